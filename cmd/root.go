@@ -41,10 +41,12 @@ environment.`,
 	root.SetOut(os.Stdout)
 	cfgFile := root.PersistentFlags().String("config_file", defaultCfgFile(), "Path to KNE config file")
 	root.PersistentFlags().String("kubecfg", defaultKubeCfg(), "kubeconfig file")
+	root.PersistentFlags().String("kubecontext", "", "kubeconfig context to use instead of the current context")
 	root.PersistentFlags().Bool("report_usage", false, "Whether to reporting anonymous usage metrics")
 	root.PersistentFlags().String("report_usage_project_id", "", "Project to report anonymous usage metrics to")
 	root.PersistentFlags().String("report_usage_topic_id", "", "Topic to report anonymous usage metrics to")
 	root.PersistentFlags().Bool("progress", false, "Display progress of container bringup")
+	root.PersistentFlags().String("resource_prefix", "", "Prefix of the topology namespace, such as to share a cluster between users")
 	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		if *cfgFile == "" {
 			return nil
@@ -92,8 +94,10 @@ func newCreateCmd() *cobra.Command {
 		RunE:      createFn,
 		ValidArgs: []string{"topology"},
 	}
-	cmd.Flags().Bool("dryrun", false, "Generate topology but do not push to k8s")
+	cmd.Flags().Bool("dryrun", false, "Generate topology and print the k8s resources instead of pushing them")
 	cmd.Flags().Duration("timeout", 0, "Timeout for pod status enquiry")
+	cmd.Flags().Bool("warn_only", true, "Only log link anomalies such as nodes without links instead of failing")
+	cmd.Flags().Bool("upsert", false, "Update or skip the resources which already exist instead of failing")
 	return cmd
 }
 
@@ -106,6 +110,7 @@ func newDeleteCmd() *cobra.Command {
 		ValidArgs: []string{"topology"},
 	}
 	cmd.Flags().Bool("skip_wait", false, "Skips waiting for resource deletion")
+	cmd.Flags().Duration("wait_timeout", 0, "If set, poll for up to this long for resource deletion and report the remaining resources on timeout")
 	return cmd
 }
 
@@ -147,8 +152,12 @@ func createFn(cmd *cobra.Command, args []string) error {
 	}
 	opts := []topo.Option{
 		topo.WithKubecfg(viper.GetString("kubecfg")),
+		topo.WithKubeContext(viper.GetString("kubecontext")),
 		topo.WithBasePath(bp),
 		topo.WithProgress(viper.GetBool("progress")),
+		topo.WithStrictLinks(!viper.GetBool("warn_only")),
+		topo.WithUpsert(viper.GetBool("upsert")),
+		topo.WithResourcePrefix(viper.GetString("resource_prefix")),
 		topo.WithUsageReporting(
 			viper.GetBool("report_usage"),
 			viper.GetString("report_usage_project_id"),
@@ -160,7 +169,7 @@ func createFn(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
 	if viper.GetBool("dryrun") {
-		return nil
+		return tm.DryRun(cmd.Context(), cmd.OutOrStdout())
 	}
 	return tm.Create(cmd.Context(), viper.GetDuration("timeout"))
 }
@@ -170,7 +179,7 @@ func deleteFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tm, err := topo.New(topopb, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithSkipDeleteWait(viper.GetBool("skip_wait")))
+	tm, err := topo.New(topopb, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithSkipDeleteWait(viper.GetBool("skip_wait")), topo.WithWaitForDelete(viper.GetDuration("wait_timeout")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
@@ -182,7 +191,7 @@ func showFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tm, err := topo.New(topopb, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tm, err := topo.New(topopb, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
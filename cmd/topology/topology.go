@@ -17,9 +17,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
 	"github.com/openconfig/gnmi/errlist"
 	cpb "github.com/openconfig/kne/proto/controller"
 	tpb "github.com/openconfig/kne/proto/topo"
@@ -29,7 +31,7 @@ import (
 	"github.com/spf13/viper"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/encoding/prototext"
+	corev1 "k8s.io/api/core/v1"
 	log "k8s.io/klog/v2"
 )
 
@@ -59,13 +61,21 @@ func New() *cobra.Command {
 		Short: "reset configuration of device to vendor default (if device not provide reset all nodes)",
 		RunE:  resetCfgFn,
 	}
+	execCmd := &cobra.Command{
+		Use:   "exec <topology> <device> <command> [args...]",
+		Short: "run a command on device",
+		RunE:  execFn,
+	}
 	resetCfgCmd.Flags().Bool("skip", false, "skip nodes if they are not resetable")
 	resetCfgCmd.Flags().Bool("push", false, "additionally push orginal topology configuration")
+	watchCmd.Flags().String("watch_format", topo.WatchFormatText, "format to print events in (text or json)")
+	serviceCmd.Flags().String("format", "proto", "format to print the topology in (proto, yaml or json)")
 	topoCmd := &cobra.Command{
 		Use:   "topology",
 		Short: "Topology commands.",
 	}
 	topoCmd.AddCommand(certCmd)
+	topoCmd.AddCommand(execCmd)
 	topoCmd.AddCommand(pushCmd)
 	topoCmd.AddCommand(serviceCmd)
 	topoCmd.AddCommand(watchCmd)
@@ -93,7 +103,7 @@ func resetCfgFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	tm, err := topo.New(topopb, tOpts...)
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
@@ -171,7 +181,7 @@ func pushFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	tm, err := topo.New(topopb, tOpts...)
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
@@ -197,7 +207,7 @@ func watchFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")), topo.WithWatchFormat(viper.GetString("watch_format")))
 	tm, err := topo.New(topopb, tOpts...)
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
@@ -213,7 +223,7 @@ func certFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	tm, err := topo.New(topopb, tOpts...)
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
@@ -227,6 +237,32 @@ var newTopologyManager = func(topopb *tpb.Topology, opts ...topo.Option) (Topolo
 
 type TopologyManager interface {
 	Show(ctx context.Context) (*cpb.ShowTopologyResponse, error)
+	ExecCommand(ctx context.Context, nodeName string, cmd []string) (stdout, stderr string, err error)
+	UpdateNode(ctx context.Context, nodeName string, patch *tpb.Node) error
+	NodeLogs(ctx context.Context, nodeName string, opts corev1.PodLogOptions) (io.ReadCloser, error)
+	Topologies(ctx context.Context) ([]topologyv1.Topology, error)
+	ResetNode(ctx context.Context, nodeName string) error
+	WatchWithHandler(ctx context.Context, handler topo.WatchHandler) error
+	Scale(ctx context.Context, nodeName string, replicas int32) error
+}
+
+func execFn(cmd *cobra.Command, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("%s: missing args", cmd.Use)
+	}
+	topopb, err := topo.Load(args[0])
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd.Use, err)
+	}
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
+	tm, err := newTopologyManager(topopb, tOpts...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd.Use, err)
+	}
+	stdout, stderr, err := tm.ExecCommand(cmd.Context(), args[1], args[2:])
+	fmt.Fprint(cmd.OutOrStdout(), stdout)
+	fmt.Fprint(cmd.ErrOrStderr(), stderr)
+	return err
 }
 
 func serviceFn(cmd *cobra.Command, args []string) error {
@@ -237,7 +273,7 @@ func serviceFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
 	}
-	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")))
+	tOpts := append(opts, topo.WithKubecfg(viper.GetString("kubecfg")), topo.WithKubeContext(viper.GetString("kubecontext")), topo.WithResourcePrefix(viper.GetString("resource_prefix")))
 	tm, err := newTopologyManager(topopb, tOpts...)
 	if err != nil {
 		return fmt.Errorf("%s: %w", cmd.Use, err)
@@ -246,7 +282,10 @@ func serviceFn(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-
-	fmt.Fprintln(cmd.OutOrStdout(), prototext.Format(ts.Topology))
+	b, err := topo.Serialize(ts.Topology, topo.SerializeOptions{Format: viper.GetString("format")})
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd.Use, err)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), string(b))
 	return nil
 }
@@ -8,19 +8,23 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h-fam/errdiff"
 	tfake "github.com/networkop/meshnet-cni/api/clientset/v1beta1/fake"
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
 	cpb "github.com/openconfig/kne/proto/controller"
 	tpb "github.com/openconfig/kne/proto/topo"
 	"github.com/openconfig/kne/topo"
 	"github.com/openconfig/kne/topo/node"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
 	"google.golang.org/protobuf/testing/protocmp"
+	corev1 "k8s.io/api/core/v1"
 	kfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 )
@@ -316,6 +320,38 @@ links: {
 type fakeTopologyManager struct {
 	topo    *tpb.Topology
 	showErr error
+	execErr error
+}
+
+func (f *fakeTopologyManager) ExecCommand(_ context.Context, nodeName string, cmd []string) (string, string, error) {
+	if f.execErr != nil {
+		return "", "error output", f.execErr
+	}
+	return fmt.Sprintf("%s: %s", nodeName, strings.Join(cmd, " ")), "", nil
+}
+
+func (f *fakeTopologyManager) UpdateNode(_ context.Context, _ string, _ *tpb.Node) error {
+	return nil
+}
+
+func (f *fakeTopologyManager) NodeLogs(_ context.Context, _ string, _ corev1.PodLogOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (f *fakeTopologyManager) Topologies(_ context.Context) ([]topologyv1.Topology, error) {
+	return nil, nil
+}
+
+func (f *fakeTopologyManager) ResetNode(_ context.Context, _ string) error {
+	return nil
+}
+
+func (f *fakeTopologyManager) WatchWithHandler(_ context.Context, _ topo.WatchHandler) error {
+	return nil
+}
+
+func (f *fakeTopologyManager) Scale(_ context.Context, _ string, _ int32) error {
+	return nil
 }
 
 func (f *fakeTopologyManager) Show(_ context.Context) (*cpb.ShowTopologyResponse, error) {
@@ -337,6 +373,7 @@ func TestService(t *testing.T) {
 		desc        string
 		args        []string
 		topoManager *fakeTopologyManager
+		json        bool
 		want        *tpb.Topology
 		wantErr     string
 	}{
@@ -354,11 +391,26 @@ func TestService(t *testing.T) {
 			topoManager: &fakeTopologyManager{topo: validProto},
 			want:        validProto,
 			args:        []string{"service", "testdata/valid_topo.pb.txt"},
+		}, {
+			desc:        "unsupported format",
+			topoManager: &fakeTopologyManager{topo: validProto},
+			wantErr:     "unsupported topology format",
+			args:        []string{"service", "testdata/valid_topo.pb.txt", "--format=xml"},
+		}, {
+			desc:        "json format",
+			topoManager: &fakeTopologyManager{topo: validProto},
+			json:        true,
+			want:        validProto,
+			args:        []string{"service", "testdata/valid_topo.pb.txt", "--format=json"},
 		},
 	}
 
 	sCmd := New()
 	sCmd.PersistentFlags().String("kubecfg", "", "")
+	sCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		viper.BindPFlags(cmd.Flags())
+		return nil
+	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			origNewTopologyManager := newTopologyManager
@@ -380,7 +432,11 @@ func TestService(t *testing.T) {
 				return
 			}
 			got := &tpb.Topology{}
-			if err := prototext.Unmarshal(buf.Bytes(), got); err != nil {
+			unmarshal := prototext.Unmarshal
+			if tt.json {
+				unmarshal = protojson.Unmarshal
+			}
+			if err := unmarshal(buf.Bytes(), got); err != nil {
 				t.Fatalf("Invalid buffer output: %v", err)
 			}
 			if s := cmp.Diff(got, tt.want, protocmp.Transform()); s != "" {
@@ -390,6 +446,65 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestExec(t *testing.T) {
+	tests := []struct {
+		desc        string
+		args        []string
+		topoManager *fakeTopologyManager
+		wantStdout  string
+		wantStderr  string
+		wantErr     string
+	}{{
+		desc:    "no args",
+		wantErr: "missing args",
+		args:    []string{"exec", "testdata/valid_topo.pb.txt", "r1"},
+	}, {
+		desc:        "exec failure",
+		topoManager: &fakeTopologyManager{execErr: fmt.Errorf("some error")},
+		args:        []string{"exec", "testdata/valid_topo.pb.txt", "r1", "show", "version"},
+		wantStderr:  "error output",
+		wantErr:     "some error",
+	}, {
+		desc:        "valid case",
+		topoManager: &fakeTopologyManager{},
+		args:        []string{"exec", "testdata/valid_topo.pb.txt", "r1", "show", "version"},
+		wantStdout:  "r1: show version",
+	}}
+
+	eCmd := New()
+	eCmd.PersistentFlags().String("kubecfg", "", "")
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			origNewTopologyManager := newTopologyManager
+			newTopologyManager = func(_ *tpb.Topology, _ ...topo.Option) (TopologyManager, error) {
+				return tt.topoManager, nil
+			}
+			defer func() {
+				newTopologyManager = origNewTopologyManager
+			}()
+			stdout := bytes.NewBuffer([]byte{})
+			stderr := bytes.NewBuffer([]byte{})
+			eCmd.SetOut(stdout)
+			eCmd.SetErr(stderr)
+			eCmd.SetArgs(tt.args)
+
+			err := eCmd.ExecuteContext(context.Background())
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("execCmd failed: %s", s)
+			}
+			if !strings.HasPrefix(stderr.String(), tt.wantStderr) {
+				t.Errorf("execCmd got stderr %q, want prefix %q", stderr.String(), tt.wantStderr)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("execCmd got stdout %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
+
 func TestPush(t *testing.T) {
 	confFile, err := os.CreateTemp("", "push")
 	if err != nil {
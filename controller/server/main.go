@@ -53,6 +53,8 @@ var (
 	reportUsage          = flag.Bool("report_usage", false, "Whether to reporting anonymous usage metrics")
 	reportUsageProjectID = flag.String("report_usage_project_id", "", "Project to report anonymous usage metrics to")
 	reportUsageTopicID   = flag.String("report_usage_topic_id", "", "Topic to report anonymous usage metrics to")
+	resourcePrefix       = flag.String("resource_prefix", "", "Prefix of the topology namespaces, such as to share a cluster between users")
+	deleteTimeout        = flag.Duration("delete_timeout", 5*time.Minute, "Timeout of waiting for the deletion of a topology requesting it")
 )
 
 func init() {
@@ -379,6 +381,7 @@ func (s *server) CreateTopology(ctx context.Context, req *cpb.CreateTopologyRequ
 	opts := []topo.Option{
 		topo.WithKubecfg(kcfg),
 		topo.WithUsageReporting(*reportUsage, *reportUsageProjectID, *reportUsageTopicID),
+		topo.WithResourcePrefix(*resourcePrefix),
 	}
 	tm, err := topo.New(topoPb, opts...)
 	if err != nil {
@@ -411,7 +414,11 @@ func (s *server) DeleteTopology(ctx context.Context, req *cpb.DeleteTopologyRequ
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "default kubecfg %q does not exist: %v", defaultKubeCfg, err)
 	}
-	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg))
+	opts := []topo.Option{topo.WithKubecfg(kcfg), topo.WithResourcePrefix(*resourcePrefix)}
+	if req.GetWaitForDelete() {
+		opts = append(opts, topo.WithWaitForDelete(*deleteTimeout))
+	}
+	tm, err := topo.New(topoPb, opts...)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create topology manager: %v", err)
 	}
@@ -437,7 +444,7 @@ func (s *server) ShowTopology(ctx context.Context, req *cpb.ShowTopologyRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "default kubecfg %q does not exist: %v", defaultKubeCfg, err)
 	}
-	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg))
+	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg), topo.WithResourcePrefix(*resourcePrefix))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create topology manager: %v", err)
 	}
@@ -464,7 +471,7 @@ func (s *server) PushConfig(ctx context.Context, req *cpb.PushConfigRequest) (*c
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "default kubecfg %q does not exist: %v", defaultKubeCfg, err)
 	}
-	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg))
+	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg), topo.WithResourcePrefix(*resourcePrefix))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create topology manager for %s: %v", topoPb.Name, err)
 	}
@@ -491,7 +498,7 @@ func (s *server) ResetConfig(ctx context.Context, req *cpb.ResetConfigRequest) (
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "default kubecfg %q does not exist: %v", defaultKubeCfg, err)
 	}
-	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg))
+	tm, err := topo.New(topoPb, topo.WithKubecfg(kcfg), topo.WithResourcePrefix(*resourcePrefix))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create topology manager for %s: %v", topoPb.Name, err)
 	}
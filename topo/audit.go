@@ -0,0 +1,103 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	log "k8s.io/klog/v2"
+)
+
+// AuditEntry is a line written to the audit log for each topology operation.
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Operation string    `json:"operation"`
+	Topology  string    `json:"topology"`
+	User      string    `json:"user"`
+	// Outcome is "success" or "failure".
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// auditLog writes audit entries as JSON lines.
+type auditLog struct {
+	mu   sync.Mutex
+	w    io.Writer
+	user string
+}
+
+// WithAuditLog writes an AuditEntry as a JSON line to w for each load,
+// create, delete and config push of the topology. The user is the user of
+// the kubeconfig context in use.
+func WithAuditLog(w io.Writer) Option {
+	return func(m *Manager) {
+		m.audit = &auditLog{w: w}
+	}
+}
+
+// record writes the entry for operation with the outcome of err to the
+// audit log of m, if any. Failures to write are logged.
+func (m *Manager) record(operation string, err error) {
+	if m.audit == nil {
+		return
+	}
+	e := AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Operation: operation,
+		Topology:  m.topo.GetName(),
+		User:      m.audit.user,
+		Outcome:   "success",
+	}
+	if err != nil {
+		e.Outcome = "failure"
+		e.Error = err.Error()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		log.Warningf("Failed to encode audit entry: %v", err)
+		return
+	}
+	m.audit.mu.Lock()
+	defer m.audit.mu.Unlock()
+	if _, err := m.audit.w.Write(append(b, '\n')); err != nil {
+		log.Warningf("Failed to write audit entry: %v", err)
+	}
+}
+
+// kubeUser returns the name of the user of the kubeconfig context in use,
+// falling back to the username of the cluster config.
+func (m *Manager) kubeUser() string {
+	raw, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: m.kubecfg},
+		&clientcmd.ConfigOverrides{CurrentContext: m.kubeContext},
+	).RawConfig()
+	if err == nil {
+		name := raw.CurrentContext
+		if m.kubeContext != "" {
+			name = m.kubeContext
+		}
+		if c, ok := raw.Contexts[name]; ok && c.AuthInfo != "" {
+			return c.AuthInfo
+		}
+	}
+	if m.rCfg != nil && m.rCfg.Username != "" {
+		return m.rCfg.Username
+	}
+	return "unknown"
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	tfake "github.com/networkop/meshnet-cni/api/clientset/v1beta1/fake"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	kfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	var buf bytes.Buffer
+	m, err := New(&tpb.Topology{Name: "test"},
+		WithKubecfg("testdata/multi_context_kubeconfig.yaml"),
+		WithKubeContext("kind-b"),
+		WithKubeClient(kfake.NewSimpleClientset()),
+		WithTopoClient(tf),
+		WithAuditLog(&buf),
+	)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	m.nodes = map[string]node.Node{
+		"r1": &configurable{Impl: &node.Impl{Namespace: "test", Proto: &tpb.Node{Name: "r1"}}},
+	}
+	if err := m.ConfigPush(ctx, "r1", strings.NewReader("config")); err != nil {
+		t.Fatalf("ConfigPush() failed: %v", err)
+	}
+	if err := m.ConfigPush(ctx, "r1", strings.NewReader("error")); err == nil {
+		t.Fatalf("ConfigPush() succeeded, want error")
+	}
+	if err := m.Delete(ctx); err == nil {
+		t.Fatalf("Delete() succeeded, want error")
+	}
+
+	var got []AuditEntry
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var e AuditEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("failed to decode audit entry: %v", err)
+		}
+		if e.Timestamp.IsZero() {
+			t.Errorf("audit entry %+v has no timestamp", e)
+		}
+		if e.Outcome == "failure" && e.Error == "" {
+			t.Errorf("audit entry %+v has no error", e)
+		}
+		got = append(got, e)
+	}
+	want := []AuditEntry{
+		{Operation: "load", Topology: "test", User: "kind-b", Outcome: "success"},
+		{Operation: "config_push", Topology: "test", User: "kind-b", Outcome: "success"},
+		{Operation: "config_push", Topology: "test", User: "kind-b", Outcome: "failure"},
+		{Operation: "delete", Topology: "test", User: "kind-b", Outcome: "failure"},
+	}
+	if s := cmp.Diff(want, got, cmpopts.IgnoreFields(AuditEntry{}, "Timestamp", "Error")); s != "" {
+		t.Errorf("audit log unexpected diff (-want +got):\n%s", s)
+	}
+}
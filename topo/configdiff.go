@@ -0,0 +1,169 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// diffContext is the number of unchanged lines around each hunk of a
+// unified diff.
+const diffContext = 3
+
+// ConfigDiff returns a unified diff from the running config of the provided
+// node to the proposed config read from r. An empty string is returned if the
+// configs are the same. If the node does not fulfill ConfigPuller then
+// status.Unimplemented error will be returned.
+func (m *Manager) ConfigDiff(ctx context.Context, nodeName string, r io.Reader) (string, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return "", fmt.Errorf("node %q not found", nodeName)
+	}
+	if _, ok := n.(node.ConfigPuller); !ok {
+		return "", status.Errorf(codes.Unimplemented, "node %q does not implement ConfigPuller interface", nodeName)
+	}
+	var running bytes.Buffer
+	if err := m.ConfigPull(ctx, nodeName, &running); err != nil {
+		return "", fmt.Errorf("failed to pull config of node %q: %w", nodeName, err)
+	}
+	proposed, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read proposed config: %w", err)
+	}
+	return unifiedDiff(nodeName+" (running)", nodeName+" (proposed)", running.String(), string(proposed)), nil
+}
+
+// diffLine is a line of a diff prefixed with ' ', '-' or '+'.
+type diffLine struct {
+	op   byte
+	text string
+	// a and z are the 0-based line numbers of the line in the old and new
+	// text.
+	a, z int
+}
+
+// splitLines splits s into lines without their trailing newlines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// unifiedDiff returns the unified diff of the lines from a to z labeled with
+// aName and zName, or an empty string if they are the same.
+func unifiedDiff(aName, zName, a, z string) string {
+	al, zl := splitLines(a), splitLines(z)
+	// lcs[i][j] is the length of the longest common subsequence of al[i:]
+	// and zl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(zl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(zl) - 1; j >= 0; j-- {
+			switch {
+			case al[i] == zl[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(al) || j < len(zl) {
+		switch {
+		case i < len(al) && j < len(zl) && al[i] == zl[j]:
+			lines = append(lines, diffLine{op: ' ', text: al[i], a: i, z: j})
+			i++
+			j++
+		case j == len(zl) || (i < len(al) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{op: '-', text: al[i], a: i, z: j})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: zl[j], a: i, z: j})
+			j++
+		}
+	}
+	var sb strings.Builder
+	for k := 0; k < len(lines); {
+		if lines[k].op == ' ' {
+			k++
+			continue
+		}
+		// Extend the hunk until more than twice the context of unchanged
+		// lines separates it from the next change.
+		start := k - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(lines) {
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			if next == len(lines) || next-end > 2*diffContext {
+				break
+			}
+			end = next + 1
+		}
+		stop := end + diffContext
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, zName)
+		}
+		var aLen, zLen int
+		for _, l := range lines[start:stop] {
+			if l.op != '+' {
+				aLen++
+			}
+			if l.op != '-' {
+				zLen++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(lines[start].a, aLen), hunkRange(lines[start].z, zLen))
+		for _, l := range lines[start:stop] {
+			fmt.Fprintf(&sb, "%c%s\n", l.op, l.text)
+		}
+		k = stop
+	}
+	return sb.String()
+}
+
+// hunkRange formats the 0-based start line and length of a hunk as a unified
+// diff range.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfigDiff(t *testing.T) {
+	running := `hostname r1
+interface eth1
+ mtu 1500
+!
+interface eth2
+ mtu 1500
+!
+line a
+line b
+line c
+line d
+line e
+end
+`
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"r1":       &configStore{config: []byte(running)},
+			"empty":    &configStore{},
+			"no_store": &notExecer{},
+		},
+	}
+	tests := []struct {
+		desc     string
+		node     string
+		proposed string
+		want     string
+		wantErr  string
+		wantCode codes.Code
+	}{{
+		desc: "changed",
+		node: "r1",
+		proposed: `hostname r1
+interface eth1
+ mtu 9000
+!
+interface eth2
+ mtu 1500
+!
+line a
+line b
+line c
+line d
+line e
+management api gnmi
+end
+`,
+		want: `--- r1 (running)
++++ r1 (proposed)
+@@ -1,6 +1,6 @@
+ hostname r1
+ interface eth1
+- mtu 1500
++ mtu 9000
+ !
+ interface eth2
+  mtu 1500
+@@ -10,4 +10,5 @@
+ line c
+ line d
+ line e
++management api gnmi
+ end
+`,
+	}, {
+		desc:     "unchanged",
+		node:     "r1",
+		proposed: running,
+	}, {
+		desc:     "empty running config",
+		node:     "empty",
+		proposed: "a\n",
+		want: `--- empty (running)
++++ empty (proposed)
+@@ -0,0 +1 @@
++a
+`,
+	}, {
+		desc:     "not puller",
+		node:     "no_store",
+		wantErr:  "does not implement ConfigPuller",
+		wantCode: codes.Unimplemented,
+	}, {
+		desc:    "missing node",
+		node:    "dne",
+		wantErr: `node "dne" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := m.ConfigDiff(context.Background(), tt.node, strings.NewReader(tt.proposed))
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ConfigDiff() unexpected err: %s", s)
+			}
+			if tt.wantCode != codes.OK && status.Code(err) != tt.wantCode {
+				t.Errorf("ConfigDiff() got code %v, want %v", status.Code(err), tt.wantCode)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("ConfigDiff() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	got := unifiedDiff("a", "b", "a\n", "")
+	want := "--- a\n+++ b\n@@ -1 +0,0 @@\n-a\n"
+	if got != want {
+		t.Errorf("unifiedDiff() got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/openconfig/gnmi/errlist"
+	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "k8s.io/klog/v2"
+)
+
+// ConnectivityResult is the outcome of probing a link from its A node to its
+// Z node.
+type ConnectivityResult struct {
+	ANode   string
+	ZNode   string
+	Success bool
+	Latency time.Duration
+}
+
+// ProbeCommand returns the command run on the A node of a link to probe the
+// Z node at target. The probe succeeds if the command exits successfully.
+type ProbeCommand func(target string, timeout time.Duration) []string
+
+// pingCommand sends a single ping to target.
+func pingCommand(target string, timeout time.Duration) []string {
+	wait := int(math.Ceil(timeout.Seconds()))
+	if wait < 1 {
+		wait = 1
+	}
+	return []string{"ping", "-c", "1", "-W", strconv.Itoa(wait), target}
+}
+
+// WithProbeCommand sets the command used by TestConnectivity to probe links
+// instead of ping.
+func WithProbeCommand(f ProbeCommand) Option {
+	return func(m *Manager) {
+		m.probeCommand = f
+	}
+}
+
+// TestConnectivity probes each link in the topology by running the probe
+// command on the pod of the A node against the pod IP of the Z node, giving
+// each probe up to timeout to complete. A result is returned for every link
+// that could be probed. Links whose nodes cannot be probed, such as nodes
+// which do not fulfill Execer, are returned as errors together with the
+// results.
+func (m *Manager) TestConnectivity(ctx context.Context, timeout time.Duration) ([]ConnectivityResult, error) {
+	probe := m.probeCommand
+	if probe == nil {
+		probe = pingCommand
+	}
+	var results []ConnectivityResult
+	var errs errlist.List
+	for _, l := range m.topo.GetLinks() {
+		r, err := m.probeLink(ctx, l.GetANode(), l.GetZNode(), probe, timeout)
+		if err != nil {
+			errs.Add(fmt.Errorf("link %s:%s %s:%s: %w", l.GetANode(), l.GetAInt(), l.GetZNode(), l.GetZInt(), err))
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, errs.Err()
+}
+
+func (m *Manager) probeLink(ctx context.Context, aName, zName string, probe ProbeCommand, timeout time.Duration) (ConnectivityResult, error) {
+	r := ConnectivityResult{ANode: aName, ZNode: zName}
+	a, ok := m.nodes[aName]
+	if !ok {
+		return r, status.Errorf(codes.NotFound, "node %q not found", aName)
+	}
+	z, ok := m.nodes[zName]
+	if !ok {
+		return r, status.Errorf(codes.NotFound, "node %q not found", zName)
+	}
+	e, ok := a.(node.Execer)
+	if !ok {
+		return r, status.Errorf(codes.Unimplemented, "node %q does not implement Execer interface", aName)
+	}
+	pods, err := z.Pods(ctx)
+	if err != nil {
+		return r, fmt.Errorf("failed to get pods for node %q: %w", zName, err)
+	}
+	if len(pods) == 0 || pods[0].Status.PodIP == "" {
+		return r, fmt.Errorf("node %q has no pod IP", zName)
+	}
+	pCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		pCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err = e.Exec(pCtx, probe(pods[0].Status.PodIP, timeout), nil, io.Discard, io.Discard)
+	r.Latency = time.Since(start)
+	if err != nil {
+		log.Warningf("Probe from %q to %q failed: %v", aName, zName, err)
+		return r, nil
+	}
+	r.Success = true
+	return r, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	corev1 "k8s.io/api/core/v1"
+)
+
+type probeNode struct {
+	*node.Impl
+	ip   string
+	down map[string]bool
+	cmds *[][]string
+}
+
+func (p *probeNode) Pods(_ context.Context) ([]*corev1.Pod, error) {
+	return []*corev1.Pod{{Status: corev1.PodStatus{PodIP: p.ip}}}, nil
+}
+
+func (p *probeNode) Exec(_ context.Context, cmd []string, _ io.Reader, _, _ io.Writer) error {
+	*p.cmds = append(*p.cmds, cmd)
+	if p.down[cmd[len(cmd)-1]] {
+		return fmt.Errorf("command terminated with exit code 1")
+	}
+	return nil
+}
+
+func TestTestConnectivity(t *testing.T) {
+	var cmds [][]string
+	newNode := func(name, ip string, down ...string) *probeNode {
+		d := map[string]bool{}
+		for _, ip := range down {
+			d[ip] = true
+		}
+		return &probeNode{
+			Impl: &node.Impl{Proto: &tpb.Node{Name: name}},
+			ip:   ip,
+			down: d,
+			cmds: &cmds,
+		}
+	}
+	tests := []struct {
+		desc     string
+		links    []*tpb.Link
+		opts     []Option
+		want     []ConnectivityResult
+		wantCmds [][]string
+		wantErr  string
+	}{{
+		desc: "all links",
+		links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth2"},
+		},
+		want: []ConnectivityResult{
+			{ANode: "r1", ZNode: "r2", Success: true},
+			{ANode: "r2", ZNode: "r3", Success: false},
+			{ANode: "r1", ZNode: "r3", Success: true},
+		},
+		wantCmds: [][]string{
+			{"ping", "-c", "1", "-W", "2", "10.0.0.2"},
+			{"ping", "-c", "1", "-W", "2", "10.0.0.3"},
+			{"ping", "-c", "1", "-W", "2", "10.0.0.3"},
+		},
+	}, {
+		desc: "custom probe",
+		links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+		},
+		opts: []Option{WithProbeCommand(func(target string, _ time.Duration) []string {
+			return []string{"nc", "-z", target}
+		})},
+		want: []ConnectivityResult{
+			{ANode: "r1", ZNode: "r2", Success: true},
+		},
+		wantCmds: [][]string{
+			{"nc", "-z", "10.0.0.2"},
+		},
+	}, {
+		desc: "not execer",
+		links: []*tpb.Link{
+			{ANode: "r4", AInt: "eth1", ZNode: "r1", ZInt: "eth3"},
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+		},
+		want: []ConnectivityResult{
+			{ANode: "r1", ZNode: "r2", Success: true},
+		},
+		wantCmds: [][]string{
+			{"ping", "-c", "1", "-W", "2", "10.0.0.2"},
+		},
+		wantErr: "does not implement Execer",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cmds = nil
+			m := &Manager{
+				topo: &tpb.Topology{Name: "test", Links: tt.links},
+				nodes: map[string]node.Node{
+					"r1": newNode("r1", "10.0.0.1"),
+					"r2": newNode("r2", "10.0.0.2", "10.0.0.3"),
+					"r3": newNode("r3", "10.0.0.3"),
+					"r4": &notExecer{},
+				},
+			}
+			for _, o := range tt.opts {
+				o(m)
+			}
+			got, err := m.TestConnectivity(context.Background(), 1500*time.Millisecond)
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("TestConnectivity() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(ConnectivityResult{}, "Latency")); s != "" {
+				t.Errorf("TestConnectivity() unexpected results (-want +got):\n%s", s)
+			}
+			if s := cmp.Diff(tt.wantCmds, cmds); s != "" {
+				t.Errorf("TestConnectivity() unexpected probe commands (-want +got):\n%s", s)
+			}
+		})
+	}
+}
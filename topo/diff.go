@@ -0,0 +1,118 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TopologyDiff is the difference between the topology proto and the
+// resources running in the cluster.
+type TopologyDiff struct {
+	// Modified are the nodes whose pods differ from the node proto.
+	Modified []NodeDiff
+	// Missing are the names of the nodes which have no pods.
+	Missing []string
+}
+
+// NodeDiff is the difference between a node proto and one of its pods.
+type NodeDiff struct {
+	Name   string
+	Pod    string
+	Fields []FieldDiff
+}
+
+// FieldDiff is a single field which differs between a node proto and its pod.
+type FieldDiff struct {
+	// Field is the name of the field, such as "image" or "env[FOO]".
+	Field string
+	Want  string
+	Got   string
+}
+
+// Diff compares the container image and environment of the pods running in
+// the cluster against the node protos of the topology. Nodes and their
+// differences are sorted by name.
+func (m *Manager) Diff(ctx context.Context) (*TopologyDiff, error) {
+	d := &TopologyDiff{}
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := m.nodes[name]
+		pods, err := nodePods(ctx, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pods for node %q: %w", name, err)
+		}
+		if len(pods) == 0 {
+			d.Missing = append(d.Missing, name)
+			continue
+		}
+		cfg := n.GetProto().GetConfig()
+		for _, p := range pods {
+			c := nodeContainer(p, name)
+			if c == nil {
+				d.Modified = append(d.Modified, NodeDiff{
+					Name:   name,
+					Pod:    p.Name,
+					Fields: []FieldDiff{{Field: "container", Want: name}},
+				})
+				continue
+			}
+			var fields []FieldDiff
+			if want := cfg.GetImage(); want != "" && want != c.Image {
+				fields = append(fields, FieldDiff{Field: "image", Want: want, Got: c.Image})
+			}
+			env := map[string]string{}
+			for _, e := range c.Env {
+				env[e.Name] = e.Value
+			}
+			var keys []string
+			for k := range cfg.GetEnv() {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if want := cfg.GetEnv()[k]; env[k] != want {
+					fields = append(fields, FieldDiff{Field: fmt.Sprintf("env[%s]", k), Want: want, Got: env[k]})
+				}
+			}
+			if len(fields) != 0 {
+				d.Modified = append(d.Modified, NodeDiff{Name: name, Pod: p.Name, Fields: fields})
+			}
+		}
+	}
+	return d, nil
+}
+
+// nodeContainer returns the container of p named after the node, or the
+// first container of p if none is.
+func nodeContainer(p *corev1.Pod, name string) *corev1.Container {
+	for i := range p.Spec.Containers {
+		if p.Spec.Containers[i].Name == name {
+			return &p.Spec.Containers[i]
+		}
+	}
+	if len(p.Spec.Containers) != 0 {
+		return &p.Spec.Containers[0]
+	}
+	return nil
+}
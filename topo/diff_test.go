@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tfake "github.com/networkop/meshnet-cni/api/clientset/v1beta1/fake"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1020), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1020),
+			Config: &tpb.Config{Image: "foo:1", Env: map[string]string{"A": "1", "B": "2"}},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1020),
+			Config: &tpb.Config{Image: "foo:1"},
+		}, {
+			Name:   "r3",
+			Vendor: tpb.Vendor(1020),
+			Config: &tpb.Config{Image: "foo:1"},
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	got, err := m.Diff(ctx)
+	if err != nil {
+		t.Fatalf("Diff() unexpected err: %v", err)
+	}
+	if s := cmp.Diff(&TopologyDiff{}, got); s != "" {
+		t.Errorf("Diff() of unchanged topology unexpected diff (-want +got):\n%s", s)
+	}
+
+	p, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	p.Spec.Containers[0].Image = "foo:2"
+	p.Spec.Containers[0].Env = []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "3"}}
+	if _, err := kf.CoreV1().Pods("test").Update(ctx, p, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+	if err := kf.CoreV1().Pods("test").Delete(ctx, "r3", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete pod: %v", err)
+	}
+	got, err = m.Diff(ctx)
+	if err != nil {
+		t.Fatalf("Diff() unexpected err: %v", err)
+	}
+	want := &TopologyDiff{
+		Modified: []NodeDiff{{
+			Name: "r1",
+			Pod:  "r1",
+			Fields: []FieldDiff{
+				{Field: "image", Want: "foo:1", Got: "foo:2"},
+				{Field: "env[B]", Want: "2", Got: "3"},
+			},
+		}},
+		Missing: []string{"r3"},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("Diff() unexpected diff (-want +got):\n%s", s)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	tpb "github.com/openconfig/kne/proto/topo"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// encryptSaltSize is the size of the random HKDF salt stored at the
+	// start of an encrypted topology file.
+	encryptSaltSize = 16
+	// encryptKeySize selects AES-256.
+	encryptKeySize = 32
+)
+
+// encryptInfo binds the derived keys to their use.
+var encryptInfo = []byte("kne topology")
+
+// topologyAEAD returns the AES-GCM cipher using the 32-byte key derived from
+// key and salt with HKDF-SHA256.
+func topologyAEAD(key, salt []byte) (cipher.AEAD, error) {
+	if len(key) == 0 {
+		return nil, errors.New("encryption key must not be empty")
+	}
+	k := make([]byte, encryptKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, encryptInfo), k); err != nil {
+		return nil, err
+	}
+	b, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(b)
+}
+
+// SaveEncrypted writes t in the proto text format to fName encrypted with
+// AES-GCM, using a key derived from key. The file holds the HKDF salt, the
+// nonce and the sealed topology, in that order, and can be read back with
+// LoadEncrypted using the same key.
+func SaveEncrypted(t *tpb.Topology, fName string, key []byte) error {
+	b, err := Serialize(t, SerializeOptions{Format: "proto"})
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, encryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	aead, err := topologyAEAD(key, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	out := append(salt, nonce...)
+	out = aead.Seal(out, nonce, b, nil)
+	return os.WriteFile(fName, out, 0600)
+}
+
+// LoadEncrypted loads a Topology written by SaveEncrypted from fName. An
+// error is returned if the file was not encrypted with key or was modified.
+// Unlike Load, the base of the topology is not resolved.
+func LoadEncrypted(fName string, key []byte) (*tpb.Topology, error) {
+	b, err := os.ReadFile(fName)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < encryptSaltSize {
+		return nil, fmt.Errorf("encrypted topology %q is truncated", fName)
+	}
+	aead, err := topologyAEAD(key, b[:encryptSaltSize])
+	if err != nil {
+		return nil, err
+	}
+	b = b[encryptSaltSize:]
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted topology %q is truncated", fName)
+	}
+	b, err = aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt topology %q: %w", fName, err)
+	}
+	return LoadFromBytes(b, "proto")
+}
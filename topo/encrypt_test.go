@@ -0,0 +1,89 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestEncrypted(t *testing.T) {
+	topo := &tpb.Topology{
+		Name:          "test",
+		SchemaVersion: CurrentSchemaVersion,
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor_ARISTA,
+			Config: &tpb.Config{Env: map[string]string{"PASSWORD": "secret"}},
+		}},
+	}
+	key := []byte("correct horse battery staple")
+	fName := filepath.Join(t.TempDir(), "topo.enc")
+	if err := SaveEncrypted(topo, fName, key); err != nil {
+		t.Fatalf("SaveEncrypted() unexpected err: %v", err)
+	}
+	b, err := os.ReadFile(fName)
+	if err != nil {
+		t.Fatalf("failed to read encrypted topology: %v", err)
+	}
+	truncated := filepath.Join(t.TempDir(), "truncated.enc")
+	if err := os.WriteFile(truncated, b[:encryptSaltSize+4], 0600); err != nil {
+		t.Fatalf("failed to write truncated topology: %v", err)
+	}
+
+	tests := []struct {
+		desc    string
+		fName   string
+		key     []byte
+		want    *tpb.Topology
+		wantErr string
+	}{{
+		desc:  "round trip",
+		fName: fName,
+		key:   key,
+		want:  topo,
+	}, {
+		desc:    "wrong key",
+		fName:   fName,
+		key:     []byte("wrong key"),
+		wantErr: "message authentication failed",
+	}, {
+		desc:    "empty key",
+		fName:   fName,
+		wantErr: "must not be empty",
+	}, {
+		desc:    "truncated",
+		fName:   truncated,
+		key:     key,
+		wantErr: "truncated",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := LoadEncrypted(tt.fName, tt.key)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("LoadEncrypted() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
+				t.Errorf("LoadEncrypted() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
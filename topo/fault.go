@@ -0,0 +1,163 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/openconfig/gnmi/errlist"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "k8s.io/klog/v2"
+)
+
+// LinkFault is the impairment applied to the traffic sent out of an
+// interface. Zero fields are not applied.
+type LinkFault struct {
+	// LossPercent is the percentage of packets dropped, from 0 to 100.
+	LossPercent float64
+	// DelayMs is the delay added to each packet in milliseconds.
+	DelayMs int
+}
+
+func (f LinkFault) validate() error {
+	if f.LossPercent < 0 || f.LossPercent > 100 {
+		return status.Errorf(codes.InvalidArgument, "loss percent %v must be between 0 and 100", f.LossPercent)
+	}
+	if f.DelayMs < 0 {
+		return status.Errorf(codes.InvalidArgument, "delay %dms must not be negative", f.DelayMs)
+	}
+	if f.LossPercent == 0 && f.DelayMs == 0 {
+		return status.Errorf(codes.InvalidArgument, "fault must set a loss or a delay")
+	}
+	return nil
+}
+
+// netemCommand returns the tc command replacing the root queueing discipline
+// of intf with a netem one applying f.
+func netemCommand(intf string, f LinkFault) []string {
+	cmd := []string{"tc", "qdisc", "replace", "dev", intf, "root", "netem"}
+	if f.DelayMs > 0 {
+		cmd = append(cmd, "delay", fmt.Sprintf("%dms", f.DelayMs))
+	}
+	if f.LossPercent > 0 {
+		cmd = append(cmd, "loss", strconv.FormatFloat(f.LossPercent, 'f', -1, 64)+"%")
+	}
+	return cmd
+}
+
+// InjectFault applies fault to the traffic sent out of interface aInt of node
+// aNode by running tc netem in the node pod. A fault already injected on the
+// interface is replaced. The node must fulfill Execer and have tc installed.
+func (m *Manager) InjectFault(ctx context.Context, aNode, aInt string, fault LinkFault) error {
+	if err := fault.validate(); err != nil {
+		return err
+	}
+	intf, err := m.podInterface(aNode, aInt)
+	if err != nil {
+		return err
+	}
+	if err := m.execNode(ctx, aNode, netemCommand(intf, fault)); err != nil {
+		return fmt.Errorf("failed to inject fault on %s:%s: %w", aNode, aInt, err)
+	}
+	log.Infof("Injected fault %+v on %s:%s", fault, aNode, aInt)
+	return nil
+}
+
+// ClearFault removes the fault injected by InjectFault on interface aInt of
+// node aNode.
+func (m *Manager) ClearFault(ctx context.Context, aNode, aInt string) error {
+	intf, err := m.podInterface(aNode, aInt)
+	if err != nil {
+		return err
+	}
+	if err := m.execNode(ctx, aNode, []string{"tc", "qdisc", "del", "dev", intf, "root"}); err != nil {
+		return fmt.Errorf("failed to clear fault on %s:%s: %w", aNode, aInt, err)
+	}
+	log.Infof("Cleared fault on %s:%s", aNode, aInt)
+	return nil
+}
+
+// podInterface returns the name in the pod of interface intf of node
+// nodeName.
+func (m *Manager) podInterface(nodeName, intf string) (string, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return "", status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	i, ok := n.GetProto().GetInterfaces()[intf]
+	if !ok {
+		return "", status.Errorf(codes.NotFound, "interface %q not found on node %q", intf, nodeName)
+	}
+	if i.GetIntName() != "" {
+		return i.GetIntName(), nil
+	}
+	return intf, nil
+}
+
+// execNode runs cmd in the pod of the provided node, adding the standard
+// error output of a failed command to the returned error.
+func (m *Manager) execNode(ctx context.Context, nodeName string, cmd []string) error {
+	_, stderr, err := m.ExecCommand(ctx, nodeName, cmd)
+	if err != nil && stderr != "" {
+		return fmt.Errorf("%w: %s", err, stderr)
+	}
+	return err
+}
+
+// SuspendLinks sets down the interfaces of both ends of every link in the
+// topology, severing all links while the pods keep running. The nodes must
+// fulfill Execer. The errors of all interfaces are returned together.
+func (m *Manager) SuspendLinks(ctx context.Context) error {
+	return m.setLinks(ctx, "down")
+}
+
+// ResumeLinks sets up the interfaces set down by SuspendLinks.
+func (m *Manager) ResumeLinks(ctx context.Context) error {
+	return m.setLinks(ctx, "up")
+}
+
+// setLinks sets the state of the interfaces of both ends of every link.
+func (m *Manager) setLinks(ctx context.Context, state string) error {
+	type endpoint struct{ node, intf string }
+	var eps []endpoint
+	for _, l := range m.topo.GetLinks() {
+		eps = append(eps, endpoint{l.GetANode(), l.GetAInt()}, endpoint{l.GetZNode(), l.GetZInt()})
+	}
+	sort.Slice(eps, func(i, j int) bool {
+		if eps[i].node != eps[j].node {
+			return eps[i].node < eps[j].node
+		}
+		return eps[i].intf < eps[j].intf
+	})
+	var errs errlist.List
+	for _, ep := range eps {
+		intf, err := m.podInterface(ep.node, ep.intf)
+		if err != nil {
+			errs.Add(err)
+			continue
+		}
+		if err := m.execNode(ctx, ep.node, []string{"ip", "link", "set", intf, state}); err != nil {
+			errs.Add(fmt.Errorf("failed to set %s:%s %s: %w", ep.node, ep.intf, state, err))
+			continue
+		}
+		log.Infof("Set %s:%s %s", ep.node, ep.intf, state)
+	}
+	return errs.Err()
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+)
+
+type tcNode struct {
+	*node.Impl
+	cmd    []string
+	stderr string
+	err    error
+}
+
+func (n *tcNode) Exec(_ context.Context, cmd []string, _ io.Reader, _, stderr io.Writer) error {
+	n.cmd = cmd
+	fmt.Fprint(stderr, n.stderr)
+	return n.err
+}
+
+func newTCNode(err error) *tcNode {
+	return &tcNode{
+		Impl: &node.Impl{Proto: &tpb.Node{
+			Name: "r1",
+			Interfaces: map[string]*tpb.Interface{
+				"eth1":      {},
+				"Ethernet2": {IntName: "eth2"},
+			},
+		}},
+		err: err,
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	tests := []struct {
+		desc    string
+		nodeErr error
+		intf    string
+		fault   LinkFault
+		wantCmd []string
+		wantErr string
+	}{{
+		desc:    "loss",
+		intf:    "eth1",
+		fault:   LinkFault{LossPercent: 12.5},
+		wantCmd: []string{"tc", "qdisc", "replace", "dev", "eth1", "root", "netem", "loss", "12.5%"},
+	}, {
+		desc:    "delay",
+		intf:    "eth1",
+		fault:   LinkFault{DelayMs: 100},
+		wantCmd: []string{"tc", "qdisc", "replace", "dev", "eth1", "root", "netem", "delay", "100ms"},
+	}, {
+		desc:    "loss and delay on renamed interface",
+		intf:    "Ethernet2",
+		fault:   LinkFault{LossPercent: 5, DelayMs: 20},
+		wantCmd: []string{"tc", "qdisc", "replace", "dev", "eth2", "root", "netem", "delay", "20ms", "loss", "5%"},
+	}, {
+		desc:    "no fault",
+		intf:    "eth1",
+		wantErr: "must set a loss or a delay",
+	}, {
+		desc:    "invalid loss",
+		intf:    "eth1",
+		fault:   LinkFault{LossPercent: 101},
+		wantErr: "between 0 and 100",
+	}, {
+		desc:    "negative delay",
+		intf:    "eth1",
+		fault:   LinkFault{DelayMs: -1},
+		wantErr: "must not be negative",
+	}, {
+		desc:    "interface not found",
+		intf:    "eth3",
+		fault:   LinkFault{DelayMs: 1},
+		wantErr: `interface "eth3" not found`,
+	}, {
+		desc:    "exec error",
+		intf:    "eth1",
+		fault:   LinkFault{DelayMs: 1},
+		nodeErr: fmt.Errorf("exit code 2"),
+		wantCmd: []string{"tc", "qdisc", "replace", "dev", "eth1", "root", "netem", "delay", "1ms"},
+		wantErr: "exit code 2: tc not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			n := newTCNode(tt.nodeErr)
+			n.stderr = "tc not found"
+			m := &Manager{nodes: map[string]node.Node{"r1": n}}
+			err := m.InjectFault(context.Background(), "r1", tt.intf, tt.fault)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("InjectFault() unexpected error: %s", s)
+			}
+			if s := cmp.Diff(tt.wantCmd, n.cmd); s != "" {
+				t.Errorf("InjectFault() unexpected command (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestClearFault(t *testing.T) {
+	tests := []struct {
+		desc    string
+		name    string
+		intf    string
+		wantCmd []string
+		wantErr string
+	}{{
+		desc:    "success",
+		name:    "r1",
+		intf:    "Ethernet2",
+		wantCmd: []string{"tc", "qdisc", "del", "dev", "eth2", "root"},
+	}, {
+		desc:    "node not found",
+		name:    "r2",
+		intf:    "eth1",
+		wantErr: `node "r2" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			n := newTCNode(nil)
+			m := &Manager{nodes: map[string]node.Node{"r1": n}}
+			err := m.ClearFault(context.Background(), tt.name, tt.intf)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ClearFault() unexpected error: %s", s)
+			}
+			if s := cmp.Diff(tt.wantCmd, n.cmd); s != "" {
+				t.Errorf("ClearFault() unexpected command (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+type linkStateNode struct {
+	*node.Impl
+	cmds *[]string
+	err  error
+}
+
+func (n *linkStateNode) Exec(_ context.Context, cmd []string, _ io.Reader, _, _ io.Writer) error {
+	*n.cmds = append(*n.cmds, n.Name()+": "+strings.Join(cmd, " "))
+	return n.err
+}
+
+func TestSuspendResumeLinks(t *testing.T) {
+	tests := []struct {
+		desc     string
+		failNode string
+		wantErr  string
+	}{{
+		desc: "success",
+	}, {
+		desc:     "exec error",
+		failNode: "r3",
+		wantErr:  "failed to set r3:eth1",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var cmds []string
+			newNode := func(name string, ints map[string]*tpb.Interface) node.Node {
+				n := &linkStateNode{Impl: &node.Impl{Proto: &tpb.Node{Name: name, Interfaces: ints}}, cmds: &cmds}
+				if name == tt.failNode {
+					n.err = fmt.Errorf("exit code 1")
+				}
+				return n
+			}
+			m := &Manager{
+				topo: &tpb.Topology{Links: []*tpb.Link{
+					{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+					{ANode: "r3", AInt: "eth1", ZNode: "r2", ZInt: "Ethernet2"},
+				}},
+				nodes: map[string]node.Node{
+					"r1": newNode("r1", map[string]*tpb.Interface{"eth1": {}}),
+					"r2": newNode("r2", map[string]*tpb.Interface{"eth1": {}, "Ethernet2": {IntName: "eth2"}}),
+					"r3": newNode("r3", map[string]*tpb.Interface{"eth1": {}}),
+				},
+			}
+			for _, state := range []string{"down", "up"} {
+				cmds = nil
+				f := m.SuspendLinks
+				if state == "up" {
+					f = m.ResumeLinks
+				}
+				err := f(context.Background())
+				if s := errdiff.Check(err, tt.wantErr); s != "" {
+					t.Fatalf("setting links %s unexpected error: %s", state, s)
+				}
+				want := []string{
+					"r1: ip link set eth1 " + state,
+					"r2: ip link set eth2 " + state,
+					"r2: ip link set eth1 " + state,
+					"r3: ip link set eth1 " + state,
+				}
+				if s := cmp.Diff(want, cmds); s != "" {
+					t.Errorf("setting links %s unexpected commands (-want +got):\n%s", state, s)
+				}
+			}
+		})
+	}
+}
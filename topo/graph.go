@@ -0,0 +1,282 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tpb "github.com/openconfig/kne/proto/topo"
+)
+
+// GraphNode is a node of a Graph.
+type GraphNode struct {
+	Name   string
+	Vendor tpb.Vendor
+}
+
+// GraphEdge is a link between two node interfaces of a Graph.
+type GraphEdge struct {
+	ANode string
+	AInt  string
+	ZNode string
+	ZInt  string
+}
+
+// Graph is the topology as a list of nodes and the links between them.
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// TopologyGraph returns the nodes and links of the topology proto as a
+// Graph. Nodes are in the order of the topology.
+func (m *Manager) TopologyGraph() *Graph {
+	g := &Graph{}
+	for _, n := range m.topo.GetNodes() {
+		g.Nodes = append(g.Nodes, GraphNode{Name: n.GetName(), Vendor: n.GetVendor()})
+	}
+	for _, l := range m.topo.GetLinks() {
+		g.Edges = append(g.Edges, GraphEdge{ANode: l.GetANode(), AInt: l.GetAInt(), ZNode: l.GetZNode(), ZInt: l.GetZInt()})
+	}
+	return g
+}
+
+// ShortestPath returns the names of the nodes on a path with the fewest links
+// from src to dst, including both. Links are bidirectional. If several paths
+// are equally short, the path through the lexically smallest neighbors is
+// returned.
+func (g *Graph) ShortestPath(src, dst string) ([]string, error) {
+	adj := map[string][]string{}
+	for _, n := range g.Nodes {
+		adj[n.Name] = nil
+	}
+	for _, e := range g.Edges {
+		adj[e.ANode] = append(adj[e.ANode], e.ZNode)
+		adj[e.ZNode] = append(adj[e.ZNode], e.ANode)
+	}
+	for _, name := range []string{src, dst} {
+		if _, ok := adj[name]; !ok {
+			return nil, fmt.Errorf("node %q not found", name)
+		}
+	}
+	for _, ns := range adj {
+		sort.Strings(ns)
+	}
+	prev := map[string]string{src: ""}
+	queue := []string{src}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == dst {
+			var path []string
+			for n := dst; n != src; n = prev[n] {
+				path = append(path, n)
+			}
+			path = append(path, src)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path, nil
+		}
+		for _, n := range adj[cur] {
+			if _, ok := prev[n]; ok {
+				continue
+			}
+			prev[n] = cur
+			queue = append(queue, n)
+		}
+	}
+	return nil, fmt.Errorf("no path from node %q to node %q", src, dst)
+}
+
+const (
+	DiagramFormatDOT     = "dot"
+	DiagramFormatMermaid = "mermaid"
+)
+
+// nodeStyle is how the nodes of a vendor are drawn in a diagram.
+type nodeStyle struct {
+	// shape is the Graphviz node shape.
+	shape string
+	// open and close delimit the node label in Mermaid, selecting its
+	// shape.
+	open, close string
+	color       string
+}
+
+var (
+	defaultNodeStyle = nodeStyle{shape: "box", open: "[", close: "]", color: "lightgray"}
+	vendorNodeStyles = map[tpb.Vendor]nodeStyle{
+		tpb.Vendor_HOST:       {shape: "ellipse", open: "(", close: ")", color: "white"},
+		tpb.Vendor_ARISTA:     {shape: "box", open: "[", close: "]", color: "lightblue"},
+		tpb.Vendor_CISCO:      {shape: "box", open: "[", close: "]", color: "lightskyblue"},
+		tpb.Vendor_JUNIPER:    {shape: "box", open: "[", close: "]", color: "palegreen"},
+		tpb.Vendor_NOKIA:      {shape: "box", open: "[", close: "]", color: "lightsalmon"},
+		tpb.Vendor_KEYSIGHT:   {shape: "hexagon", open: "{{", close: "}}", color: "plum"},
+		tpb.Vendor_FRR:        {shape: "circle", open: "((", close: "))", color: "khaki"},
+		tpb.Vendor_QUAGGA:     {shape: "circle", open: "((", close: "))", color: "wheat"},
+		tpb.Vendor_GOBGP:      {shape: "circle", open: "((", close: "))", color: "lightpink"},
+		tpb.Vendor_OPENCONFIG: {shape: "box", open: "[", close: "]", color: "lightcyan"},
+	}
+)
+
+func styleOf(v tpb.Vendor) nodeStyle {
+	if s, ok := vendorNodeStyles[v]; ok {
+		return s
+	}
+	return defaultNodeStyle
+}
+
+// DOT returns the graph in the Graphviz DOT language. Nodes are labeled with
+// their name and vendor and drawn in the style of their vendor. Edges are
+// labeled with the interfaces they connect.
+func (g *Graph) DOT(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph %q {\n", name)
+	fmt.Fprintln(&b, "  node [style=filled];")
+	for _, n := range g.Nodes {
+		s := styleOf(n.Vendor)
+		fmt.Fprintf(&b, "  %q [label=%q, shape=%s, fillcolor=%q];\n", n.Name, n.Name+"\n"+n.Vendor.String(), s.shape, s.color)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -- %q [taillabel=%q, headlabel=%q];\n", e.ANode, e.ZNode, e.AInt, e.ZInt)
+	}
+	fmt.Fprintln(&b, "}")
+	return b.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart. Nodes are labeled with
+// their name and vendor and drawn in the style of their vendor. Edges are
+// labeled with the interfaces they connect.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, "graph LR")
+	// Node names are not valid Mermaid identifiers in general, so nodes are
+	// identified by their index.
+	ids := map[string]string{}
+	id := func(name string) string {
+		if i, ok := ids[name]; ok {
+			return i
+		}
+		ids[name] = fmt.Sprintf("n%d", len(ids))
+		return ids[name]
+	}
+	vendors := map[tpb.Vendor]bool{}
+	for _, n := range g.Nodes {
+		s := styleOf(n.Vendor)
+		fmt.Fprintf(&b, "  %s%s\"%s<br/>%s\"%s:::%s\n", id(n.Name), s.open, n.Name, n.Vendor, s.close, strings.ToLower(n.Vendor.String()))
+		vendors[n.Vendor] = true
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s ---|\"%s - %s\"| %s\n", id(e.ANode), e.AInt, e.ZInt, id(e.ZNode))
+	}
+	var classes []tpb.Vendor
+	for v := range vendors {
+		classes = append(classes, v)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	for _, v := range classes {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", strings.ToLower(v.String()), styleOf(v).color)
+	}
+	return b.String()
+}
+
+// ExportTopologyDiagram returns the topology graph as a diagram in format,
+// DiagramFormatDOT or DiagramFormatMermaid.
+func (m *Manager) ExportTopologyDiagram(format string) (string, error) {
+	g := m.TopologyGraph()
+	switch format {
+	case DiagramFormatDOT:
+		return g.DOT(m.topo.GetName()), nil
+	case DiagramFormatMermaid:
+		return g.Mermaid(), nil
+	default:
+		return "", fmt.Errorf("unknown diagram format %q", format)
+	}
+}
+
+// ValidationWarning is an anomaly in the links of a topology which may be
+// intentional, such as a node without links.
+type ValidationWarning struct {
+	Node      string
+	Interface string
+	Message   string
+}
+
+func (w ValidationWarning) String() string {
+	if w.Interface == "" {
+		return fmt.Sprintf("node %q: %s", w.Node, w.Message)
+	}
+	return fmt.Sprintf("interface %s:%s: %s", w.Node, w.Interface, w.Message)
+}
+
+// ValidateLinks returns warnings for the nodes of t without any links, unless
+// t has a single node, the link endpoints which are not declared in the
+// interfaces of their node and the interfaces whose peer interface is not
+// connected back to them. Wildcard endpoints such as "eth*" and endpoints of
+// nodes which do not exist are skipped as they are handled when loading and
+// validating the topology.
+func ValidateLinks(t *tpb.Topology) []ValidationWarning {
+	var warnings []ValidationWarning
+	nodes := map[string]*tpb.Node{}
+	for _, n := range t.GetNodes() {
+		nodes[n.GetName()] = n
+	}
+	linked := map[string]bool{}
+	for _, l := range t.GetLinks() {
+		for _, e := range [][2]string{{l.GetANode(), l.GetAInt()}, {l.GetZNode(), l.GetZInt()}} {
+			linked[e[0]] = true
+			n, ok := nodes[e[0]]
+			if !ok || strings.HasSuffix(e[1], "*") {
+				continue
+			}
+			if _, ok := n.GetInterfaces()[e[1]]; !ok {
+				warnings = append(warnings, ValidationWarning{Node: e[0], Interface: e[1], Message: "used by a link but not declared by the node"})
+			}
+		}
+	}
+	if len(t.GetNodes()) > 1 {
+		for _, n := range t.GetNodes() {
+			if !linked[n.GetName()] {
+				warnings = append(warnings, ValidationWarning{Node: n.GetName(), Message: "has no links"})
+			}
+		}
+	}
+	for _, n := range t.GetNodes() {
+		var names []string
+		for name := range n.GetInterfaces() {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			intf := n.GetInterfaces()[name]
+			if intf.GetPeerName() == "" {
+				continue
+			}
+			peer, ok := nodes[intf.GetPeerName()]
+			if !ok {
+				warnings = append(warnings, ValidationWarning{Node: n.GetName(), Interface: name, Message: fmt.Sprintf("peer node %q does not exist", intf.GetPeerName())})
+				continue
+			}
+			pIntf := peer.GetInterfaces()[intf.GetPeerIntName()]
+			if pIntf.GetPeerName() != n.GetName() || pIntf.GetPeerIntName() != name {
+				warnings = append(warnings, ValidationWarning{Node: n.GetName(), Interface: name, Message: fmt.Sprintf("peer %s:%s is not connected back to it", intf.GetPeerName(), intf.GetPeerIntName())})
+			}
+		}
+	}
+	return warnings
+}
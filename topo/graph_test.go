@@ -0,0 +1,261 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+)
+
+func TestTopologyGraph(t *testing.T) {
+	m := &Manager{topo: &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Vendor: tpb.Vendor_ARISTA},
+			{Name: "r2", Vendor: tpb.Vendor_JUNIPER},
+			{Name: "r3", Vendor: tpb.Vendor_CISCO},
+			{Name: "r4", Vendor: tpb.Vendor_NOKIA},
+			{Name: "r5", Vendor: tpb.Vendor_HOST},
+		},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			{ANode: "r3", AInt: "eth2", ZNode: "r1", ZInt: "eth2"},
+			{ANode: "r4", AInt: "eth1", ZNode: "r3", ZInt: "eth3"},
+		},
+	}}
+	g := m.TopologyGraph()
+	want := &Graph{
+		Nodes: []GraphNode{
+			{Name: "r1", Vendor: tpb.Vendor_ARISTA},
+			{Name: "r2", Vendor: tpb.Vendor_JUNIPER},
+			{Name: "r3", Vendor: tpb.Vendor_CISCO},
+			{Name: "r4", Vendor: tpb.Vendor_NOKIA},
+			{Name: "r5", Vendor: tpb.Vendor_HOST},
+		},
+		Edges: []GraphEdge{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			{ANode: "r3", AInt: "eth2", ZNode: "r1", ZInt: "eth2"},
+			{ANode: "r4", AInt: "eth1", ZNode: "r3", ZInt: "eth3"},
+		},
+	}
+	if s := cmp.Diff(want, g); s != "" {
+		t.Fatalf("TopologyGraph() unexpected diff (-want +got):\n%s", s)
+	}
+
+	tests := []struct {
+		desc     string
+		src, dst string
+		want     []string
+		wantErr  string
+	}{{
+		desc: "same node",
+		src:  "r1",
+		dst:  "r1",
+		want: []string{"r1"},
+	}, {
+		desc: "adjacent",
+		src:  "r1",
+		dst:  "r2",
+		want: []string{"r1", "r2"},
+	}, {
+		desc: "reverse link",
+		src:  "r1",
+		dst:  "r3",
+		want: []string{"r1", "r3"},
+	}, {
+		desc: "two hops",
+		src:  "r2",
+		dst:  "r4",
+		want: []string{"r2", "r3", "r4"},
+	}, {
+		desc:    "unreachable",
+		src:     "r1",
+		dst:     "r5",
+		wantErr: "no path",
+	}, {
+		desc:    "missing node",
+		src:     "r1",
+		dst:     "dne",
+		wantErr: `node "dne" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := g.ShortestPath(tt.src, tt.dst)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ShortestPath() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("ShortestPath() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestExportTopologyDiagram(t *testing.T) {
+	m := &Manager{topo: &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Vendor: tpb.Vendor_ARISTA},
+			{Name: "otg-1", Vendor: tpb.Vendor_KEYSIGHT},
+		},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "otg-1", ZInt: "eth2"},
+		},
+	}}
+	tests := []struct {
+		desc    string
+		format  string
+		want    string
+		wantErr string
+	}{{
+		desc:   "dot",
+		format: DiagramFormatDOT,
+		want: `graph "test" {
+  node [style=filled];
+  "r1" [label="r1\nARISTA", shape=box, fillcolor="lightblue"];
+  "otg-1" [label="otg-1\nKEYSIGHT", shape=hexagon, fillcolor="plum"];
+  "r1" -- "otg-1" [taillabel="eth1", headlabel="eth2"];
+}
+`,
+	}, {
+		desc:   "mermaid",
+		format: DiagramFormatMermaid,
+		want: `graph LR
+  n0["r1<br/>ARISTA"]:::arista
+  n1{{"otg-1<br/>KEYSIGHT"}}:::keysight
+  n0 ---|"eth1 - eth2"| n1
+  classDef arista fill:lightblue
+  classDef keysight fill:plum
+`,
+	}, {
+		desc:    "unknown format",
+		format:  "svg",
+		wantErr: `unknown diagram format "svg"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := m.ExportTopologyDiagram(tt.format)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ExportTopologyDiagram() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("ExportTopologyDiagram() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestValidateLinks(t *testing.T) {
+	tests := []struct {
+		desc string
+		topo *tpb.Topology
+		want []ValidationWarning
+	}{{
+		desc: "valid",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{
+				Name: "r1",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {PeerName: "r2", PeerIntName: "eth1"},
+				},
+			}, {
+				Name: "r2",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {PeerName: "r1", PeerIntName: "eth1"},
+				},
+			}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			},
+		},
+	}, {
+		desc: "single node",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}},
+		},
+	}, {
+		desc: "island",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}},
+		},
+		want: []ValidationWarning{
+			{Node: "r1", Message: "has no links"},
+			{Node: "r2", Message: "has no links"},
+		},
+	}, {
+		desc: "dangling interfaces",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{
+				Name: "r1",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {},
+				},
+			}, {
+				Name: "r2",
+			}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth2", ZNode: "r2", ZInt: "eth*"},
+				{ANode: "r1", AInt: "eth3", ZNode: "r3", ZInt: "eth1"},
+			},
+		},
+		want: []ValidationWarning{
+			{Node: "r2", Interface: "eth1", Message: "used by a link but not declared by the node"},
+			{Node: "r1", Interface: "eth2", Message: "used by a link but not declared by the node"},
+			{Node: "r1", Interface: "eth3", Message: "used by a link but not declared by the node"},
+		},
+	}, {
+		desc: "asymmetric peers",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{
+				Name: "r1",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {PeerName: "r2", PeerIntName: "eth1"},
+					"eth2": {PeerName: "r4", PeerIntName: "eth1"},
+				},
+			}, {
+				Name: "r2",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {PeerName: "r3", PeerIntName: "eth1"},
+				},
+			}, {
+				Name: "r3",
+				Interfaces: map[string]*tpb.Interface{
+					"eth1": {PeerName: "r2", PeerIntName: "eth1"},
+				},
+			}},
+		},
+		want: []ValidationWarning{
+			{Node: "r1", Message: "has no links"},
+			{Node: "r2", Message: "has no links"},
+			{Node: "r3", Message: "has no links"},
+			{Node: "r1", Interface: "eth1", Message: `peer r2:eth1 is not connected back to it`},
+			{Node: "r1", Interface: "eth2", Message: `peer node "r4" does not exist`},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := ValidateLinks(tt.topo)
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("ValidateLinks() unexpected warnings (-want +got):\n%s", s)
+			}
+		})
+	}
+}
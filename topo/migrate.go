@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"fmt"
+
+	tpb "github.com/openconfig/kne/proto/topo"
+	"google.golang.org/protobuf/proto"
+)
+
+// CurrentSchemaVersion is the version of the topology schema topologies are
+// migrated to by MigrateTopology.
+const CurrentSchemaVersion = 2
+
+// migrations holds the function migrating a topology from each schema
+// version to the next.
+var migrations = map[int32]func(*tpb.Topology) error{
+	1: migrateNodeTypes,
+}
+
+// MigrateTopology returns a copy of t migrated from its schema version to
+// CurrentSchemaVersion. A topology without a schema version is treated as
+// version 1. t is not modified.
+func MigrateTopology(t *tpb.Topology) (*tpb.Topology, error) {
+	m := proto.Clone(t).(*tpb.Topology)
+	v := m.GetSchemaVersion()
+	if v == 0 {
+		v = 1
+	}
+	if v > CurrentSchemaVersion {
+		return nil, fmt.Errorf("topology schema version %d is newer than supported version %d", v, CurrentSchemaVersion)
+	}
+	for ; v < CurrentSchemaVersion; v++ {
+		if err := migrations[v](m); err != nil {
+			return nil, fmt.Errorf("failed to migrate topology from schema version %d: %w", v, err)
+		}
+	}
+	m.SchemaVersion = CurrentSchemaVersion
+	return m, nil
+}
+
+// typeVendors maps the deprecated node types to the vendor and model
+// replacing them. An empty model leaves the model of the node unset.
+var typeVendors = map[tpb.Node_Type]struct {
+	vendor tpb.Vendor
+	model  string
+}{
+	tpb.Node_HOST:         {tpb.Vendor_HOST, ""},
+	tpb.Node_ARISTA_CEOS:  {tpb.Vendor_ARISTA, ""},
+	tpb.Node_JUNIPER_CEVO: {tpb.Vendor_JUNIPER, "cptx"},
+	tpb.Node_CISCO_CXR:    {tpb.Vendor_CISCO, ""},
+	tpb.Node_QUAGGA:       {tpb.Vendor_QUAGGA, ""},
+	tpb.Node_FRR:          {tpb.Vendor_FRR, ""},
+	tpb.Node_JUNIPER_VMX:  {tpb.Vendor_JUNIPER, ""},
+	tpb.Node_CISCO_CSR:    {tpb.Vendor_CISCO, ""},
+	tpb.Node_NOKIA_SRL:    {tpb.Vendor_NOKIA, ""},
+	tpb.Node_IXIA_TG:      {tpb.Vendor_KEYSIGHT, ""},
+	tpb.Node_GOBGP:        {tpb.Vendor_GOBGP, ""},
+	tpb.Node_CISCO_XRD:    {tpb.Vendor_CISCO, "xrd"},
+	tpb.Node_CISCO_E8000:  {tpb.Vendor_CISCO, ""},
+	tpb.Node_LEMMING:      {tpb.Vendor_OPENCONFIG, "lemming"},
+}
+
+// migrateNodeTypes sets the vendor and model of each node from its deprecated
+// type. The vendor and model already set on a node are kept. The type is also
+// kept so nodes can still be found by it, such as with FindNodes.
+func migrateNodeTypes(t *tpb.Topology) error {
+	for _, n := range t.Nodes {
+		typ := n.GetType()
+		if typ == tpb.Node_UNKNOWN {
+			continue
+		}
+		tv, ok := typeVendors[typ]
+		if !ok {
+			return fmt.Errorf("node %q has unknown type %v", n.GetName(), typ)
+		}
+		if n.Vendor == tpb.Vendor_UNKNOWN {
+			n.Vendor = tv.vendor
+		} else if n.Vendor != tv.vendor {
+			return fmt.Errorf("node %q has type %v conflicting with vendor %v", n.GetName(), typ, n.Vendor)
+		}
+		if n.Model == "" {
+			n.Model = tv.model
+		}
+	}
+	return nil
+}
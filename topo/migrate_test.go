@@ -0,0 +1,135 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestMigrateTopology(t *testing.T) {
+	tests := []struct {
+		desc    string
+		in      *tpb.Topology
+		want    *tpb.Topology
+		wantErr string
+	}{{
+		desc: "v1 node types",
+		in: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{{
+				Name:     "r1",
+				Type:     tpb.Node_ARISTA_CEOS,
+				Labels:   map[string]string{"role": "dut"},
+				Config:   &tpb.Config{Image: "ceos:latest"},
+				Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
+			}, {
+				Name: "r2",
+				Type: tpb.Node_CISCO_XRD,
+			}, {
+				Name:  "r3",
+				Type:  tpb.Node_CISCO_E8000,
+				Model: "8201",
+			}, {
+				Name:   "r4",
+				Vendor: tpb.Vendor_NOKIA,
+			}},
+			Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+		},
+		want: &tpb.Topology{
+			Name:          "test",
+			SchemaVersion: 2,
+			Nodes: []*tpb.Node{{
+				Name:     "r1",
+				Type:     tpb.Node_ARISTA_CEOS,
+				Vendor:   tpb.Vendor_ARISTA,
+				Labels:   map[string]string{"role": "dut"},
+				Config:   &tpb.Config{Image: "ceos:latest"},
+				Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
+			}, {
+				Name:   "r2",
+				Type:   tpb.Node_CISCO_XRD,
+				Vendor: tpb.Vendor_CISCO,
+				Model:  "xrd",
+			}, {
+				Name:   "r3",
+				Type:   tpb.Node_CISCO_E8000,
+				Vendor: tpb.Vendor_CISCO,
+				Model:  "8201",
+			}, {
+				Name:   "r4",
+				Vendor: tpb.Vendor_NOKIA,
+			}},
+			Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+		},
+	}, {
+		desc: "current version",
+		in: &tpb.Topology{
+			Name:          "test",
+			SchemaVersion: 2,
+			Nodes:         []*tpb.Node{{Name: "r1", Vendor: tpb.Vendor_ARISTA}},
+		},
+		want: &tpb.Topology{
+			Name:          "test",
+			SchemaVersion: 2,
+			Nodes:         []*tpb.Node{{Name: "r1", Vendor: tpb.Vendor_ARISTA}},
+		},
+	}, {
+		desc: "conflicting vendor",
+		in: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1", Type: tpb.Node_ARISTA_CEOS, Vendor: tpb.Vendor_CISCO}},
+		},
+		wantErr: "conflicting with vendor",
+	}, {
+		desc:    "newer version",
+		in:      &tpb.Topology{SchemaVersion: 3},
+		wantErr: "newer than supported",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			in := proto.Clone(tt.in).(*tpb.Topology)
+			got, err := MigrateTopology(in)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("MigrateTopology() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.in, in, protocmp.Transform()); s != "" {
+				t.Errorf("MigrateTopology() modified input (-want +got):\n%s", s)
+			}
+			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
+				t.Errorf("MigrateTopology() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadMigrates(t *testing.T) {
+	got, err := LoadFromBytes([]byte(`name: "test" nodes: { name: "r1" type: JUNIPER_CEVO }`), "proto")
+	if err != nil {
+		t.Fatalf("LoadFromBytes() unexpected err: %v", err)
+	}
+	want := &tpb.Topology{
+		Name:          "test",
+		SchemaVersion: CurrentSchemaVersion,
+		Nodes:         []*tpb.Node{{Name: "r1", Type: tpb.Node_JUNIPER_CEVO, Vendor: tpb.Vendor_JUNIPER, Model: "cptx"}},
+	}
+	if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+		t.Errorf("LoadFromBytes() unexpected diff (-want +got):\n%s", s)
+	}
+}
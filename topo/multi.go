@@ -0,0 +1,116 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnmi/errlist"
+	"github.com/openconfig/kne/topo/node"
+	"golang.org/x/sync/errgroup"
+)
+
+// TopologyManager is the set of topology operations MultiManager needs from
+// each of its topologies. It is implemented by Manager.
+type TopologyManager interface {
+	Create(ctx context.Context, timeout time.Duration) error
+	Delete(ctx context.Context) error
+	CheckNodeStatus(ctx context.Context, timeout time.Duration) error
+	Nodes() map[string]node.Node
+}
+
+var _ TopologyManager = (*Manager)(nil)
+
+// MultiManager manages several independent topologies as a unit.
+type MultiManager struct {
+	managers []TopologyManager
+}
+
+// NewMultiManager returns a MultiManager for the provided topologies.
+func NewMultiManager(managers ...TopologyManager) *MultiManager {
+	return &MultiManager{managers: managers}
+}
+
+// Create creates all of the topologies concurrently. The errors of all
+// topologies which failed to be created are returned together.
+func (mm *MultiManager) Create(ctx context.Context, timeout time.Duration) error {
+	return mm.do(ctx, func(ctx context.Context, m TopologyManager) error {
+		return m.Create(ctx, timeout)
+	})
+}
+
+// Delete deletes all of the topologies concurrently. The errors of all
+// topologies which failed to be deleted are returned together.
+func (mm *MultiManager) Delete(ctx context.Context) error {
+	return mm.do(ctx, func(ctx context.Context, m TopologyManager) error {
+		return m.Delete(ctx)
+	})
+}
+
+// CheckNodeStatus checks the node status of all of the topologies
+// concurrently. The errors of all topologies whose nodes failed are returned
+// together.
+func (mm *MultiManager) CheckNodeStatus(ctx context.Context, timeout time.Duration) error {
+	return mm.do(ctx, func(ctx context.Context, m TopologyManager) error {
+		return m.CheckNodeStatus(ctx, timeout)
+	})
+}
+
+// Nodes returns the nodes of all of the topologies.
+func (mm *MultiManager) Nodes() []node.Node {
+	var nodes []node.Node
+	for _, m := range mm.managers {
+		for _, n := range m.Nodes() {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Node returns the node with the provided name from the first topology which
+// contains it.
+func (mm *MultiManager) Node(name string) (node.Node, error) {
+	for _, m := range mm.managers {
+		if n, ok := m.Nodes()[name]; ok {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("node %q not found", name)
+}
+
+// do calls f for each topology concurrently and collects the errors.
+func (mm *MultiManager) do(ctx context.Context, f func(context.Context, TopologyManager) error) error {
+	var mu sync.Mutex
+	var errs errlist.List
+	var g errgroup.Group
+	for i, m := range mm.managers {
+		i, m := i, m
+		g.Go(func() error {
+			if err := f(ctx, m); err != nil {
+				mu.Lock()
+				errs.Add(fmt.Errorf("topology %d: %w", i, err))
+				mu.Unlock()
+			}
+			// Errors are collected rather than returned so a failing
+			// topology does not cancel the others.
+			return nil
+		})
+	}
+	g.Wait()
+	return errs.Err()
+}
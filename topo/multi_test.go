@@ -0,0 +1,167 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+)
+
+type fakeManager struct {
+	nodes     map[string]node.Node
+	createErr error
+	deleteErr error
+	statusErr error
+	// started is marked done when Create is called and Create then waits
+	// for all managers to be started.
+	started *sync.WaitGroup
+	deleted bool
+	checked bool
+}
+
+func (f *fakeManager) Create(ctx context.Context, _ time.Duration) error {
+	f.started.Done()
+	done := make(chan struct{})
+	go func() {
+		f.started.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("topologies not created concurrently")
+	}
+	return f.createErr
+}
+
+func (f *fakeManager) Delete(_ context.Context) error {
+	f.deleted = true
+	return f.deleteErr
+}
+
+func (f *fakeManager) CheckNodeStatus(_ context.Context, _ time.Duration) error {
+	f.checked = true
+	return f.statusErr
+}
+
+func (f *fakeManager) Nodes() map[string]node.Node {
+	return f.nodes
+}
+
+func newFakeNode(ns, name string) node.Node {
+	return &configurable{Impl: &node.Impl{Namespace: ns, Proto: &tpb.Node{Name: name}}}
+}
+
+func TestMultiManager(t *testing.T) {
+	tests := []struct {
+		desc      string
+		createErr []error
+		deleteErr []error
+		statusErr []error
+		wantErr   string
+	}{{
+		desc:      "success",
+		createErr: []error{nil, nil, nil},
+		deleteErr: []error{nil, nil, nil},
+		statusErr: []error{nil, nil, nil},
+	}, {
+		desc:      "partial failure",
+		createErr: []error{nil, fmt.Errorf("create failed"), nil},
+		deleteErr: []error{fmt.Errorf("delete failed"), nil, nil},
+		statusErr: []error{nil, nil, fmt.Errorf("node failed")},
+		wantErr:   "topology 1: create failed",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			var started sync.WaitGroup
+			var fakes []*fakeManager
+			var managers []TopologyManager
+			for i := range tt.createErr {
+				ns := fmt.Sprintf("t%d", i)
+				f := &fakeManager{
+					nodes: map[string]node.Node{
+						ns + "-r1": newFakeNode(ns, ns+"-r1"),
+					},
+					createErr: tt.createErr[i],
+					deleteErr: tt.deleteErr[i],
+					statusErr: tt.statusErr[i],
+					started:   &started,
+				}
+				started.Add(1)
+				fakes = append(fakes, f)
+				managers = append(managers, f)
+			}
+			mm := NewMultiManager(managers...)
+			err := mm.Create(ctx, time.Minute)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("Create() unexpected err: %s", s)
+			}
+			err = mm.CheckNodeStatus(ctx, time.Minute)
+			for i, f := range fakes {
+				if !f.checked {
+					t.Errorf("CheckNodeStatus() did not check topology %d", i)
+				}
+				if tt.statusErr[i] != nil {
+					if s := errdiff.Check(err, fmt.Sprintf("topology %d: %v", i, tt.statusErr[i])); s != "" {
+						t.Errorf("CheckNodeStatus() unexpected err: %s", s)
+					}
+				}
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CheckNodeStatus() unexpected err: %v", err)
+			}
+			err = mm.Delete(ctx)
+			for i, f := range fakes {
+				if !f.deleted {
+					t.Errorf("Delete() did not delete topology %d", i)
+				}
+				if tt.deleteErr[i] != nil {
+					if s := errdiff.Check(err, tt.deleteErr[i].Error()); s != "" {
+						t.Errorf("Delete() unexpected err: %s", s)
+					}
+				}
+			}
+			var got []string
+			for _, n := range mm.Nodes() {
+				got = append(got, n.Name())
+			}
+			sort.Strings(got)
+			if s := cmp.Diff([]string{"t0-r1", "t1-r1", "t2-r1"}, got); s != "" {
+				t.Errorf("Nodes() unexpected diff (-want +got):\n%s", s)
+			}
+			n, err := mm.Node("t2-r1")
+			if err != nil {
+				t.Fatalf("Node() unexpected err: %v", err)
+			}
+			if n.GetNamespace() != "t2" {
+				t.Errorf("Node() got node from topology %q, want %q", n.GetNamespace(), "t2")
+			}
+			if _, err := mm.Node("dne"); err == nil {
+				t.Errorf("Node() of missing node succeeded, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/openconfig/gnmi/errlist"
+	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "k8s.io/klog/v2"
+)
+
+// addrAdd returns the address n addresses after a.
+func addrAdd(a netip.Addr, n uint64) netip.Addr {
+	b := a.As16()
+	for i := len(b) - 1; i >= 0 && n > 0; i-- {
+		sum := uint64(b[i]) + n&0xff
+		b[i] = byte(sum)
+		n = n>>8 + sum>>8
+	}
+	if a.Is4() {
+		return netip.AddrFrom16(b).Unmap()
+	}
+	return netip.AddrFrom16(b)
+}
+
+// linkAddrs allocates a point-to-point subnet from cidr to each link of the
+// topology, a /31 for IPv4 or a /127 for IPv6, in the order of the links.
+// The first address of each subnet is assigned to the A end and the second
+// to the Z end. The addresses are returned keyed by node and interface name.
+func (m *Manager) linkAddrs(cidr string) (map[string]map[string]netip.Prefix, error) {
+	p, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid CIDR %q: %v", cidr, err)
+	}
+	p = p.Masked()
+	bits := p.Addr().BitLen() - 1
+	links := m.topo.GetLinks()
+	if hostBits := bits - p.Bits(); hostBits < 0 || (hostBits < 64 && uint64(len(links)) > 1<<hostBits) {
+		return nil, status.Errorf(codes.InvalidArgument, "CIDR %q is too small for %d links", cidr, len(links))
+	}
+	addrs := map[string]map[string]netip.Prefix{}
+	add := func(nodeName, intf string, a netip.Addr) {
+		if addrs[nodeName] == nil {
+			addrs[nodeName] = map[string]netip.Prefix{}
+		}
+		addrs[nodeName][intf] = netip.PrefixFrom(a, bits)
+	}
+	for i, l := range links {
+		a := addrAdd(p.Addr(), 2*uint64(i))
+		add(l.GetANode(), l.GetAInt(), a)
+		add(l.GetZNode(), l.GetZInt(), a.Next())
+	}
+	return addrs, nil
+}
+
+// GenerateNetworkConfig allocates a point-to-point subnet from cidr to each
+// link, a /31 for IPv4 or a /127 for IPv6, and returns the config assigning
+// the addresses to the interfaces of each node, keyed by node name. The
+// config is in the format accepted by the ConfigPush of the node. Nodes
+// which do not fulfill NetworkConfiger are skipped.
+func (m *Manager) GenerateNetworkConfig(ctx context.Context, cidr string) (map[string]string, error) {
+	addrs, err := m.linkAddrs(cidr)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range addrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	configs := map[string]string{}
+	var errs errlist.List
+	for _, name := range names {
+		n, ok := m.nodes[name]
+		if !ok {
+			errs.Add(status.Errorf(codes.NotFound, "node %q not found", name))
+			continue
+		}
+		nc, ok := n.(node.NetworkConfiger)
+		if !ok {
+			log.Infof("Node %q does not implement NetworkConfiger interface, skipping network config", name)
+			continue
+		}
+		cfg, err := nc.NetworkConfig(addrs[name])
+		if err != nil {
+			errs.Add(fmt.Errorf("failed to generate network config for node %q: %w", name, err))
+			continue
+		}
+		configs[name] = cfg
+	}
+	return configs, errs.Err()
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+)
+
+type netConfigNode struct {
+	*node.Impl
+}
+
+func (n *netConfigNode) NetworkConfig(addrs map[string]netip.Prefix) (string, error) {
+	var lines []string
+	for intf, a := range addrs {
+		lines = append(lines, fmt.Sprintf("%s %s", intf, a))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n"), nil
+}
+
+func TestGenerateNetworkConfig(t *testing.T) {
+	links := []*tpb.Link{
+		{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+		{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+		{ANode: "r3", AInt: "eth2", ZNode: "r1", ZInt: "eth2"},
+		{ANode: "r1", AInt: "eth3", ZNode: "r4", ZInt: "eth1"},
+	}
+	m := &Manager{
+		topo: &tpb.Topology{Links: links},
+		nodes: map[string]node.Node{
+			"r1": &netConfigNode{},
+			"r2": &netConfigNode{},
+			"r3": &netConfigNode{},
+			"r4": &configurable{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		cidr    string
+		want    map[string]string
+		wantErr string
+	}{{
+		desc: "ipv4",
+		cidr: "10.0.0.0/29",
+		want: map[string]string{
+			"r1": "eth1 10.0.0.0/31\neth2 10.0.0.5/31\neth3 10.0.0.6/31",
+			"r2": "eth1 10.0.0.1/31\neth2 10.0.0.2/31",
+			"r3": "eth1 10.0.0.3/31\neth2 10.0.0.4/31",
+		},
+	}, {
+		desc: "ipv4 unmasked",
+		cidr: "192.168.0.254/23",
+		want: map[string]string{
+			"r1": "eth1 192.168.0.0/31\neth2 192.168.0.5/31\neth3 192.168.0.6/31",
+			"r2": "eth1 192.168.0.1/31\neth2 192.168.0.2/31",
+			"r3": "eth1 192.168.0.3/31\neth2 192.168.0.4/31",
+		},
+	}, {
+		desc: "ipv6",
+		cidr: "2001:db8::/64",
+		want: map[string]string{
+			"r1": "eth1 2001:db8::/127\neth2 2001:db8::5/127\neth3 2001:db8::6/127",
+			"r2": "eth1 2001:db8::1/127\neth2 2001:db8::2/127",
+			"r3": "eth1 2001:db8::3/127\neth2 2001:db8::4/127",
+		},
+	}, {
+		desc:    "too small",
+		cidr:    "10.0.0.0/30",
+		wantErr: "too small for 4 links",
+	}, {
+		desc:    "invalid",
+		cidr:    "10.0.0.0",
+		wantErr: "invalid CIDR",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := m.GenerateNetworkConfig(context.Background(), tt.cidr)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("GenerateNetworkConfig() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("GenerateNetworkConfig() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLinkAddrsUnique(t *testing.T) {
+	m := &Manager{topo: &tpb.Topology{}}
+	for i := 0; i < 300; i++ {
+		m.topo.Links = append(m.topo.Links, &tpb.Link{ANode: fmt.Sprintf("a%d", i), AInt: "eth1", ZNode: fmt.Sprintf("z%d", i), ZInt: "eth1"})
+	}
+	addrs, err := m.linkAddrs("10.1.0.0/22")
+	if err != nil {
+		t.Fatalf("linkAddrs() unexpected err: %v", err)
+	}
+	seenAddrs := map[netip.Addr]bool{}
+	seenSubnets := map[netip.Prefix]bool{}
+	for i, l := range m.topo.Links {
+		a, z := addrs[l.ANode][l.AInt], addrs[l.ZNode][l.ZInt]
+		if a.Masked() != z.Masked() {
+			t.Errorf("link %d: endpoints %v and %v are not in the same subnet", i, a, z)
+		}
+		if seenSubnets[a.Masked()] {
+			t.Errorf("link %d: subnet %v already allocated", i, a.Masked())
+		}
+		seenSubnets[a.Masked()] = true
+		for _, p := range []netip.Prefix{a, z} {
+			if !netip.MustParsePrefix("10.1.0.0/22").Contains(p.Addr()) {
+				t.Errorf("link %d: address %v outside of CIDR", i, p)
+			}
+			if seenAddrs[p.Addr()] {
+				t.Errorf("link %d: address %v already allocated", i, p.Addr())
+			}
+			seenAddrs[p.Addr()] = true
+		}
+	}
+}
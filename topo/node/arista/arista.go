@@ -18,9 +18,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/netip"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -100,6 +102,24 @@ func (n *Node) Create(ctx context.Context) error {
 	return nil
 }
 
+// PodSpec returns an Unimplemented error since the pod of the node is created
+// by its controller from the custom resource rather than by KNE.
+func (n *Node) PodSpec(_ context.Context) (*corev1.Pod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// Update returns an Unimplemented error since the pod of the node would be
+// re-created by its controller rather than from the node proto.
+func (n *Node) Update(_ context.Context) error {
+	return status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// WaitForInterfaces returns an Unimplemented error since the container of
+// the pod created by the controller of the node is not named after the node.
+func (n *Node) WaitForInterfaces(_ context.Context, _ time.Duration) error {
+	return status.Errorf(codes.Unimplemented, "interfaces of node %q cannot be listed", n.Name())
+}
+
 func (n *Node) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 	pb := n.Proto
 	var data []byte
@@ -114,19 +134,9 @@ func (n *Node) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 		data = v.Data
 	}
 	if data != nil {
-		cm := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("%s-config", pb.Name),
-			},
-			Data: map[string]string{
-				pb.Config.ConfigFile: string(data),
-			},
-		}
-		sCM, err := n.KubeClient.CoreV1().ConfigMaps(n.Namespace).Create(ctx, cm, metav1.CreateOptions{})
-		if err != nil {
+		if err := n.ApplyConfigMap(ctx, fmt.Sprintf("%s-config", pb.Name), map[string]string{pb.Config.ConfigFile: string(data)}); err != nil {
 			return nil, err
 		}
-		log.V(1).Infof("Server Config Map:\n%v\n", sCM)
 	}
 	return nil, nil
 }
@@ -241,6 +251,9 @@ func (n *Node) Delete(ctx context.Context) error {
 	if err := n.DeleteConfig(ctx); err != nil {
 		return err
 	}
+	if err := n.DeleteConfigMap(ctx, fmt.Sprintf("%s-config", n.Name())); err != nil {
+		return err
+	}
 	log.Infof("Deleted CEosLabDevice resources of node: %v", n.Name())
 	return nil
 }
@@ -303,6 +316,32 @@ func (n *Node) ConfigPush(ctx context.Context, r io.Reader) error {
 	return resp.Failed
 }
 
+// NetworkConfig returns the EOS config setting addrs on the routed
+// interfaces of the node. The config is merged into the running config by
+// ConfigPush.
+func (n *Node) NetworkConfig(addrs map[string]netip.Prefix) (string, error) {
+	var names []string
+	for name := range addrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		intf := name
+		if i, ok := n.Proto.GetInterfaces()[name]; ok && i.GetName() != "" {
+			intf = i.GetName()
+		}
+		fmt.Fprintf(&b, "interface %s\n   no switchport\n", intf)
+		if a := addrs[name]; a.Addr().Is4() {
+			fmt.Fprintf(&b, "   ip address %s\n", a)
+		} else {
+			fmt.Fprintf(&b, "   ipv6 enable\n   ipv6 address %s\n", a)
+		}
+		b.WriteString("!\n")
+	}
+	return b.String(), nil
+}
+
 func (n *Node) ResetCfg(ctx context.Context) error {
 	log.Infof("%s resetting config", n.Name())
 
@@ -338,22 +377,10 @@ func defaults(pb *tpb.Node) *tpb.Node {
 	pb = constraints(pb)
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
-			443: {
-				Name:   "ssl",
-				Inside: 443,
-			},
-			22: {
-				Name:   "ssh",
-				Inside: 22,
-			},
-			6030: {
-				Name:   "gnmi",
-				Inside: 6030,
-			},
-			9340: {
-				Name:   "gribi",
-				Inside: 9340,
-			},
+			443:  node.ServiceProto("ssl", 443, 0),
+			22:   node.ServiceProto("ssh", 22, 0),
+			6030: node.ServiceProto("gnmi", 6030, 0),
+			9340: node.ServiceProto("gribi", 9340, 0),
 		}
 	}
 	if pb.Labels == nil {
@@ -16,6 +16,7 @@ package arista
 import (
 	"context"
 	"fmt"
+	"net/netip"
 	"testing"
 	"time"
 
@@ -520,3 +521,33 @@ func TestResetCfg(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkConfig(t *testing.T) {
+	n := &Node{Impl: &node.Impl{Proto: &topopb.Node{
+		Name: "r1",
+		Interfaces: map[string]*topopb.Interface{
+			"eth1": {Name: "Ethernet1"},
+			"eth2": {},
+		},
+	}}}
+	got, err := n.NetworkConfig(map[string]netip.Prefix{
+		"eth1": netip.MustParsePrefix("10.0.0.0/31"),
+		"eth2": netip.MustParsePrefix("2001:db8::1/127"),
+	})
+	if err != nil {
+		t.Fatalf("NetworkConfig() unexpected err: %v", err)
+	}
+	want := `interface Ethernet1
+   no switchport
+   ip address 10.0.0.0/31
+!
+interface eth2
+   no switchport
+   ipv6 enable
+   ipv6 address 2001:db8::1/127
+!
+`
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("NetworkConfig() unexpected diff (-want +got):\n%s", s)
+	}
+}
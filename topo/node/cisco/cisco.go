@@ -81,9 +81,15 @@ var (
 	_ node.Resetter = (*Node)(nil)
 )
 
-func (n *Node) Create(ctx context.Context) error {
-	log.Infof("Creating Cisco %s node resource %s", n.Proto.Model, n.Name())
+// PodSpec returns the pod Create would create for the node without creating
+// it or the config volume it references.
+func (n *Node) PodSpec(ctx context.Context) (*corev1.Pod, error) {
+	return n.podSpec(ctx, false)
+}
 
+// podSpec returns the pod of the node. If create is set the ConfigMaps and
+// files backing the config volumes of the pod are created.
+func (n *Node) podSpec(ctx context.Context, create bool) (*corev1.Pod, error) {
 	pb := n.Proto
 	initContainerImage := pb.Config.InitImage
 	if initContainerImage == "" {
@@ -143,6 +149,9 @@ func (n *Node) Create(ctx context.Context) error {
 				},
 			}},
 			TerminationGracePeriodSeconds: pointer.Int64(0),
+			ServiceAccountName:            n.ServiceAccount,
+			SecurityContext:               n.PodSecurityContextSpec(),
+			ImagePullSecrets:              n.ImagePullSecrets,
 			NodeSelector:                  map[string]string{},
 			Affinity: &corev1.Affinity{
 				PodAntiAffinity: &corev1.PodAntiAffinity{
@@ -163,10 +172,10 @@ func (n *Node) Create(ctx context.Context) error {
 			},
 		},
 	}
-	if pb.Config.ConfigData != nil {
-		vol, err := n.CreateConfig(ctx)
+	if pb.Config.ConfigData != nil || pb.Config.ConfigMapRef != "" {
+		vol, err := n.ConfigVolume(ctx, create)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
 		vm := corev1.VolumeMount{
@@ -181,11 +190,29 @@ func (n *Node) Create(ctx context.Context) error {
 			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
 		}
 	}
-	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to create pod for %q: %w", pb.Name, err)
+	if pb.Config.InitConfig != "" {
+		vol, err := n.InitConfigVolume(ctx, create)
+		if err != nil {
+			return nil, err
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
+		vm := corev1.VolumeMount{
+			Name:      node.InitConfigVolumeName,
+			MountPath: node.InitConfigPath,
+			ReadOnly:  true,
+		}
+		for i, c := range pod.Spec.Containers {
+			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
+		}
+	}
+	return pod, nil
+}
+
+func (n *Node) Create(ctx context.Context) error {
+	log.Infof("Creating Cisco %s node resource %s", n.Proto.Model, n.Name())
+	if err := n.CreatePod(ctx); err != nil {
+		return err
 	}
-	log.V(1).Infof("Pod created:\n%+v\n", sPod)
 	log.Infof("Created Cisco %s node resource %s pod", n.Proto.Model, n.Name())
 	if err := n.CreateService(ctx); err != nil {
 		return err
@@ -194,6 +221,25 @@ func (n *Node) Create(ctx context.Context) error {
 	return nil
 }
 
+// CreatePod creates the pod of the node along with its config volumes.
+func (n *Node) CreatePod(ctx context.Context) error {
+	pod, err := n.podSpec(ctx, true)
+	if err != nil {
+		return err
+	}
+	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create pod for %q: %w", n.Name(), err)
+	}
+	log.V(1).Infof("Pod created:\n%+v\n", sPod)
+	return nil
+}
+
+// Update re-creates the pod of the node from the node proto.
+func (n *Node) Update(ctx context.Context) error {
+	return n.UpdatePod(ctx, n.CreatePod)
+}
+
 func constraints(pb *tpb.Node) *tpb.Node {
 	if pb.Constraints == nil {
 		pb.Constraints = map[string]string{}
@@ -361,26 +407,11 @@ func defaults(pb *tpb.Node) (*tpb.Node, error) {
 	pb = constraints(pb)
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
-			22: {
-				Name:   "ssh",
-				Inside: 22,
-			},
-			9339: {
-				Name:   "gnmi",
-				Inside: 57400,
-			},
-			9340: {
-				Name:   "gribi",
-				Inside: 57400,
-			},
-			9337: {
-				Name:   "gnoi",
-				Inside: 57400,
-			},
-			9559: {
-				Name:   "p4rt",
-				Inside: 57400,
-			},
+			22:   node.ServiceProto("ssh", 22, 0),
+			9339: node.ServiceProto("gnmi", 57400, 0),
+			9340: node.ServiceProto("gribi", 57400, 0),
+			9337: node.ServiceProto("gnoi", 57400, 0),
+			9559: node.ServiceProto("p4rt", 57400, 0),
 		}
 	}
 	if pb.Labels == nil {
@@ -30,6 +30,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	tpb "github.com/openconfig/kne/proto/topo"
 	scrapliopts "github.com/scrapli/scrapligo/driver/options"
@@ -731,6 +732,154 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestPodSpec(t *testing.T) {
+	ki := fake.NewSimpleClientset()
+	n, err := New(&node.Impl{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+		ServiceAccount:   "kne-sa",
+		PodSecurityContext: &corev1.PodSecurityContext{
+			FSGroup: pointer.Int64(2000),
+		},
+		KubeClient: ki,
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name:  "pod1",
+			Model: ModelXRD,
+			Config: &tpb.Config{
+				ConfigFile: "foo",
+				ConfigPath: "/",
+				ConfigData: &tpb.Config_Data{
+					Data: []byte("config file data"),
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pod, err := n.PodSpec(context.Background())
+	if err != nil {
+		t.Fatalf("PodSpec() failed: %v", err)
+	}
+	if s := cmp.Diff(&corev1.PodSecurityContext{FSGroup: pointer.Int64(2000)}, pod.Spec.SecurityContext); s != "" {
+		t.Errorf("PodSpec() unexpected pod security context diff (-want +got):\n%s", s)
+	}
+	if got, want := pod.Spec.ServiceAccountName, "kne-sa"; got != want {
+		t.Errorf("PodSpec() service account got %q, want %q", got, want)
+	}
+	if s := cmp.Diff([]corev1.LocalObjectReference{{Name: "regcred"}}, pod.Spec.ImagePullSecrets); s != "" {
+		t.Errorf("PodSpec() unexpected image pull secrets diff (-want +got):\n%s", s)
+	}
+	wantCaps := &corev1.Capabilities{Add: []corev1.Capability{"SYS_ADMIN"}}
+	if s := cmp.Diff(wantCaps, pod.Spec.Containers[0].SecurityContext.Capabilities); s != "" {
+		t.Errorf("PodSpec() unexpected capabilities diff (-want +got):\n%s", s)
+	}
+	var vols []string
+	for _, v := range pod.Spec.Volumes {
+		vols = append(vols, v.Name)
+	}
+	if s := cmp.Diff([]string{"pod1-run-mount", node.ConfigVolumeName}, vols); s != "" {
+		t.Errorf("PodSpec() unexpected volumes diff (-want +got):\n%s", s)
+	}
+	cms, err := ki.CoreV1().ConfigMaps("test").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list config maps: %v", err)
+	}
+	if len(cms.Items) != 0 {
+		t.Errorf("PodSpec() created %d config maps, want 0", len(cms.Items))
+	}
+}
+
+func TestPodSpecConfigMapRef(t *testing.T) {
+	n, err := New(&node.Impl{
+		KubeClient: fake.NewSimpleClientset(),
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name:  "pod1",
+			Model: ModelXRD,
+			Config: &tpb.Config{
+				ConfigFile:   "foo",
+				ConfigPath:   "/",
+				ConfigMapRef: "shared-config",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pod, err := n.PodSpec(context.Background())
+	if err != nil {
+		t.Fatalf("PodSpec() failed: %v", err)
+	}
+	var vol *corev1.Volume
+	for i, v := range pod.Spec.Volumes {
+		if v.Name == node.ConfigVolumeName {
+			vol = &pod.Spec.Volumes[i]
+		}
+	}
+	if vol == nil || vol.ConfigMap == nil || vol.ConfigMap.Name != "shared-config" {
+		t.Fatalf("PodSpec() got config volume %v, want ConfigMap %q", vol, "shared-config")
+	}
+	want := corev1.VolumeMount{Name: node.ConfigVolumeName, MountPath: "//foo", SubPath: "foo", ReadOnly: true}
+	var found bool
+	for _, vm := range pod.Spec.Containers[0].VolumeMounts {
+		found = found || vm == want
+	}
+	if !found {
+		t.Errorf("PodSpec() did not mount %v, got %v", want, pod.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestPodSpecInitConfig(t *testing.T) {
+	ki := fake.NewSimpleClientset()
+	nn, err := New(&node.Impl{
+		KubeClient: ki,
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name:  "pod1",
+			Model: ModelXRD,
+			Config: &tpb.Config{
+				InitConfig: "enable gnmi\n",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	n := nn.(*Node)
+	if err := n.CreatePod(context.Background()); err != nil {
+		t.Fatalf("CreatePod() failed: %v", err)
+	}
+	pod, err := ki.CoreV1().Pods("test").Get(context.Background(), "pod1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	var vol *corev1.Volume
+	for i, v := range pod.Spec.Volumes {
+		if v.Name == node.InitConfigVolumeName {
+			vol = &pod.Spec.Volumes[i]
+		}
+	}
+	if vol == nil || vol.ConfigMap == nil || vol.ConfigMap.Name != "pod1-init-config" {
+		t.Fatalf("CreatePod() got init config volume %v, want ConfigMap %q", vol, "pod1-init-config")
+	}
+	want := corev1.VolumeMount{Name: node.InitConfigVolumeName, MountPath: node.InitConfigPath, ReadOnly: true}
+	var found bool
+	for _, vm := range pod.Spec.Containers[0].VolumeMounts {
+		found = found || vm == want
+	}
+	if !found {
+		t.Errorf("CreatePod() did not mount %v, got %v", want, pod.Spec.Containers[0].VolumeMounts)
+	}
+	cm, err := ki.CoreV1().ConfigMaps("test").Get(context.Background(), "pod1-init-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CreatePod() did not create the init config: %v", err)
+	}
+	if got, want := cm.Data[node.InitConfigFile], "enable gnmi\n"; got != want {
+		t.Errorf("CreatePod() init config got %q, want %q", got, want)
+	}
+}
+
 var (
 	ki = fake.NewSimpleClientset(
 		&corev1.Pod{
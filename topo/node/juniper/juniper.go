@@ -315,9 +315,15 @@ func (n *Node) ResetCfg(ctx context.Context) error {
 	return nil
 }
 
-func (n *Node) Create(ctx context.Context) error {
-	log.Infof("Creating cPTX node resource %s model %s", n.Name(), n.Proto.Model)
+// PodSpec returns the pod Create would create for the node without creating
+// it or the config volume it references.
+func (n *Node) PodSpec(ctx context.Context) (*corev1.Pod, error) {
+	return n.podSpec(ctx, false)
+}
 
+// podSpec returns the pod of the node. If create is set the ConfigMaps and
+// files backing the config volumes of the pod are created.
+func (n *Node) podSpec(ctx context.Context, create bool) (*corev1.Pod, error) {
 	hpd := corev1.HostPathDirectory
 	pb := n.Proto
 	initContainerImage := pb.Config.InitImage
@@ -326,11 +332,15 @@ func (n *Node) Create(ctx context.Context) error {
 	}
 
 	// downward api - pass some useful values to container
+	env := map[string]string{}
+	for k, v := range pb.Config.Env {
+		env[k] = v
+	}
 	if n.isChannelized() {
-		pb.Config.Env["CPTX_CHANNELIZED"] = "1"
+		env["CPTX_CHANNELIZED"] = "1"
 	}
-	pb.Config.Env["CPTX_CPU_LIMIT"] = pb.Constraints["cpu"]
-	pb.Config.Env["CPTX_MEMORY_LIMIT"] = pb.Constraints["memory"]
+	env["CPTX_CPU_LIMIT"] = pb.Constraints["cpu"]
+	env["CPTX_MEMORY_LIMIT"] = pb.Constraints["memory"]
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: n.Name(),
@@ -354,7 +364,7 @@ func (n *Node) Create(ctx context.Context) error {
 				Image:           pb.Config.Image,
 				Command:         pb.Config.Command,
 				Args:            pb.Config.Args,
-				Env:             node.ToEnvVar(pb.Config.Env),
+				Env:             node.ToEnvVar(env),
 				Resources:       node.ToResourceRequirements(pb.Constraints),
 				ImagePullPolicy: "IfNotPresent",
 				SecurityContext: &corev1.SecurityContext{
@@ -441,6 +451,9 @@ func (n *Node) Create(ctx context.Context) error {
 				},
 			},
 			TerminationGracePeriodSeconds: pointer.Int64(0),
+			ServiceAccountName:            n.ServiceAccount,
+			SecurityContext:               n.PodSecurityContextSpec(),
+			ImagePullSecrets:              n.ImagePullSecrets,
 			NodeSelector:                  map[string]string{},
 			Affinity: &corev1.Affinity{
 				PodAntiAffinity: &corev1.PodAntiAffinity{
@@ -461,10 +474,10 @@ func (n *Node) Create(ctx context.Context) error {
 			},
 		},
 	}
-	if pb.Config.ConfigData != nil {
-		vol, err := n.CreateConfig(ctx)
+	if pb.Config.ConfigData != nil || pb.Config.ConfigMapRef != "" {
+		vol, err := n.ConfigVolume(ctx, create)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
 		vm := corev1.VolumeMount{
@@ -479,11 +492,29 @@ func (n *Node) Create(ctx context.Context) error {
 			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
 		}
 	}
-	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to create pod for %q: %w", pb.Name, err)
+	if pb.Config.InitConfig != "" {
+		vol, err := n.InitConfigVolume(ctx, create)
+		if err != nil {
+			return nil, err
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
+		vm := corev1.VolumeMount{
+			Name:      node.InitConfigVolumeName,
+			MountPath: node.InitConfigPath,
+			ReadOnly:  true,
+		}
+		for i, c := range pod.Spec.Containers {
+			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
+		}
+	}
+	return pod, nil
+}
+
+func (n *Node) Create(ctx context.Context) error {
+	log.Infof("Creating cPTX node resource %s model %s", n.Name(), n.Proto.Model)
+	if err := n.CreatePod(ctx); err != nil {
+		return err
 	}
-	log.V(1).Infof("Pod created:\n%+v\n", sPod)
 	log.Infof("Created cPTX node resource %s pod model %s", n.Name(), n.Proto.Model)
 	if err := n.CreateService(ctx); err != nil {
 		return err
@@ -492,6 +523,25 @@ func (n *Node) Create(ctx context.Context) error {
 	return nil
 }
 
+// CreatePod creates the pod of the node along with its config volumes.
+func (n *Node) CreatePod(ctx context.Context) error {
+	pod, err := n.podSpec(ctx, true)
+	if err != nil {
+		return err
+	}
+	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create pod for %q: %w", n.Name(), err)
+	}
+	log.V(1).Infof("Pod created:\n%+v\n", sPod)
+	return nil
+}
+
+// Update re-creates the pod of the node from the node proto.
+func (n *Node) Update(ctx context.Context) error {
+	return n.UpdatePod(ctx, n.CreatePod)
+}
+
 func defaults(pb *tpb.Node) *tpb.Node {
 	if pb == nil {
 		pb = &tpb.Node{
@@ -544,26 +594,11 @@ func defaults(pb *tpb.Node) *tpb.Node {
 	}
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
-			443: {
-				Name:   "ssl",
-				Inside: 443,
-			},
-			22: {
-				Name:   "ssh",
-				Inside: 22,
-			},
-			9337: {
-				Name:   "gnoi",
-				Inside: 32767,
-			},
-			9339: {
-				Name:   "gnmi",
-				Inside: 32767,
-			},
-			9340: {
-				Name:   "gribi",
-				Inside: 32767,
-			},
+			443:  node.ServiceProto("ssl", 443, 0),
+			22:   node.ServiceProto("ssh", 22, 0),
+			9337: node.ServiceProto("gnoi", 32767, 0),
+			9339: node.ServiceProto("gnmi", 32767, 0),
+			9340: node.ServiceProto("gribi", 32767, 0),
 		}
 	}
 	if pb.Config.Cert == nil {
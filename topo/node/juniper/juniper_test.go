@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
 	ktest "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
 )
 
 type fakeWatch struct {
@@ -704,3 +705,135 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestPodSpec(t *testing.T) {
+	n, err := New(&node.Impl{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "regcred"}},
+		ServiceAccount:   "kne-sa",
+		PodSecurityContext: &corev1.PodSecurityContext{
+			FSGroup: pointer.Int64(2000),
+		},
+		KubeClient: fake.NewSimpleClientset(),
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name: "pod1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pod, err := n.PodSpec(context.Background())
+	if err != nil {
+		t.Fatalf("PodSpec() failed: %v", err)
+	}
+	if s := cmp.Diff(&corev1.PodSecurityContext{FSGroup: pointer.Int64(2000)}, pod.Spec.SecurityContext); s != "" {
+		t.Errorf("PodSpec() unexpected pod security context diff (-want +got):\n%s", s)
+	}
+	if got, want := pod.Spec.ServiceAccountName, "kne-sa"; got != want {
+		t.Errorf("PodSpec() service account got %q, want %q", got, want)
+	}
+	if s := cmp.Diff([]corev1.LocalObjectReference{{Name: "regcred"}}, pod.Spec.ImagePullSecrets); s != "" {
+		t.Errorf("PodSpec() unexpected image pull secrets diff (-want +got):\n%s", s)
+	}
+	env := map[string]string{}
+	for _, e := range pod.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if got, want := env["CPTX_CPU_LIMIT"], "8"; got != want {
+		t.Errorf("PodSpec() CPTX_CPU_LIMIT got %q, want %q", got, want)
+	}
+	if _, ok := n.GetProto().GetConfig().GetEnv()["CPTX_CPU_LIMIT"]; ok {
+		t.Errorf("PodSpec() modified the env of the node proto")
+	}
+	if got, want := len(pod.Spec.Volumes), 5; got != want {
+		t.Errorf("PodSpec() got %d volumes, want %d", got, want)
+	}
+}
+
+func TestPodSpecConfigMapRef(t *testing.T) {
+	n, err := New(&node.Impl{
+		KubeClient: fake.NewSimpleClientset(),
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name: "pod1",
+			Config: &tpb.Config{
+				ConfigFile:   "foo",
+				ConfigPath:   "/",
+				ConfigMapRef: "shared-config",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	pod, err := n.PodSpec(context.Background())
+	if err != nil {
+		t.Fatalf("PodSpec() failed: %v", err)
+	}
+	var vol *corev1.Volume
+	for i, v := range pod.Spec.Volumes {
+		if v.Name == node.ConfigVolumeName {
+			vol = &pod.Spec.Volumes[i]
+		}
+	}
+	if vol == nil || vol.ConfigMap == nil || vol.ConfigMap.Name != "shared-config" {
+		t.Fatalf("PodSpec() got config volume %v, want ConfigMap %q", vol, "shared-config")
+	}
+	want := corev1.VolumeMount{Name: node.ConfigVolumeName, MountPath: "//foo", SubPath: "foo", ReadOnly: true}
+	var found bool
+	for _, vm := range pod.Spec.Containers[0].VolumeMounts {
+		found = found || vm == want
+	}
+	if !found {
+		t.Errorf("PodSpec() did not mount %v, got %v", want, pod.Spec.Containers[0].VolumeMounts)
+	}
+}
+
+func TestPodSpecInitConfig(t *testing.T) {
+	ki := fake.NewSimpleClientset()
+	nn, err := New(&node.Impl{
+		KubeClient: ki,
+		Namespace:  "test",
+		Proto: &tpb.Node{
+			Name: "pod1",
+			Config: &tpb.Config{
+				InitConfig: "enable gnmi\n",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	n := nn.(*Node)
+	if err := n.CreatePod(context.Background()); err != nil {
+		t.Fatalf("CreatePod() failed: %v", err)
+	}
+	pod, err := ki.CoreV1().Pods("test").Get(context.Background(), "pod1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	var vol *corev1.Volume
+	for i, v := range pod.Spec.Volumes {
+		if v.Name == node.InitConfigVolumeName {
+			vol = &pod.Spec.Volumes[i]
+		}
+	}
+	if vol == nil || vol.ConfigMap == nil || vol.ConfigMap.Name != "pod1-init-config" {
+		t.Fatalf("CreatePod() got init config volume %v, want ConfigMap %q", vol, "pod1-init-config")
+	}
+	want := corev1.VolumeMount{Name: node.InitConfigVolumeName, MountPath: node.InitConfigPath, ReadOnly: true}
+	var found bool
+	for _, vm := range pod.Spec.Containers[0].VolumeMounts {
+		found = found || vm == want
+	}
+	if !found {
+		t.Errorf("CreatePod() did not mount %v, got %v", want, pod.Spec.Containers[0].VolumeMounts)
+	}
+	cm, err := ki.CoreV1().ConfigMaps("test").Get(context.Background(), "pod1-init-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CreatePod() did not create the init config: %v", err)
+	}
+	if got, want := cm.Data[node.InitConfigFile], "enable gnmi\n"; got != want {
+		t.Errorf("CreatePod() init config got %q, want %q", got, want)
+	}
+}
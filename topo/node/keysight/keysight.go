@@ -7,6 +7,8 @@ import (
 
 	ixclient "github.com/open-traffic-generator/ixia-c-operator/api/clientset/v1beta1"
 	ixiatg "github.com/open-traffic-generator/ixia-c-operator/api/v1beta1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	log "k8s.io/klog/v2"
@@ -217,6 +219,24 @@ func (n *Node) Create(ctx context.Context) error {
 	return nil
 }
 
+// PodSpec returns an Unimplemented error since the pod of the node is created
+// by its controller from the custom resource rather than by KNE.
+func (n *Node) PodSpec(_ context.Context) (*corev1.Pod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// Update returns an Unimplemented error since the pod of the node would be
+// re-created by its controller rather than from the node proto.
+func (n *Node) Update(_ context.Context) error {
+	return status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// WaitForInterfaces returns an Unimplemented error since the interfaces of
+// the node are spread over the pods created by its controller.
+func (n *Node) WaitForInterfaces(_ context.Context, _ time.Duration) error {
+	return status.Errorf(codes.Unimplemented, "interfaces of node %q cannot be listed", n.Name())
+}
+
 // Pods returns the pod definitions for the node.
 func (n *Node) Pods(ctx context.Context) ([]*corev1.Pod, error) {
 	crd, err := n.getCRD(ctx)
@@ -255,6 +275,30 @@ func (n *Node) Pods(ctx context.Context) ([]*corev1.Pod, error) {
 	return pods, nil
 }
 
+// Ready returns true if all the pods of the node created by its controller
+// are ready.
+func (n *Node) Ready(ctx context.Context) (bool, error) {
+	pods, err := n.Pods(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range pods {
+		if p == nil {
+			return false, nil
+		}
+		ready := false
+		for _, cond := range p.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				ready = true
+			}
+		}
+		if !ready {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // Services returns the service definition for the node.
 func (n *Node) Services(ctx context.Context) ([]*corev1.Service, error) {
 	crd, err := n.getCRD(ctx)
@@ -325,18 +369,9 @@ func (n *Node) FixInterfaces() {
 func defaults(pb *tpb.Node) *tpb.Node {
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
-			8443: {
-				Name:   "https",
-				Inside: 8443,
-			},
-			40051: {
-				Name:   "grpc",
-				Inside: 40051,
-			},
-			50051: {
-				Name:   "gnmi",
-				Inside: 50051,
-			},
+			8443:  node.ServiceProto("https", 8443, 0),
+			40051: node.ServiceProto("grpc", 40051, 0),
+			50051: node.ServiceProto("gnmi", 50051, 0),
 		}
 	}
 	if pb.Labels == nil {
@@ -1,22 +1,36 @@
 package node
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/netip"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
+	"github.com/openconfig/gnmi/errlist"
 	tpb "github.com/openconfig/kne/proto/topo"
 	scraplinetwork "github.com/scrapli/scrapligo/driver/network"
 	scrapliopts "github.com/scrapli/scrapligo/driver/options"
 	scraplilogging "github.com/scrapli/scrapligo/logging"
 	scrapliplatform "github.com/scrapli/scrapligo/platform"
 	scrapliutil "github.com/scrapli/scrapligo/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -24,6 +38,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 	log "k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 )
@@ -32,6 +47,9 @@ type Interface interface {
 	Name() string
 	GetNamespace() string
 	GetProto() *tpb.Node
+	// Interfaces returns the resolved interfaces of the node keyed by
+	// interface name.
+	Interfaces() map[string]*tpb.Interface
 	String() string
 }
 
@@ -45,6 +63,22 @@ type Implementation interface {
 	// Status provides a custom implementation of accessing vendor node status.
 	// Requires context, Kubernetes client interface and namespace.
 	Status(context.Context) (Status, error)
+	// Ready provides a custom implementation of checking that a running
+	// node is ready to accept connections.
+	Ready(context.Context) (bool, error)
+	// WaitForInterfaces provides a custom implementation of waiting until
+	// the interfaces of a running node exist in its kernel. Nodes which
+	// cannot list their interfaces return a status.Unimplemented error.
+	WaitForInterfaces(ctx context.Context, timeout time.Duration) error
+	// PodSpec provides a custom implementation of building the pod Create
+	// would submit for the node, without submitting it.
+	PodSpec(context.Context) (*corev1.Pod, error)
+	// ConfigMaps provides a custom implementation of building the
+	// ConfigMaps Create would apply for the node, without applying them.
+	ConfigMaps() ([]*corev1.ConfigMap, error)
+	// Restart provides a custom implementation of restarting the node in
+	// place, keeping its services and meshnet links.
+	Restart(context.Context) error
 	// Delete provides a custom implementation of pod creation
 	// for a node type. Requires context, Kubernetes client interface and namespace.
 	Delete(context.Context) error
@@ -61,16 +95,76 @@ type Certer interface {
 	GenerateSelfSigned(context.Context) error
 }
 
+// CertRevoker provides an interface for revoking the certificates of a node
+// so they can be regenerated, such as when they expire in long running labs.
+type CertRevoker interface {
+	RevokeCert(context.Context) error
+}
+
+// ResourceApplier provides an interface for updating the resources of an
+// existing node, other than its pod, to match the node proto.
+type ResourceApplier interface {
+	ApplyConfig(context.Context) error
+	ApplyService(context.Context) error
+}
+
 // ConfigPusher provides an interface for performing config pushes to the node.
 type ConfigPusher interface {
 	ConfigPush(context.Context, io.Reader) error
 }
 
+// ConfigPuller provides an interface for reading the running config of the
+// node.
+type ConfigPuller interface {
+	PullConfig(ctx context.Context, w io.Writer) error
+}
+
+// Backuper provides an interface for reading the saved startup config of the
+// node, as opposed to the running config read by ConfigPuller.
+type Backuper interface {
+	BackupConfig(ctx context.Context, w io.Writer) error
+}
+
+// NetworkConfiger provides an interface for rendering the addresses of the
+// node interfaces, keyed by interface name, as config accepted by
+// ConfigPush.
+type NetworkConfiger interface {
+	NetworkConfig(addrs map[string]netip.Prefix) (string, error)
+}
+
 // Resetter provides Reset interface to nodes.
 type Resetter interface {
 	ResetCfg(ctx context.Context) error
 }
 
+// Execer provides an interface for running commands in the node.
+type Execer interface {
+	Exec(ctx context.Context, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+}
+
+// FileCopier provides an interface for copying local files into the node.
+type FileCopier interface {
+	CopyFile(ctx context.Context, srcPath, destPath string) error
+}
+
+// Scaler provides an interface for changing the number of replicas of
+// multi-instance nodes.
+type Scaler interface {
+	Scale(ctx context.Context, replicas int32) error
+}
+
+// Versioner provides an interface for querying the software version running
+// on the node, such as through gNMI.
+type Versioner interface {
+	Version(ctx context.Context) (string, error)
+}
+
+// Updater provides an interface for applying changes made to the node proto
+// to a running node, such as updating the image, environment or services.
+type Updater interface {
+	Update(ctx context.Context) error
+}
+
 // Node is the base interface for all node implementations in KNE.
 type Node interface {
 	Interface
@@ -87,6 +181,18 @@ const (
 
 	ConfigVolumeName = "startup-config-volume"
 
+	// InitConfigVolumeName is the name of the volume holding the init config
+	// of the node, which is mounted at InitConfigPath.
+	InitConfigVolumeName = "init-config-volume"
+	// InitConfigPath is the directory the init config is mounted at in the
+	// node containers, under the file name InitConfigFile.
+	InitConfigPath = "/init-config"
+	InitConfigFile = "init-config"
+
+	// limitSuffix is appended to a resource name to form the constraint
+	// key of the resource limit.
+	limitSuffix = "_limit"
+
 	OndatraRoleLabel = "ondatra-role"
 	OndatraRoleDUT   = "DUT"
 	OndatraRoleATE   = "ATE"
@@ -98,6 +204,17 @@ var (
 	mu          sync.Mutex
 	vendorTypes = map[tpb.Vendor]NewNodeFn{}
 	tempCfgDir  = "/tmp/kne"
+
+	// readyDialTimeout bounds the TCP connection attempt made by GNMIReady.
+	readyDialTimeout = 2 * time.Second
+	// readyPollInterval is the interval at which WaitForReady checks the
+	// node.
+	readyPollInterval = time.Second
+	// restartPollInterval is the interval at which Restart checks that the
+	// deleted pod is gone before recreating it.
+	restartPollInterval = time.Second
+
+	newSPDYExecutor = remotecommand.NewSPDYExecutor
 )
 
 // Vendor registers the vendor type with the topology manager.
@@ -118,25 +235,77 @@ type Impl struct {
 	Proto      *tpb.Node
 	BasePath   string
 	Kubecfg    string
+	// ServiceAccount is the k8s service account the pods of the node run
+	// as. If empty the default service account of the namespace is used.
+	ServiceAccount string
+	// PodSecurityContext is the security context of the pods of the node.
+	// The security context of the node proto takes precedence over it.
+	PodSecurityContext *corev1.PodSecurityContext
+	// ImagePullSecrets are the secrets used to pull the images of the pods
+	// of the node from private registries.
+	ImagePullSecrets []corev1.LocalObjectReference
+}
+
+// Option sets optional fields of the node implementation.
+type Option func(*Impl)
+
+// WithServiceAccount sets the k8s service account the pods of the node run as.
+func WithServiceAccount(name string) Option {
+	return func(n *Impl) {
+		n.ServiceAccount = name
+	}
+}
+
+// WithPodSecurityContext sets the security context of the pods of the node.
+func WithPodSecurityContext(sc *corev1.PodSecurityContext) Option {
+	return func(n *Impl) {
+		n.PodSecurityContext = sc
+	}
+}
+
+// WithImagePullSecrets sets the secrets used to pull the images of the pods
+// of the node.
+func WithImagePullSecrets(secrets []corev1.LocalObjectReference) Option {
+	return func(n *Impl) {
+		n.ImagePullSecrets = secrets
+	}
 }
 
 // New creates a new node for use in the k8s cluster.  Configure will push the node to
 // the cluster.
-func New(namespace string, pb *tpb.Node, kClient kubernetes.Interface, rCfg *rest.Config, bp, kubecfg string) (Node, error) {
-	return getImpl(&Impl{
+func New(namespace string, pb *tpb.Node, kClient kubernetes.Interface, rCfg *rest.Config, bp, kubecfg string, opts ...Option) (Node, error) {
+	impl := &Impl{
 		Namespace:  namespace,
 		Proto:      pb,
 		KubeClient: kClient,
 		RestConfig: rCfg,
 		BasePath:   bp,
 		Kubecfg:    kubecfg,
-	})
+	}
+	for _, o := range opts {
+		o(impl)
+	}
+	return getImpl(impl)
 }
 
 func (n *Impl) GetProto() *tpb.Node {
 	return n.Proto
 }
 
+func (n *Impl) Interfaces() map[string]*tpb.Interface {
+	return n.GetProto().GetInterfaces()
+}
+
+// InterfaceNames returns the sorted names of the interfaces of n.
+func InterfaceNames(n Interface) []string {
+	var names []string
+	for name := range n.Interfaces() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (n *Impl) GetNamespace() string {
 	return n.Namespace
 }
@@ -195,6 +364,9 @@ func ToEnvVar(kv map[string]string) []corev1.EnvVar {
 	return envVar
 }
 
+// ToResourceRequirements converts the node constraints to resource
+// requirements. The "cpu" and "memory" constraints are requests and the
+// "cpu_limit" and "memory_limit" constraints are limits.
 func ToResourceRequirements(kv map[string]string) corev1.ResourceRequirements {
 	r := corev1.ResourceRequirements{
 		Requests: map[corev1.ResourceName]resource.Quantity{},
@@ -205,9 +377,32 @@ func ToResourceRequirements(kv map[string]string) corev1.ResourceRequirements {
 	if v, ok := kv["memory"]; ok {
 		r.Requests["memory"] = resource.MustParse(v)
 	}
+	for _, k := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if v, ok := kv[string(k)+limitSuffix]; ok {
+			if r.Limits == nil {
+				r.Limits = map[corev1.ResourceName]resource.Quantity{}
+			}
+			r.Limits[k] = resource.MustParse(v)
+		}
+	}
 	return r
 }
 
+// ToConstraints converts the CPU and memory requests and limits of r to node
+// constraints understood by ToResourceRequirements.
+func ToConstraints(r corev1.ResourceRequirements) map[string]string {
+	kv := map[string]string{}
+	for _, k := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if q, ok := r.Requests[k]; ok {
+			kv[string(k)] = q.String()
+		}
+		if q, ok := r.Limits[k]; ok {
+			kv[string(k)+limitSuffix] = q.String()
+		}
+	}
+	return kv
+}
+
 // Create will create the node in the k8s cluster with all services and config
 // maps.
 func (n *Impl) Create(ctx context.Context) error {
@@ -234,10 +429,14 @@ func (n *Impl) readConfig() ([]byte, error) {
 }
 
 // CreateConfig creates a boot config for the node based on the underlying proto.
-// A volume containing the boot config is returned. If the config size is <3MB
+// A volume containing the boot config is returned. If the config references a
+// ConfigMap it is used as the volume source. Else if the config size is <3MB
 // then a ConfigMap is created as the volume source. Else a temporary file
 // is written with the boot config to serve as a HostPath volume source.
 func (n *Impl) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
+	if ref := n.Proto.Config.GetConfigMapRef(); ref != "" {
+		return configMapVolume(ConfigVolumeName, ref), nil
+	}
 	data, err := n.readConfig()
 	if err != nil {
 		return nil, err
@@ -249,19 +448,9 @@ func (n *Impl) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 		return nil, nil
 	case size < 1048576*3: // size less than 3MB, use configMap
 		name := fmt.Sprintf("%s-config", n.Proto.Name)
-		cm := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: name,
-			},
-			Data: map[string]string{
-				n.Proto.Config.ConfigFile: string(data),
-			},
-		}
-		sCM, err := n.KubeClient.CoreV1().ConfigMaps(n.Namespace).Create(ctx, cm, metav1.CreateOptions{})
-		if err != nil {
+		if err := n.ApplyConfigMap(ctx, name, map[string]string{n.Proto.Config.ConfigFile: string(data)}); err != nil {
 			return nil, err
 		}
-		log.V(1).Infof("Created config ConfigMap:\n%v\n", sCM)
 		vs = corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
 				LocalObjectReference: corev1.LocalObjectReference{
@@ -303,10 +492,182 @@ func (n *Impl) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 	}, nil
 }
 
+// ConfigMaps returns the ConfigMaps CreateConfig and CreateInitConfig would
+// apply for the node without applying them. A ConfigMap referenced by
+// config_map_ref is not owned by the node and is not returned, nor is a
+// config too large for a ConfigMap.
+func (n *Impl) ConfigMaps() ([]*corev1.ConfigMap, error) {
+	var cms []*corev1.ConfigMap
+	if n.Proto.Config.GetConfigMapRef() == "" {
+		data, err := n.readConfig()
+		if err != nil {
+			return nil, err
+		}
+		if size := len(data); size > 0 && size < 1048576*3 {
+			cms = append(cms, configMap(fmt.Sprintf("%s-config", n.Proto.Name), map[string]string{n.Proto.Config.ConfigFile: string(data)}))
+		}
+	}
+	if data := n.Proto.Config.GetInitConfig(); data != "" {
+		cms = append(cms, configMap(fmt.Sprintf("%s-init-config", n.Proto.Name), map[string]string{InitConfigFile: data}))
+	}
+	return cms, nil
+}
+
+// configMap returns the ConfigMap name holding data.
+func configMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Data: data,
+	}
+}
+
+// ApplyConfigMap creates the ConfigMap name holding data in the namespace of
+// the node, or replaces its data if it already exists.
+func (n *Impl) ApplyConfigMap(ctx context.Context, name string, data map[string]string) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Data: data,
+	}
+	c := n.KubeClient.CoreV1().ConfigMaps(n.Namespace)
+	sCM, err := c.Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		sCM, err = c.Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply ConfigMap %q: %w", name, err)
+	}
+	log.V(1).Infof("Applied ConfigMap:\n%v\n", sCM)
+	return nil
+}
+
+// DeleteConfigMap deletes the ConfigMap name from the namespace of the node.
+// A ConfigMap which does not exist is not an error.
+func (n *Impl) DeleteConfigMap(ctx context.Context, name string) error {
+	err := n.KubeClient.CoreV1().ConfigMaps(n.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to delete ConfigMap %q: %w", name, err)
+	}
+	log.V(1).Infof("Deleted config map %s", name)
+	return nil
+}
+
+// CreateInitConfig creates a ConfigMap holding the init config of the node
+// and returns a volume referencing it. If the node has no init config a nil
+// volume is returned.
+func (n *Impl) CreateInitConfig(ctx context.Context) (*corev1.Volume, error) {
+	data := n.Proto.Config.GetInitConfig()
+	if data == "" {
+		return nil, nil
+	}
+	name := fmt.Sprintf("%s-init-config", n.Proto.Name)
+	if err := n.ApplyConfigMap(ctx, name, map[string]string{InitConfigFile: data}); err != nil {
+		return nil, err
+	}
+	return configMapVolume(InitConfigVolumeName, name), nil
+}
+
+// configVolume returns the volume CreateConfig would return without creating
+// the ConfigMap or file holding the config. The path of a HostPath volume is
+// the pattern of the temporary file.
+func (n *Impl) configVolume() (*corev1.Volume, error) {
+	if ref := n.Proto.Config.GetConfigMapRef(); ref != "" {
+		return configMapVolume(ConfigVolumeName, ref), nil
+	}
+	data, err := n.readConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch size := len(data); {
+	case size == 0:
+		return nil, nil
+	case size < 1048576*3:
+		return configMapVolume(ConfigVolumeName, fmt.Sprintf("%s-config", n.Proto.Name)), nil
+	default:
+		return &corev1.Volume{
+			Name: ConfigVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: filepath.Join(tempCfgDir, fmt.Sprintf("kne-%s-config-*.cfg", n.Proto.Name)),
+				},
+			},
+		}, nil
+	}
+}
+
+// ConfigVolume returns the volume holding the config of the node. If create
+// is set the ConfigMap or file backing the volume is created as by
+// CreateConfig, otherwise nothing is created.
+func (n *Impl) ConfigVolume(ctx context.Context, create bool) (*corev1.Volume, error) {
+	if create {
+		return n.CreateConfig(ctx)
+	}
+	return n.configVolume()
+}
+
+// InitConfigVolume returns the volume holding the init config of the node,
+// which is mounted at InitConfigPath. If create is set the ConfigMap backing
+// the volume is created as by CreateInitConfig, otherwise nothing is created.
+// If the node has no init config a nil volume is returned.
+func (n *Impl) InitConfigVolume(ctx context.Context, create bool) (*corev1.Volume, error) {
+	if n.Proto.Config.GetInitConfig() == "" {
+		return nil, nil
+	}
+	if create {
+		return n.CreateInitConfig(ctx)
+	}
+	return configMapVolume(InitConfigVolumeName, fmt.Sprintf("%s-init-config", n.Proto.Name)), nil
+}
+
+// configMapVolume returns the volume name backed by the ConfigMap cm.
+func configMapVolume(name, cm string) *corev1.Volume {
+	return &corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: cm,
+				},
+			},
+		},
+	}
+}
+
 // CreatePod creates a Pod for the Node based on the underlying proto.
 func (n *Impl) CreatePod(ctx context.Context) error {
+	log.Infof("Creating Pod:\n %+v", n.Proto)
+	pod, err := n.podSpec(ctx, true)
+	if err != nil {
+		return err
+	}
+	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	log.V(1).Infof("Pod created:\n%+v\n", sPod)
+	return nil
+}
+
+// PodSpec returns the pod CreatePod would create for the node without
+// creating it or the config volumes it references.
+func (n *Impl) PodSpec(ctx context.Context) (*corev1.Pod, error) {
+	return n.podSpec(ctx, false)
+}
+
+// podSpec returns the pod of the node. If create is set the ConfigMaps and
+// files backing the config volumes of the pod are created.
+func (n *Impl) podSpec(ctx context.Context, create bool) (*corev1.Pod, error) {
 	pb := n.Proto
-	log.Infof("Creating Pod:\n %+v", pb)
 	initContainerImage := pb.Config.InitImage
 	if initContainerImage == "" {
 		initContainerImage = DefaultInitContainerImage
@@ -342,6 +703,9 @@ func (n *Impl) CreatePod(ctx context.Context) error {
 				},
 			}},
 			TerminationGracePeriodSeconds: pointer.Int64(0),
+			ServiceAccountName:            n.ServiceAccount,
+			SecurityContext:               n.PodSecurityContextSpec(),
+			ImagePullSecrets:              n.ImagePullSecrets,
 			NodeSelector:                  map[string]string{},
 			Affinity: &corev1.Affinity{
 				PodAntiAffinity: &corev1.PodAntiAffinity{
@@ -362,10 +726,15 @@ func (n *Impl) CreatePod(ctx context.Context) error {
 			},
 		},
 	}
-	if pb.Config.ConfigData != nil {
-		vol, err := n.CreateConfig(ctx)
+	for k, v := range pb.Labels {
+		if _, ok := pod.ObjectMeta.Labels[k]; !ok {
+			pod.ObjectMeta.Labels[k] = v
+		}
+	}
+	if pb.Config.ConfigData != nil || pb.Config.ConfigMapRef != "" {
+		vol, err := n.ConfigVolume(ctx, create)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
 		vm := corev1.VolumeMount{
@@ -380,62 +749,172 @@ func (n *Impl) CreatePod(ctx context.Context) error {
 			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
 		}
 	}
-	sPod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Create(ctx, pod, metav1.CreateOptions{})
-	if err != nil {
-		return err
+	if pb.Config.InitConfig != "" {
+		vol, err := n.InitConfigVolume(ctx, create)
+		if err != nil {
+			return nil, err
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, *vol)
+		vm := corev1.VolumeMount{
+			Name:      InitConfigVolumeName,
+			MountPath: InitConfigPath,
+			ReadOnly:  true,
+		}
+		for i, c := range pod.Spec.Containers {
+			pod.Spec.Containers[i].VolumeMounts = append(c.VolumeMounts, vm)
+		}
 	}
-	log.V(1).Infof("Pod created:\n%+v\n", sPod)
-	return nil
+	return pod, nil
 }
 
-// CreateService creates services for the node based on the underlying proto.
-func (n *Impl) CreateService(ctx context.Context) error {
-	var servicePorts []corev1.ServicePort
-	if len(n.Proto.Services) == 0 {
-		log.Info("no services found")
-		return nil
+// PodSecurityContextSpec returns the pod security context of the node with
+// the fields set in the node proto overriding n.PodSecurityContext, or nil if
+// neither sets one.
+func (n *Impl) PodSecurityContextSpec() *corev1.PodSecurityContext {
+	var sc *corev1.PodSecurityContext
+	if n.PodSecurityContext != nil {
+		sc = n.PodSecurityContext.DeepCopy()
 	}
-	for k, v := range n.Proto.Services {
-		name := v.Name
-		if name == "" {
-			name = fmt.Sprintf("port-%d", k)
+	psc := n.Proto.GetConfig().GetSecurityContext()
+	if psc == nil {
+		return sc
+	}
+	if sc == nil {
+		sc = &corev1.PodSecurityContext{}
+	}
+	if v := psc.GetRunAsUser(); v != 0 {
+		sc.RunAsUser = pointer.Int64(v)
+	}
+	if v := psc.GetRunAsGroup(); v != 0 {
+		sc.RunAsGroup = pointer.Int64(v)
+	}
+	if v := psc.GetFsGroup(); v != 0 {
+		sc.FSGroup = pointer.Int64(v)
+	}
+	if psc.GetRunAsNonRoot() {
+		sc.RunAsNonRoot = pointer.Bool(true)
+	}
+	return sc
+}
+
+// ServiceProto returns the node service named name forwarding to the inside
+// port of the node. If nodePort is zero the cluster assigns the node port.
+func ServiceProto(name string, inside, nodePort uint32) *tpb.Service {
+	return &tpb.Service{
+		Name:     name,
+		Inside:   inside,
+		NodePort: nodePort,
+	}
+}
+
+// DefaultServices returns copies of the services of the node sorted by
+// service port, with Outside set to the service port. Node implementations
+// may override it to expose other services.
+func (n *Impl) DefaultServices() []*tpb.Service {
+	var ports []uint32
+	for k := range n.Proto.GetServices() {
+		ports = append(ports, k)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	var services []*tpb.Service
+	for _, k := range ports {
+		s := proto.Clone(n.Proto.Services[k]).(*tpb.Service)
+		if s.Outside == 0 {
+			s.Outside = k
 		}
+		services = append(services, s)
+	}
+	return services
+}
+
+// ValidateNodePorts returns an error for each node port requested by the
+// services of more than one of the nodes, as node ports are shared by the
+// whole cluster.
+func ValidateNodePorts(nodes []*tpb.Node) error {
+	used := map[uint32]string{}
+	var errs errlist.List
+	for _, n := range nodes {
+		var ports []uint32
+		for k := range n.GetServices() {
+			ports = append(ports, k)
+		}
+		sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+		for _, k := range ports {
+			np := n.GetServices()[k].GetNodePort()
+			if np == 0 {
+				continue
+			}
+			if other, ok := used[np]; ok {
+				errs.Add(fmt.Errorf("node port %d requested by both node %q and node %q", np, other, n.GetName()))
+				continue
+			}
+			used[np] = n.GetName()
+		}
+	}
+	return errs.Err()
+}
+
+// DefaultService returns the LoadBalancer service exposing the services of
+// the node proto pb as returned by DefaultServices, with the ports sorted by
+// port number.
+func DefaultService(pb *tpb.Node) *corev1.Service {
+	for _, v := range pb.Services {
 		if v.Outside != 0 {
 			log.Warningf("Outside should not be set by user. The key is used as the target external port")
 		}
+	}
+	var servicePorts []corev1.ServicePort
+	for _, v := range (&Impl{Proto: pb}).DefaultServices() {
+		name := v.Name
+		if name == "" {
+			name = fmt.Sprintf("port-%d", v.Outside)
+		}
 		sp := corev1.ServicePort{
 			Name:       name,
 			Protocol:   "TCP",
-			Port:       int32(k),
+			Port:       int32(v.Outside),
 			TargetPort: intstr.FromInt(int(v.Inside)),
 		}
 		if v.NodePort != 0 {
 			sp.NodePort = int32(v.NodePort)
 		}
-		if v.Outside != 0 {
-			sp.Port = int32(v.Outside)
-		}
 		servicePorts = append(servicePorts, sp)
 	}
+	sort.Slice(servicePorts, func(i, j int) bool { return servicePorts[i].Port < servicePorts[j].Port })
 	s := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: fmt.Sprintf("service-%s", n.Name()),
+			Name: fmt.Sprintf("service-%s", pb.Name),
 			Labels: map[string]string{
-				"pod": n.Name(),
+				"pod": pb.Name,
 			},
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: servicePorts,
 			Selector: map[string]string{
-				"app": n.Name(),
+				"app": pb.Name,
 			},
 			Type: "LoadBalancer",
 		},
 	}
+	for k, v := range pb.Labels {
+		if _, ok := s.ObjectMeta.Labels[k]; !ok {
+			s.ObjectMeta.Labels[k] = v
+		}
+	}
+	return s
+}
+
+// CreateService creates services for the node based on the underlying proto.
+func (n *Impl) CreateService(ctx context.Context) error {
+	if len(n.Proto.Services) == 0 {
+		log.Info("no services found")
+		return nil
+	}
+	s := DefaultService(n.Proto)
 	sS, err := n.KubeClient.CoreV1().Services(n.Namespace).Create(ctx, s, metav1.CreateOptions{})
 	if err != nil {
 		return err
@@ -444,6 +923,49 @@ func (n *Impl) CreateService(ctx context.Context) error {
 	return nil
 }
 
+// ApplyService creates the Service of the node, or updates the ports and
+// selector of the Service if it already exists. Nodes without services have
+// no Service.
+func (n *Impl) ApplyService(ctx context.Context) error {
+	if len(n.Proto.Services) == 0 {
+		return nil
+	}
+	s := DefaultService(n.Proto)
+	c := n.KubeClient.CoreV1().Services(n.Namespace)
+	sS, err := c.Create(ctx, s, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		var cur *corev1.Service
+		if cur, err = c.Get(ctx, s.Name, metav1.GetOptions{}); err == nil {
+			cur.Labels = s.Labels
+			cur.Spec.Type = s.Spec.Type
+			cur.Spec.Ports = s.Spec.Ports
+			cur.Spec.Selector = s.Spec.Selector
+			sS, err = c.Update(ctx, cur, metav1.UpdateOptions{})
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to apply Service %q: %w", s.Name, err)
+	}
+	log.V(1).Infof("Applied Service:\n%v\n", sS)
+	return nil
+}
+
+// ApplyConfig creates the ConfigMaps holding the config and init config of
+// the node, or replaces their data if they already exist. A running node
+// only reads the new config when it restarts.
+func (n *Impl) ApplyConfig(ctx context.Context) error {
+	cms, err := n.ConfigMaps()
+	if err != nil {
+		return err
+	}
+	for _, cm := range cms {
+		if err := n.ApplyConfigMap(ctx, cm.Name, cm.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Delete remove the node from the cluster.
 func (n *Impl) Delete(ctx context.Context) error {
 	if err := n.DeleteService(ctx); err != nil {
@@ -456,15 +978,17 @@ func (n *Impl) Delete(ctx context.Context) error {
 	return nil
 }
 
-// DeleteConfig removes the node configmap from the cluster if it exists. If
-// a config file hostPath was used for the boot config volume, clean the file up instead.
+// DeleteConfig removes the node configmaps, including the init config, from
+// the cluster if they exist. If a config file hostPath was used for the boot
+// config volume, clean the file up instead. A ConfigMap referenced by
+// config_map_ref is not owned by the node and is not deleted.
 func (n *Impl) DeleteConfig(ctx context.Context) error {
 	pod, err := n.KubeClient.CoreV1().Pods(n.Namespace).Get(ctx, n.Name(), metav1.GetOptions{})
 	if err != nil {
 		return err
 	}
 	for _, vol := range pod.Spec.Volumes {
-		if vol.Name != ConfigVolumeName {
+		if vol.Name != ConfigVolumeName && vol.Name != InitConfigVolumeName {
 			continue
 		}
 		switch vs := vol.VolumeSource; {
@@ -476,10 +1000,13 @@ func (n *Impl) DeleteConfig(ctx context.Context) error {
 			log.V(1).Infof("Deleted config file %s", path)
 		case vs.ConfigMap != nil:
 			name := vs.ConfigMap.LocalObjectReference.Name
-			if err := n.KubeClient.CoreV1().ConfigMaps(n.Namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			if name != fmt.Sprintf("%s-config", n.Name()) && name != fmt.Sprintf("%s-init-config", n.Name()) {
+				log.V(1).Infof("Skipping deletion of config map %s not owned by node %s", name, n.Name())
+				continue
+			}
+			if err := n.DeleteConfigMap(ctx, name); err != nil {
 				return err
 			}
-			log.V(1).Infof("Deleted config map %s", name)
 		}
 	}
 	return nil
@@ -504,27 +1031,107 @@ func (n *Impl) DeleteResource(ctx context.Context) error {
 	return n.KubeClient.CoreV1().Pods(n.Namespace).Delete(ctx, n.Name(), metav1.DeleteOptions{})
 }
 
+// Restart deletes the pod of the node. A pod owned by a controller, such as
+// a Deployment or StatefulSet, is recreated by the controller. Otherwise the
+// pod is recreated from its spec once the deleted pod is gone.
+func (n *Impl) Restart(ctx context.Context) error {
+	c := n.KubeClient.CoreV1().Pods(n.Namespace)
+	pod, err := c.Get(ctx, n.Name(), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get pod %q: %w", n.Name(), err)
+	}
+	log.Infof("Restarting pod %q", n.Name())
+	if err := c.Delete(ctx, n.Name(), metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete pod %q: %w", n.Name(), err)
+	}
+	if metav1.GetControllerOf(pod) != nil {
+		return nil
+	}
+	if err := n.waitPodDeleted(ctx); err != nil {
+		return err
+	}
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        pod.Name,
+			Namespace:   pod.Namespace,
+			Labels:      pod.Labels,
+			Annotations: pod.Annotations,
+		},
+		Spec: pod.Spec,
+	}
+	// Let the scheduler place the pod again.
+	p.Spec.NodeName = ""
+	if _, err := c.Create(ctx, p, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to recreate pod %q: %w", n.Name(), err)
+	}
+	return nil
+}
+
+// waitPodDeleted waits until the pod of the node no longer exists.
+func (n *Impl) waitPodDeleted(ctx context.Context) error {
+	for {
+		_, err := n.KubeClient.CoreV1().Pods(n.Namespace).Get(ctx, n.Name(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get pod %q: %w", n.Name(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(restartPollInterval):
+		}
+	}
+}
+
+// Update applies changes made to the node proto by re-creating the pod of
+// the node from the proto.
+func (n *Impl) Update(ctx context.Context) error {
+	return n.UpdatePod(ctx, n.CreatePod)
+}
+
+// UpdatePod deletes the pod and config of the node, waits for the pod to be
+// removed and calls createPod to re-create it from the node proto. The
+// service of the node is then updated to match the proto. It is used by
+// vendors which build their own pods to implement Update.
+func (n *Impl) UpdatePod(ctx context.Context, createPod func(context.Context) error) error {
+	log.Infof("Updating pod %q", n.Name())
+	if err := n.DeleteResource(ctx); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod %q: %w", n.Name(), err)
+	}
+	if err := n.waitPodDeleted(ctx); err != nil {
+		return err
+	}
+	if err := createPod(ctx); err != nil {
+		return fmt.Errorf("failed to re-create pod %q: %w", n.Name(), err)
+	}
+	return n.ApplyService(ctx)
+}
+
 // Exec will make a connection via spdy transport to the Pod and execute the provided command.
-// It will wire up stdin, stdout, stderr to provided io channels.
+// It will wire up stdin, stdout, stderr to provided io channels. A TTY is only
+// allocated if stdin is provided so that stderr is kept separate from stdout.
 func (n *Impl) Exec(ctx context.Context, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	return n.exec(ctx, cmd, stdin, stdout, stderr, stdin != nil)
+}
+
+func (n *Impl) exec(ctx context.Context, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool) error {
 	req := n.KubeClient.CoreV1().RESTClient().Post().Resource("pods").Name(n.Name()).Namespace(n.Namespace).SubResource("exec")
 	opts := &corev1.PodExecOptions{
 		Command:   cmd,
 		Container: n.Name(),
-		Stdin:     true,
+		Stdin:     stdin != nil,
 		Stdout:    true,
-		Stderr:    true,
-		TTY:       true,
-	}
-	if stdin == nil {
-		opts.Stdin = false
+		Stderr:    !tty,
+		TTY:       tty,
 	}
 	req.VersionedParams(
 		opts,
 		scheme.ParameterCodec,
 	)
 
-	exec, err := remotecommand.NewSPDYExecutor(n.RestConfig, "POST", req.URL())
+	exec, err := newSPDYExecutor(n.RestConfig, "POST", req.URL())
 	if err != nil {
 		return err
 	}
@@ -533,9 +1140,56 @@ func (n *Impl) Exec(ctx context.Context, cmd []string, stdin io.Reader, stdout i
 		Stdin:  stdin,
 		Stdout: stdout,
 		Stderr: stderr,
+		Tty:    tty,
 	})
 }
 
+// CopyFile copies the local file srcPath to destPath in the node container
+// by streaming it as a tar archive, creating the destination directory if it
+// does not exist.
+func (n *Impl) CopyFile(ctx context.Context, srcPath, destPath string) error {
+	f, err := os.Open(srcPath)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("source %q is a directory", srcPath)
+	}
+	dir := path.Dir(destPath)
+	var stderr bytes.Buffer
+	if err := n.exec(ctx, []string{"mkdir", "-p", dir}, nil, io.Discard, &stderr, false); err != nil {
+		return fmt.Errorf("failed to create directory %q on node %s: %w: %s", dir, n.Name(), err, stderr.String())
+	}
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		tw := tar.NewWriter(pw)
+		err := tw.WriteHeader(&tar.Header{
+			Name:    path.Base(destPath),
+			Mode:    int64(fi.Mode().Perm()),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		})
+		if err == nil {
+			_, err = io.Copy(tw, f)
+		}
+		if err == nil {
+			err = tw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	stderr.Reset()
+	if err := n.exec(ctx, []string{"tar", "-xmf", "-", "-C", dir}, pr, io.Discard, &stderr, false); err != nil {
+		return fmt.Errorf("failed to copy %q to %s:%s: %w: %s", srcPath, n.Name(), destPath, err, stderr.String())
+	}
+	return nil
+}
+
 // Status returns the current node state.
 func (n *Impl) Status(ctx context.Context) (Status, error) {
 	p, err := n.Pods(ctx)
@@ -558,6 +1212,210 @@ func (n *Impl) Status(ctx context.Context) (Status, error) {
 	return StatusPending, nil
 }
 
+// Ready returns true if the readiness probes of all the containers of the
+// node pod pass.
+func (n *Impl) Ready(ctx context.Context) (bool, error) {
+	p, err := n.Pods(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(p) != 1 {
+		return false, fmt.Errorf("expected exactly one pod for node %s", n.Name())
+	}
+	for _, cond := range p[0].Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue, nil
+		}
+	}
+	return false, nil
+}
+
+// GNMIReady returns true if the node accepts connections on its gNMI service.
+// A TCP connection is made to the gNMI port of the external IP of the node
+// service, so the load balancer must be reachable from the caller. Nodes
+// without a gNMI service or whose service has no external IP are ready.
+// Node implementations may override Ready with it.
+func (n *Impl) GNMIReady(ctx context.Context) (bool, error) {
+	var port uint32
+	for k, s := range n.Proto.GetServices() {
+		if s.GetName() != "gnmi" {
+			continue
+		}
+		port = k
+		if s.GetOutside() != 0 {
+			port = s.GetOutside()
+		}
+	}
+	if port == 0 {
+		return true, nil
+	}
+	services, err := n.Services(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range services {
+		for _, ing := range s.Status.LoadBalancer.Ingress {
+			if ing.IP == "" {
+				continue
+			}
+			addr := net.JoinHostPort(ing.IP, strconv.Itoa(int(port)))
+			d := net.Dialer{Timeout: readyDialTimeout}
+			conn, err := d.DialContext(ctx, "tcp", addr)
+			if err != nil {
+				log.V(1).Infof("Node %s not ready, failed to connect to %s: %v", n.Name(), addr, err)
+				return false, nil
+			}
+			conn.Close()
+			return true, nil
+		}
+	}
+	return true, nil
+}
+
+// WaitForReady waits until the node is running and Ready returns true, or
+// until timeout elapses. Node implementations overriding Ready should call
+// the package level WaitForReady so their Ready is used.
+func (n *Impl) WaitForReady(ctx context.Context, timeout time.Duration) error {
+	return WaitForReady(ctx, n, timeout)
+}
+
+// WaitForReady waits until n is running and its Ready returns true. If
+// timeout is non-zero an error is returned once it elapses. An error is also
+// returned if the node fails.
+func WaitForReady(ctx context.Context, n Node, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		ok, err := isReady(ctx, n)
+		if err != nil {
+			return fmt.Errorf("node %s not ready: %w", n.Name(), err)
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("node %s not ready: %w", n.Name(), ctx.Err())
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// isReady returns true if n is running and ready. Pods which do not exist
+// yet are treated as not ready.
+func isReady(ctx context.Context, n Node) (bool, error) {
+	s, err := n.Status(ctx)
+	switch {
+	case apierrors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	case s == StatusFailed:
+		return false, fmt.Errorf("status %s", s)
+	case s != StatusRunning:
+		return false, nil
+	}
+	return n.Ready(ctx)
+}
+
+// BackupConfig writes the saved startup config of n to w. Nodes which do not
+// fulfill Backuper fall back to writing their running config if they fulfill
+// ConfigPuller. If n fulfills neither a status.Unimplemented error is
+// returned.
+func BackupConfig(ctx context.Context, n Node, w io.Writer) error {
+	switch b := n.(type) {
+	case Backuper:
+		return b.BackupConfig(ctx, w)
+	case ConfigPuller:
+		return b.PullConfig(ctx, w)
+	}
+	return status.Errorf(codes.Unimplemented, "node %q does not implement Backuper or ConfigPuller interface", n.Name())
+}
+
+// WaitForInterfaces waits until all interfaces of the node are listed by
+// "ip link show" in the node container, or until timeout elapses if it is
+// non-zero. Nodes without interfaces return immediately. Not every container
+// can run "ip link show", so if it is not found or fails the wait is skipped.
+// Other errors, such as failing to reach the pod, are returned.
+func (n *Impl) WaitForInterfaces(ctx context.Context, timeout time.Duration) error {
+	var want []string
+	for _, name := range InterfaceNames(n) {
+		intf := n.Interfaces()[name]
+		if intf.GetIntName() != "" {
+			name = intf.GetIntName()
+		}
+		want = append(want, name)
+	}
+	if len(want) == 0 {
+		return nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for {
+		var stdout, stderr bytes.Buffer
+		err := n.Exec(ctx, []string{"ip", "link", "show"}, nil, &stdout, &stderr)
+		switch {
+		case err == nil:
+		case commandUnavailable(err):
+			log.Warningf("Node %s: cannot list interfaces, skipping wait for interfaces: %v: %s", n.Name(), err, stderr.String())
+			return nil
+		case ctx.Err() != nil:
+			return fmt.Errorf("failed to list interfaces of node %s: %w", n.Name(), ctx.Err())
+		default:
+			return fmt.Errorf("failed to list interfaces of node %s: %w", n.Name(), err)
+		}
+		missing := missingInterfaces(stdout.String(), want)
+		if len(missing) == 0 {
+			return nil
+		}
+		log.V(1).Infof("Node %s: waiting for interfaces %v", n.Name(), missing)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("interfaces %v of node %s not found: %w", missing, n.Name(), ctx.Err())
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// commandUnavailable returns true if err shows that a command run in a node
+// container was not found or exited with a non-zero status.
+func commandUnavailable(err error) bool {
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "executable file not found") || strings.Contains(msg, "command not found")
+}
+
+// missingInterfaces returns the interfaces in want which are not listed in
+// out, the output of "ip link show".
+func missingInterfaces(out string, want []string) []string {
+	found := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		// Interface lines look like "2: eth1@if5: <BROADCAST,...> mtu 1500".
+		f := strings.SplitN(line, ": ", 3)
+		if len(f) < 3 || strings.HasPrefix(line, " ") {
+			continue
+		}
+		name, _, _ := strings.Cut(f[1], "@")
+		found[name] = true
+	}
+	var missing []string
+	for _, name := range want {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // Name returns the name of the node.
 func (n *Impl) Name() string {
 	return n.Proto.Name
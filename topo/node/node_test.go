@@ -1,19 +1,32 @@
 package node
 
 import (
+	"archive/tar"
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/h-fam/errdiff"
+	"google.golang.org/protobuf/testing/protocmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	kfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/pointer"
 
 	topopb "github.com/openconfig/kne/proto/topo"
 )
@@ -210,6 +223,220 @@ func TestCreateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateInitConfig(t *testing.T) {
+	ctx := context.Background()
+	n := &Impl{
+		Namespace:  "test",
+		KubeClient: kfake.NewSimpleClientset(),
+		RestConfig: &rest.Config{},
+		Proto: &topopb.Node{
+			Name: "dev1",
+			Config: &topopb.Config{
+				Image:      "image",
+				InitConfig: "enable gnmi\n",
+			},
+		},
+	}
+	if err := n.CreatePod(ctx); err != nil {
+		t.Fatalf("CreatePod() unexpected err: %v", err)
+	}
+	gotCM, err := n.KubeClient.CoreV1().ConfigMaps("test").Get(ctx, "dev1-init-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CreatePod() did not create the init config configmap: %v", err)
+	}
+	if s := cmp.Diff(map[string]string{InitConfigFile: "enable gnmi\n"}, gotCM.Data); s != "" {
+		t.Errorf("CreatePod() created init config configmap unexpected diff (-want +got):\n%s", s)
+	}
+	pod, err := n.KubeClient.CoreV1().Pods("test").Get(ctx, "dev1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CreatePod() did not create the pod: %v", err)
+	}
+	wantVols := []corev1.Volume{{
+		Name: InitConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "dev1-init-config"},
+			},
+		},
+	}}
+	if s := cmp.Diff(wantVols, pod.Spec.Volumes); s != "" {
+		t.Errorf("CreatePod() unexpected volumes (-want +got):\n%s", s)
+	}
+	wantMounts := []corev1.VolumeMount{{Name: InitConfigVolumeName, MountPath: InitConfigPath, ReadOnly: true}}
+	if s := cmp.Diff(wantMounts, pod.Spec.Containers[0].VolumeMounts); s != "" {
+		t.Errorf("CreatePod() unexpected volume mounts (-want +got):\n%s", s)
+	}
+	if err := n.DeleteConfig(ctx); err != nil {
+		t.Fatalf("DeleteConfig() unexpected err: %v", err)
+	}
+	if _, err := n.KubeClient.CoreV1().ConfigMaps("test").Get(ctx, "dev1-init-config", metav1.GetOptions{}); err == nil {
+		t.Errorf("DeleteConfig() did not delete the init config configmap")
+	}
+
+	n.Proto = &topopb.Node{Name: "dev2", Config: &topopb.Config{}}
+	vol, err := n.CreateInitConfig(ctx)
+	if err != nil || vol != nil {
+		t.Errorf("CreateInitConfig() without init config got %v, %v, want nil, nil", vol, err)
+	}
+}
+
+func TestPodSpec(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset()
+	n := &Impl{
+		Namespace:  "test",
+		KubeClient: kf,
+		RestConfig: &rest.Config{},
+		Proto: &topopb.Node{
+			Name:   "dev1",
+			Labels: map[string]string{"role": "dut"},
+			Config: &topopb.Config{
+				Image:      "image",
+				ConfigPath: "/etc",
+				ConfigFile: "startup.cfg",
+				ConfigData: &topopb.Config_Data{Data: []byte("hostname dev1\n")},
+				InitConfig: "enable gnmi\n",
+			},
+		},
+	}
+	got, err := n.PodSpec(ctx)
+	if err != nil {
+		t.Fatalf("PodSpec() unexpected err: %v", err)
+	}
+	if a := kf.Actions(); len(a) != 0 {
+		t.Errorf("PodSpec() made unexpected requests: %v", a)
+	}
+	if err := n.CreatePod(ctx); err != nil {
+		t.Fatalf("CreatePod() unexpected err: %v", err)
+	}
+	want, err := kf.CoreV1().Pods("test").Get(ctx, "dev1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("CreatePod() did not create pod: %v", err)
+	}
+	if s := cmp.Diff(want.Spec, got.Spec); s != "" {
+		t.Errorf("PodSpec() unexpected spec diff from created pod (-want +got):\n%s", s)
+	}
+	if s := cmp.Diff(want.Labels, got.Labels); s != "" {
+		t.Errorf("PodSpec() unexpected labels diff from created pod (-want +got):\n%s", s)
+	}
+}
+
+func TestPodSecurityContext(t *testing.T) {
+	tests := []struct {
+		desc string
+		topo *corev1.PodSecurityContext
+		node *topopb.PodSecurityContext
+		want *corev1.PodSecurityContext
+	}{{
+		desc: "unset",
+	}, {
+		desc: "topology",
+		topo: &corev1.PodSecurityContext{RunAsUser: pointer.Int64(1000), RunAsNonRoot: pointer.Bool(true)},
+		want: &corev1.PodSecurityContext{RunAsUser: pointer.Int64(1000), RunAsNonRoot: pointer.Bool(true)},
+	}, {
+		desc: "node",
+		node: &topopb.PodSecurityContext{RunAsUser: 2000, FsGroup: 3000},
+		want: &corev1.PodSecurityContext{RunAsUser: pointer.Int64(2000), FSGroup: pointer.Int64(3000)},
+	}, {
+		desc: "node overrides topology",
+		topo: &corev1.PodSecurityContext{RunAsUser: pointer.Int64(1000), RunAsGroup: pointer.Int64(1000), RunAsNonRoot: pointer.Bool(true)},
+		node: &topopb.PodSecurityContext{RunAsUser: 2000, FsGroup: 3000},
+		want: &corev1.PodSecurityContext{RunAsUser: pointer.Int64(2000), RunAsGroup: pointer.Int64(1000), FSGroup: pointer.Int64(3000), RunAsNonRoot: pointer.Bool(true)},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			n := &Impl{
+				Namespace:  "test",
+				KubeClient: kfake.NewSimpleClientset(),
+				Proto: &topopb.Node{
+					Name:   "dev1",
+					Config: &topopb.Config{Image: "image", SecurityContext: tt.node},
+				},
+			}
+			WithPodSecurityContext(tt.topo)(n)
+			got, err := n.PodSpec(context.Background())
+			if err != nil {
+				t.Fatalf("PodSpec() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.want, got.Spec.SecurityContext); s != "" {
+				t.Errorf("PodSpec() unexpected security context (-want +got):\n%s", s)
+			}
+			if tt.topo != nil && got.Spec.SecurityContext == tt.topo {
+				t.Errorf("PodSpec() security context aliases the topology security context")
+			}
+		})
+	}
+}
+
+func TestServiceProto(t *testing.T) {
+	got := ServiceProto("gnmi", 9339, 30339)
+	want := &topopb.Service{Name: "gnmi", Inside: 9339, NodePort: 30339}
+	if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+		t.Errorf("ServiceProto() unexpected diff (-want +got):\n%s", s)
+	}
+}
+
+func TestDefaultServices(t *testing.T) {
+	n := &Impl{Proto: &topopb.Node{
+		Name: "r1",
+		Services: map[uint32]*topopb.Service{
+			9339: ServiceProto("gnmi", 57400, 0),
+			22:   ServiceProto("ssh", 22, 0),
+			9340: {Name: "gribi", Inside: 57401, Outside: 9999},
+		},
+	}}
+	want := []*topopb.Service{
+		{Name: "ssh", Inside: 22, Outside: 22},
+		{Name: "gnmi", Inside: 57400, Outside: 9339},
+		{Name: "gribi", Inside: 57401, Outside: 9999},
+	}
+	if s := cmp.Diff(want, n.DefaultServices(), protocmp.Transform()); s != "" {
+		t.Errorf("DefaultServices() unexpected diff (-want +got):\n%s", s)
+	}
+	if n.Proto.Services[22].Outside != 0 {
+		t.Errorf("DefaultServices() modified the node services")
+	}
+}
+
+func TestValidateNodePorts(t *testing.T) {
+	tests := []struct {
+		desc    string
+		nodes   []*topopb.Node
+		wantErr string
+	}{{
+		desc: "auto assigned",
+		nodes: []*topopb.Node{
+			{Name: "r1", Services: map[uint32]*topopb.Service{22: ServiceProto("ssh", 22, 0)}},
+			{Name: "r2", Services: map[uint32]*topopb.Service{22: ServiceProto("ssh", 22, 0)}},
+		},
+	}, {
+		desc: "distinct",
+		nodes: []*topopb.Node{
+			{Name: "r1", Services: map[uint32]*topopb.Service{22: ServiceProto("ssh", 22, 30022)}},
+			{Name: "r2", Services: map[uint32]*topopb.Service{22: ServiceProto("ssh", 22, 30023)}},
+		},
+	}, {
+		desc: "collision",
+		nodes: []*topopb.Node{
+			{Name: "r1", Services: map[uint32]*topopb.Service{22: ServiceProto("ssh", 22, 30022)}},
+			{Name: "r2", Services: map[uint32]*topopb.Service{
+				22:   ServiceProto("ssh", 22, 30023),
+				9339: ServiceProto("gnmi", 9339, 30022),
+			}},
+		},
+		wantErr: `node port 30022 requested by both node "r1" and node "r2"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := ValidateNodePorts(tt.nodes)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("ValidateNodePorts() unexpected err: %s", s)
+			}
+		})
+	}
+}
+
 func TestService(t *testing.T) {
 	tests := []struct {
 		desc           string
@@ -357,3 +584,674 @@ func TestService(t *testing.T) {
 		})
 	}
 }
+
+func TestInterfaces(t *testing.T) {
+	tests := []struct {
+		desc      string
+		pb        *topopb.Node
+		wantNames []string
+	}{{
+		desc: "no interfaces",
+		pb:   &topopb.Node{Name: "r1"},
+	}, {
+		desc: "interfaces",
+		pb: &topopb.Node{
+			Name: "r1",
+			Interfaces: map[string]*topopb.Interface{
+				"eth2": {Name: "Ethernet2", IntName: "eth2", PeerName: "r3", PeerIntName: "eth1", Uid: 1},
+				"eth1": {Name: "Ethernet1", IntName: "eth1", PeerName: "r2", PeerIntName: "eth1", Uid: 0},
+			},
+		},
+		wantNames: []string{"eth1", "eth2"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			n := &Impl{Proto: tt.pb}
+			if s := cmp.Diff(tt.pb.Interfaces, n.Interfaces(), protocmp.Transform()); s != "" {
+				t.Errorf("Interfaces() unexpected diff (-want +got):\n%s", s)
+			}
+			if s := cmp.Diff(tt.wantNames, InterfaceNames(n)); s != "" {
+				t.Errorf("InterfaceNames() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestReady(t *testing.T) {
+	pod := func(conds ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", Labels: map[string]string{"app": "r1"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: conds},
+		}
+	}
+	tests := []struct {
+		desc    string
+		pod     *corev1.Pod
+		want    bool
+		wantErr string
+	}{{
+		desc: "ready",
+		pod:  pod(corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionTrue}),
+		want: true,
+	}, {
+		desc: "not ready",
+		pod:  pod(corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionFalse}),
+	}, {
+		desc: "no ready condition",
+		pod:  pod(corev1.PodCondition{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}),
+	}, {
+		desc:    "no pod",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset()
+			if tt.pod != nil {
+				kf = kfake.NewSimpleClientset(tt.pod)
+			}
+			n := &Impl{
+				Namespace:  "test",
+				KubeClient: kf,
+				Proto:      &topopb.Node{Name: "r1"},
+			}
+			got, err := n.Ready(context.Background())
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Ready() unexpected err: %s", s)
+			}
+			if got != tt.want {
+				t.Errorf("Ready() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGNMIReady(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer lis.Close()
+	openPort := uint32(lis.Addr().(*net.TCPAddr).Port)
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closedPort := uint32(closed.Addr().(*net.TCPAddr).Port)
+	closed.Close()
+
+	service := func(ips ...string) *corev1.Service {
+		s := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"}}
+		for _, ip := range ips {
+			s.Status.LoadBalancer.Ingress = append(s.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{IP: ip})
+		}
+		return s
+	}
+	tests := []struct {
+		desc     string
+		services map[uint32]*topopb.Service
+		svc      *corev1.Service
+		want     bool
+		wantErr  string
+	}{{
+		desc: "no gnmi service",
+		services: map[uint32]*topopb.Service{
+			22: {Name: "ssh", Inside: 22},
+		},
+		want: true,
+	}, {
+		desc: "gnmi accepting connections",
+		services: map[uint32]*topopb.Service{
+			openPort: {Name: "gnmi", Inside: 9339},
+		},
+		svc:  service("127.0.0.1"),
+		want: true,
+	}, {
+		desc: "gnmi outside port accepting connections",
+		services: map[uint32]*topopb.Service{
+			9339: {Name: "gnmi", Inside: 9339, Outside: openPort},
+		},
+		svc:  service("127.0.0.1"),
+		want: true,
+	}, {
+		desc: "gnmi refusing connections",
+		services: map[uint32]*topopb.Service{
+			closedPort: {Name: "gnmi", Inside: 9339},
+		},
+		svc: service("127.0.0.1"),
+	}, {
+		desc: "no external ip",
+		services: map[uint32]*topopb.Service{
+			closedPort: {Name: "gnmi", Inside: 9339},
+		},
+		svc:  service(),
+		want: true,
+	}, {
+		desc: "service not found",
+		services: map[uint32]*topopb.Service{
+			openPort: {Name: "gnmi", Inside: 9339},
+		},
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset()
+			if tt.svc != nil {
+				kf = kfake.NewSimpleClientset(tt.svc)
+			}
+			n := &Impl{
+				Namespace:  "test",
+				KubeClient: kf,
+				Proto:      &topopb.Node{Name: "r1", Services: tt.services},
+			}
+			got, err := n.GNMIReady(context.Background())
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("GNMIReady() unexpected err: %s", s)
+			}
+			if got != tt.want {
+				t.Errorf("GNMIReady() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	origInterval := readyPollInterval
+	defer func() {
+		readyPollInterval = origInterval
+	}()
+	readyPollInterval = time.Millisecond
+	pod := func(phase corev1.PodPhase, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	tests := []struct {
+		desc    string
+		pod     *corev1.Pod
+		wantErr string
+	}{{
+		desc: "ready",
+		pod:  pod(corev1.PodRunning, corev1.ConditionTrue),
+	}, {
+		desc:    "timeout pending",
+		pod:     pod(corev1.PodPending, corev1.ConditionFalse),
+		wantErr: "deadline exceeded",
+	}, {
+		desc:    "timeout no pod",
+		wantErr: "deadline exceeded",
+	}, {
+		desc:    "failed",
+		pod:     pod(corev1.PodFailed, corev1.ConditionFalse),
+		wantErr: "status FAILED",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset()
+			if tt.pod != nil {
+				kf = kfake.NewSimpleClientset(tt.pod)
+			}
+			n := &Impl{
+				Namespace:  "test",
+				KubeClient: kf,
+				Proto:      &topopb.Node{Name: "r1"},
+			}
+			err := n.WaitForReady(context.Background(), 20*time.Millisecond)
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Errorf("WaitForReady() unexpected err: %s", s)
+			}
+		})
+	}
+}
+
+type backupNode struct {
+	*Impl
+}
+
+func (b *backupNode) BackupConfig(_ context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, "startup")
+	return err
+}
+
+type pullNode struct {
+	*Impl
+}
+
+func (p *pullNode) PullConfig(_ context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, "running")
+	return err
+}
+
+func TestBackupConfig(t *testing.T) {
+	impl := &Impl{Proto: &topopb.Node{Name: "r1"}}
+	tests := []struct {
+		desc    string
+		node    Node
+		want    string
+		wantErr string
+	}{{
+		desc: "backuper",
+		node: &backupNode{Impl: impl},
+		want: "startup",
+	}, {
+		desc: "config puller fallback",
+		node: &pullNode{Impl: impl},
+		want: "running",
+	}, {
+		desc:    "unimplemented",
+		node:    impl,
+		wantErr: `node "r1" does not implement Backuper or ConfigPuller interface`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var b strings.Builder
+			err := BackupConfig(context.Background(), tt.node, &b)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("BackupConfig() unexpected error: %s", s)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("BackupConfig() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigMap(t *testing.T) {
+	ctx := context.Background()
+	n := &Impl{Namespace: "test", KubeClient: kfake.NewSimpleClientset(), Proto: &topopb.Node{Name: "r1"}}
+	get := func() map[string]string {
+		t.Helper()
+		cm, err := n.KubeClient.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get ConfigMap: %v", err)
+		}
+		return cm.Data
+	}
+	for _, data := range []map[string]string{
+		{"startup.cfg": "hostname r1"},
+		{"startup.cfg": "hostname r1-updated"},
+	} {
+		if err := n.ApplyConfigMap(ctx, "r1-config", data); err != nil {
+			t.Fatalf("ApplyConfigMap() unexpected err: %v", err)
+		}
+		if s := cmp.Diff(data, get()); s != "" {
+			t.Errorf("ApplyConfigMap() unexpected data (-want +got):\n%s", s)
+		}
+	}
+	if err := n.DeleteConfigMap(ctx, "r1-config"); err != nil {
+		t.Fatalf("DeleteConfigMap() unexpected err: %v", err)
+	}
+	if _, err := n.KubeClient.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{}); err == nil {
+		t.Errorf("DeleteConfigMap() did not delete ConfigMap")
+	}
+	if err := n.DeleteConfigMap(ctx, "r1-config"); err != nil {
+		t.Errorf("DeleteConfigMap() of missing ConfigMap unexpected err: %v", err)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	ctx := context.Background()
+	isController := true
+	tests := []struct {
+		desc       string
+		pod        *corev1.Pod
+		wantErr    string
+		wantVerbs  []string
+		wantExists bool
+	}{{
+		desc: "recreated",
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", UID: "1", Labels: map[string]string{"app": "r1"}},
+			Spec:       corev1.PodSpec{NodeName: "kind-worker", Containers: []corev1.Container{{Name: "r1"}}},
+		},
+		wantVerbs:  []string{"get", "delete", "get", "create"},
+		wantExists: true,
+	}, {
+		desc: "owned by controller",
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "StatefulSet",
+				Name:       "r1",
+				Controller: &isController,
+			}}},
+		},
+		wantVerbs: []string{"get", "delete"},
+	}, {
+		desc:    "missing pod",
+		wantErr: "failed to get pod",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset()
+			if tt.pod != nil {
+				if _, err := kf.CoreV1().Pods("test").Create(ctx, tt.pod, metav1.CreateOptions{}); err != nil {
+					t.Fatalf("failed to create pod: %v", err)
+				}
+			}
+			kf.ClearActions()
+			n := &Impl{Namespace: "test", KubeClient: kf, Proto: &topopb.Node{Name: "r1"}}
+			err := n.Restart(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Restart() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			var verbs []string
+			for _, a := range kf.Actions() {
+				verbs = append(verbs, a.GetVerb())
+			}
+			if s := cmp.Diff(tt.wantVerbs, verbs); s != "" {
+				t.Errorf("Restart() unexpected actions (-want +got):\n%s", s)
+			}
+			p, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+			if !tt.wantExists {
+				if err == nil {
+					t.Errorf("Restart() recreated pod owned by controller")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Restart() did not recreate pod: %v", err)
+			}
+			if p.UID != "" || p.Spec.NodeName != "" {
+				t.Errorf("Restart() recreated pod with uid %q on node %q, want cleared", p.UID, p.Spec.NodeName)
+			}
+			if s := cmp.Diff(tt.pod.Labels, p.Labels); s != "" {
+				t.Errorf("Restart() recreated pod labels unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	n := &Impl{
+		Namespace:  "test",
+		KubeClient: kfake.NewSimpleClientset(),
+		RestConfig: &rest.Config{},
+		Proto: &topopb.Node{
+			Name:     "r1",
+			Config:   &topopb.Config{Image: "foo:1", Args: []string{"-a"}},
+			Services: map[uint32]*topopb.Service{22: {Name: "ssh", Inside: 22}},
+		},
+	}
+	if err := n.Create(ctx); err != nil {
+		t.Fatalf("Create() unexpected err: %v", err)
+	}
+	n.Proto.Config.Image = "foo:2"
+	n.Proto.Config.Args = []string{"-b"}
+	n.Proto.Services[443] = &topopb.Service{Name: "ssl", Inside: 443}
+	if err := n.Update(ctx); err != nil {
+		t.Fatalf("Update() unexpected err: %v", err)
+	}
+	pod, err := n.KubeClient.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Update() did not re-create pod: %v", err)
+	}
+	if got := pod.Spec.Containers[0].Image; got != "foo:2" {
+		t.Errorf("Update() got image %q, want %q", got, "foo:2")
+	}
+	if s := cmp.Diff([]string{"-b"}, pod.Spec.Containers[0].Args); s != "" {
+		t.Errorf("Update() unexpected args diff (-want +got):\n%s", s)
+	}
+	svc, err := n.KubeClient.CoreV1().Services("test").Get(ctx, "service-r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Update() did not keep service: %v", err)
+	}
+	var ports []int32
+	for _, p := range svc.Spec.Ports {
+		ports = append(ports, p.Port)
+	}
+	if s := cmp.Diff([]int32{22, 443}, ports); s != "" {
+		t.Errorf("Update() unexpected service ports diff (-want +got):\n%s", s)
+	}
+}
+
+type fakeExecutor struct {
+	cmd   []string
+	err   error
+	files map[string]string
+}
+
+func (f *fakeExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return f.StreamWithContext(context.Background(), opts)
+}
+
+func (f *fakeExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	if f.err != nil {
+		return f.err
+	}
+	if opts.Stdin == nil {
+		return nil
+	}
+	tr := tar.NewReader(opts.Stdin)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		f.files[path.Join(f.cmd[len(f.cmd)-1], hdr.Name)] = fmt.Sprintf("%o:%s", hdr.Mode, b)
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "license.key")
+	if err := os.WriteFile(src, []byte("secret"), 0600); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+	kClient, err := kubernetes.NewForConfig(&rest.Config{Host: "localhost"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	tests := []struct {
+		desc      string
+		src       string
+		dest      string
+		execErr   error
+		wantCmds  [][]string
+		wantFiles map[string]string
+		wantErr   string
+	}{{
+		desc:      "success",
+		src:       src,
+		dest:      "/etc/licenses/license.key",
+		wantCmds:  [][]string{{"mkdir", "-p", "/etc/licenses"}, {"tar", "-xmf", "-", "-C", "/etc/licenses"}},
+		wantFiles: map[string]string{"/etc/licenses/license.key": "600:secret"},
+	}, {
+		desc:      "rename",
+		src:       src,
+		dest:      "/license.txt",
+		wantCmds:  [][]string{{"mkdir", "-p", "/"}, {"tar", "-xmf", "-", "-C", "/"}},
+		wantFiles: map[string]string{"/license.txt": "600:secret"},
+	}, {
+		desc:    "source does not exist",
+		src:     filepath.Join(dir, "dne"),
+		dest:    "/etc/licenses/license.key",
+		wantErr: "failed to read source file",
+	}, {
+		desc:    "source is a directory",
+		src:     dir,
+		dest:    "/etc/licenses/license.key",
+		wantErr: "is a directory",
+	}, {
+		desc:     "exec failure",
+		src:      src,
+		dest:     "/etc/licenses/license.key",
+		execErr:  fmt.Errorf("permission denied"),
+		wantCmds: [][]string{{"mkdir", "-p", "/etc/licenses"}},
+		wantErr:  "failed to create directory",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotCmds [][]string
+			gotFiles := map[string]string{}
+			origNewSPDYExecutor := newSPDYExecutor
+			newSPDYExecutor = func(_ *rest.Config, _ string, u *url.URL) (remotecommand.Executor, error) {
+				cmd := u.Query()["command"]
+				gotCmds = append(gotCmds, cmd)
+				return &fakeExecutor{cmd: cmd, err: tt.execErr, files: gotFiles}, nil
+			}
+			defer func() {
+				newSPDYExecutor = origNewSPDYExecutor
+			}()
+			n := &Impl{
+				Namespace:  "test",
+				Proto:      &topopb.Node{Name: "r1"},
+				KubeClient: kClient,
+				RestConfig: &rest.Config{},
+			}
+			err := n.CopyFile(context.Background(), tt.src, tt.dest)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("CopyFile() unexpected error: %s", s)
+			}
+			if s := cmp.Diff(tt.wantCmds, gotCmds); s != "" {
+				t.Errorf("CopyFile() unexpected commands (-want +got):\n%s", s)
+			}
+			if s := cmp.Diff(tt.wantFiles, gotFiles, cmpopts.EquateEmpty()); s != "" {
+				t.Errorf("CopyFile() unexpected files (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+type linkExecutor struct {
+	outputs []string
+	calls   *int
+	err     error
+}
+
+func (l *linkExecutor) Stream(opts remotecommand.StreamOptions) error {
+	return l.StreamWithContext(context.Background(), opts)
+}
+
+func (l *linkExecutor) StreamWithContext(_ context.Context, opts remotecommand.StreamOptions) error {
+	i := *l.calls
+	if i >= len(l.outputs) {
+		i = len(l.outputs) - 1
+	}
+	*l.calls++
+	if l.err != nil {
+		return l.err
+	}
+	_, err := io.WriteString(opts.Stdout, l.outputs[i])
+	return err
+}
+
+func TestWaitForInterfaces(t *testing.T) {
+	origInterval := readyPollInterval
+	defer func() {
+		readyPollInterval = origInterval
+	}()
+	readyPollInterval = time.Millisecond
+	kClient, err := kubernetes.NewForConfig(&rest.Config{Host: "localhost"})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	const (
+		loOnly = "1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN mode DEFAULT group default qlen 1000\n" +
+			"    link/loopback 00:00:00:00:00:00 brd 00:00:00:00:00:00\n"
+		eth1 = "2: eth1@if5: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default\n" +
+			"    link/ether 02:42:ac:11:00:02 brd ff:ff:ff:ff:ff:ff link-netnsid 0\n"
+		eth2 = "3: Ethernet2@if6: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default\n" +
+			"    link/ether 02:42:ac:11:00:03 brd ff:ff:ff:ff:ff:ff link-netnsid 0\n"
+	)
+	tests := []struct {
+		desc      string
+		ints      map[string]*topopb.Interface
+		outputs   []string
+		execErr   error
+		wantCalls int
+		wantErr   string
+	}{{
+		desc: "no interfaces",
+	}, {
+		desc: "present",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+			"eth2": {IntName: "Ethernet2"},
+		},
+		outputs:   []string{loOnly + eth1 + eth2},
+		wantCalls: 1,
+	}, {
+		desc: "appear later",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+			"eth2": {IntName: "Ethernet2"},
+		},
+		outputs:   []string{loOnly, loOnly + eth1, loOnly + eth1 + eth2},
+		wantCalls: 3,
+	}, {
+		desc: "timeout",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+			"eth3": {},
+		},
+		outputs: []string{loOnly + eth1},
+		wantErr: "interfaces [eth3] of node r1 not found",
+	}, {
+		desc: "cannot list interfaces",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+		},
+		outputs:   []string{""},
+		execErr:   fmt.Errorf("executable file not found: ip"),
+		wantCalls: 1,
+	}, {
+		desc: "command fails",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+		},
+		outputs:   []string{""},
+		execErr:   utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 1"), Code: 1},
+		wantCalls: 1,
+	}, {
+		desc: "exec error",
+		ints: map[string]*topopb.Interface{
+			"eth1": {},
+		},
+		outputs: []string{""},
+		execErr: fmt.Errorf("unable to upgrade connection: Unauthorized"),
+		wantErr: "failed to list interfaces of node r1: unable to upgrade connection: Unauthorized",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			calls := 0
+			origNewSPDYExecutor := newSPDYExecutor
+			newSPDYExecutor = func(_ *rest.Config, _ string, u *url.URL) (remotecommand.Executor, error) {
+				if got, want := u.Query()["command"], []string{"ip", "link", "show"}; !cmp.Equal(got, want) {
+					t.Errorf("WaitForInterfaces() ran %v, want %v", got, want)
+				}
+				return &linkExecutor{outputs: tt.outputs, calls: &calls, err: tt.execErr}, nil
+			}
+			defer func() {
+				newSPDYExecutor = origNewSPDYExecutor
+			}()
+			n := &Impl{
+				Namespace:  "test",
+				Proto:      &topopb.Node{Name: "r1", Interfaces: tt.ints},
+				KubeClient: kClient,
+				RestConfig: &rest.Config{},
+			}
+			err := n.WaitForInterfaces(context.Background(), 50*time.Millisecond)
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("WaitForInterfaces() unexpected err: %s", s)
+			}
+			if tt.wantErr == "" && calls != tt.wantCalls {
+				t.Errorf("WaitForInterfaces() listed interfaces %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
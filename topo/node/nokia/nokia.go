@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	tpb "github.com/openconfig/kne/proto/topo"
 	"github.com/openconfig/kne/topo/node"
@@ -17,6 +18,8 @@ import (
 	scrapliutil "github.com/scrapli/scrapligo/util"
 	srlinuxv1 "github.com/srl-labs/srl-controller/api/v1"
 	"github.com/srl-labs/srlinux-scrapli"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
@@ -271,6 +274,24 @@ func (n *Node) Create(ctx context.Context) error {
 	return err
 }
 
+// PodSpec returns an Unimplemented error since the pod of the node is created
+// by its controller from the custom resource rather than by KNE.
+func (n *Node) PodSpec(_ context.Context) (*corev1.Pod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// Update returns an Unimplemented error since the pod of the node would be
+// re-created by its controller rather than from the node proto.
+func (n *Node) Update(_ context.Context) error {
+	return status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+}
+
+// WaitForInterfaces returns an Unimplemented error since the container of
+// the pod created by the controller of the node is not named after the node.
+func (n *Node) WaitForInterfaces(_ context.Context, _ time.Duration) error {
+	return status.Errorf(codes.Unimplemented, "interfaces of node %q cannot be listed", n.Name())
+}
+
 func (n *Node) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 	pb := n.Proto
 	var data []byte
@@ -285,19 +306,9 @@ func (n *Node) CreateConfig(ctx context.Context) (*corev1.Volume, error) {
 		data = v.Data
 	}
 	if data != nil {
-		cm := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("%s-config", pb.Name),
-			},
-			Data: map[string]string{
-				pb.Config.ConfigFile: string(data),
-			},
-		}
-		sCM, err := n.KubeClient.CoreV1().ConfigMaps(n.Namespace).Create(ctx, cm, metav1.CreateOptions{})
-		if err != nil {
+		if err := n.ApplyConfigMap(ctx, fmt.Sprintf("%s-config", pb.Name), map[string]string{pb.Config.ConfigFile: string(data)}); err != nil {
 			return nil, err
 		}
-		log.V(1).Infof("Server Config Map:\n%v\n", sCM)
 	}
 	return nil, nil
 }
@@ -317,6 +328,9 @@ func (n *Node) Delete(ctx context.Context) error {
 	if err := n.DeleteConfig(ctx); err != nil {
 		return err
 	}
+	if err := n.DeleteConfigMap(ctx, fmt.Sprintf("%s-config", n.Name())); err != nil {
+		return err
+	}
 	log.Infof("Deleted Srlinux node resource %s", n.Name())
 	return nil
 }
@@ -327,30 +341,12 @@ func defaults(pb *tpb.Node) *tpb.Node {
 	}
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
-			443: {
-				Name:   "ssl",
-				Inside: 443,
-			},
-			22: {
-				Name:   "ssh",
-				Inside: 22,
-			},
-			9337: {
-				Name:   "gnoi",
-				Inside: 57400,
-			},
-			9339: {
-				Name:   "gnmi",
-				Inside: 57400,
-			},
-			9340: {
-				Name:   "gribi",
-				Inside: 57401,
-			},
-			9559: {
-				Name:   "p4rt",
-				Inside: 9559,
-			},
+			443:  node.ServiceProto("ssl", 443, 0),
+			22:   node.ServiceProto("ssh", 22, 0),
+			9337: node.ServiceProto("gnoi", 57400, 0),
+			9339: node.ServiceProto("gnmi", 57400, 0),
+			9340: node.ServiceProto("gribi", 57401, 0),
+			9559: node.ServiceProto("p4rt", 9559, 0),
 		}
 	}
 	if pb.Labels == nil {
@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	tpb "github.com/openconfig/kne/proto/topo"
 	"github.com/openconfig/kne/topo/node"
@@ -28,6 +29,7 @@ import (
 	lemmingv1 "github.com/openconfig/lemming/operator/api/lemming/v1alpha1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
 	log "k8s.io/klog/v2"
@@ -95,6 +97,44 @@ func (n *Node) Create(ctx context.Context) error {
 	}
 }
 
+// PodSpec returns the pod Create would create for magna nodes. The pods of
+// lemming nodes are created by the lemming controller so an Unimplemented
+// error is returned for them.
+func (n *Node) PodSpec(ctx context.Context) (*corev1.Pod, error) {
+	switch n.Impl.Proto.Model {
+	case modelLemming:
+		return nil, status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+	case modelMagna:
+		return n.Impl.PodSpec(ctx)
+	default:
+		return nil, fmt.Errorf("cannot build the pod of an unknown model")
+	}
+}
+
+// Update re-creates the pod of magna nodes from the node proto. The pods of
+// lemming nodes are created by the lemming controller so an Unimplemented
+// error is returned for them.
+func (n *Node) Update(ctx context.Context) error {
+	switch n.Impl.Proto.Model {
+	case modelLemming:
+		return status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", n.Name())
+	case modelMagna:
+		return n.Impl.Update(ctx)
+	default:
+		return fmt.Errorf("cannot update a node of an unknown model")
+	}
+}
+
+// WaitForInterfaces waits for the interfaces of magna nodes. An Unimplemented
+// error is returned for lemming nodes since the container of the pod created
+// by the lemming controller is not named after the node.
+func (n *Node) WaitForInterfaces(ctx context.Context, timeout time.Duration) error {
+	if n.Impl.Proto.Model == modelLemming {
+		return status.Errorf(codes.Unimplemented, "interfaces of node %q cannot be listed", n.Name())
+	}
+	return n.Impl.WaitForInterfaces(ctx, timeout)
+}
+
 // lemmingCreate implements the Create function for the lemming model devices.
 func (n *Node) lemmingCreate(ctx context.Context) error {
 	nodeSpec := n.GetProto()
@@ -266,22 +306,10 @@ func lemmingDefaults(pb *tpb.Node) *tpb.Node {
 	if pb.Services == nil {
 		pb.Services = map[uint32]*tpb.Service{
 			// https://www.iana.org/assignments/service-names-port-numbers/service-names-port-numbers.xhtml?search=gnmi
-			9339: {
-				Name:   "gnmi",
-				Inside: 9339,
-			},
-			9340: {
-				Name:   "gribi",
-				Inside: 9340,
-			},
-			9341: {
-				Name:   "gnsi",
-				Inside: 9339,
-			},
-			9342: {
-				Name:   "gnoi",
-				Inside: 9339,
-			},
+			9339: node.ServiceProto("gnmi", 9339, 0),
+			9340: node.ServiceProto("gribi", 9340, 0),
+			9341: node.ServiceProto("gnsi", 9339, 0),
+			9342: node.ServiceProto("gnoi", 9339, 0),
 		}
 	}
 	return pb
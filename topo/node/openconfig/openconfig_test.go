@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/h-fam/errdiff"
@@ -239,6 +240,15 @@ func TestGenerateSelfSigned(t *testing.T) {
 	}
 }
 
+func TestLemmingWaitForInterfaces(t *testing.T) {
+	n := &Node{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1", Model: modelLemming}}}
+	err := n.WaitForInterfaces(context.Background(), time.Second)
+	want := codes.Unimplemented
+	if s, ok := status.FromError(err); !ok || s.Code() != want {
+		t.Fatalf("WaitForInterfaces() unexpected error get %v, want %v", s, want)
+	}
+}
+
 func TestLemmingDelete(t *testing.T) {
 	tests := []struct {
 		desc        string
@@ -0,0 +1,123 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// podMetricsGVR is the resource of the pod metrics served by the metrics
+// server.
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// NodeMetrics is the resource usage of the pods of a node.
+type NodeMetrics struct {
+	CPUMillicores int64
+	MemoryMiB     int64
+}
+
+// MetricsUnavailableError is returned by Metrics when the metrics API is not
+// served by the cluster, such as when the metrics server is not installed.
+type MetricsUnavailableError struct {
+	Err error
+}
+
+func (e *MetricsUnavailableError) Error() string {
+	return fmt.Sprintf("metrics API unavailable: %v", e.Err)
+}
+
+func (e *MetricsUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Metrics returns the CPU and memory usage of each node in the topology
+// keyed by node name, summed over the containers of all pods of the node.
+// Nodes without metrics, such as pods which just started, report no usage.
+func (m *Manager) Metrics(ctx context.Context) (map[string]*NodeMetrics, error) {
+	list, err := m.mClient.Resource(podMetricsGVR).Namespace(m.namespace()).List(ctx, metav1.ListOptions{})
+	switch {
+	case apierrors.IsNotFound(err), apierrors.IsServiceUnavailable(err), meta.IsNoMatchError(err):
+		return nil, &MetricsUnavailableError{Err: err}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+	pods := map[string]*NodeMetrics{}
+	for _, item := range list.Items {
+		pm, err := podUsage(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse metrics of pod %q: %w", item.GetName(), err)
+		}
+		pods[item.GetName()] = pm
+	}
+	metrics := map[string]*NodeMetrics{}
+	for name, n := range m.nodes {
+		nm := &NodeMetrics{}
+		podList, err := nodePods(ctx, n)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pods of node %q: %w", name, err)
+		}
+		for _, p := range podList {
+			if pm, ok := pods[p.Name]; ok {
+				nm.CPUMillicores += pm.CPUMillicores
+				nm.MemoryMiB += pm.MemoryMiB
+			}
+		}
+		metrics[name] = nm
+	}
+	return metrics, nil
+}
+
+// podUsage returns the usage summed over the containers of the pod metrics
+// object u.
+func podUsage(u unstructured.Unstructured) (*NodeMetrics, error) {
+	containers, _, err := unstructured.NestedSlice(u.Object, "containers")
+	if err != nil {
+		return nil, err
+	}
+	var cpu, mem int64
+	for _, c := range containers {
+		cm, ok := c.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected container metrics %T", c)
+		}
+		usage, _, err := unstructured.NestedStringMap(cm, "usage")
+		if err != nil {
+			return nil, err
+		}
+		if v, ok := usage["cpu"]; ok {
+			q, err := resource.ParseQuantity(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cpu usage %q: %w", v, err)
+			}
+			cpu += q.MilliValue()
+		}
+		if v, ok := usage["memory"]; ok {
+			q, err := resource.ParseQuantity(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid memory usage %q: %w", v, err)
+			}
+			mem += q.Value()
+		}
+	}
+	return &NodeMetrics{CPUMillicores: cpu, MemoryMiB: mem / (1 << 20)}, nil
+}
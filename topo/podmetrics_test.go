@@ -0,0 +1,129 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dfake "k8s.io/client-go/dynamic/fake"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	ktest "k8s.io/client-go/testing"
+)
+
+func podMetrics(name string, usage ...map[string]interface{}) *unstructured.Unstructured {
+	var containers []interface{}
+	for i, u := range usage {
+		containers = append(containers, map[string]interface{}{
+			"name":  name + string(rune('a'+i)),
+			"usage": u,
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "test",
+		},
+		"containers": containers,
+	}}
+}
+
+func TestMetrics(t *testing.T) {
+	kf := kfake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "test"}},
+	)
+	nodes := map[string]node.Node{}
+	for _, name := range []string{"r1", "r2", "r3"} {
+		nodes[name] = &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: name}}}
+	}
+	tests := []struct {
+		desc        string
+		objs        []runtime.Object
+		reactErr    error
+		want        map[string]*NodeMetrics
+		wantErr     string
+		unavailable bool
+	}{{
+		desc: "all pods",
+		objs: []runtime.Object{
+			podMetrics("r1", map[string]interface{}{"cpu": "250m", "memory": "512Mi"}, map[string]interface{}{"cpu": "50m", "memory": "64Mi"}),
+			podMetrics("r2", map[string]interface{}{"cpu": "1", "memory": "1Gi"}),
+			podMetrics("other", map[string]interface{}{"cpu": "2", "memory": "2Gi"}),
+		},
+		want: map[string]*NodeMetrics{
+			"r1": {CPUMillicores: 300, MemoryMiB: 576},
+			"r2": {CPUMillicores: 1000, MemoryMiB: 1024},
+			"r3": {},
+		},
+	}, {
+		desc: "invalid usage",
+		objs: []runtime.Object{
+			podMetrics("r1", map[string]interface{}{"cpu": "lots"}),
+		},
+		wantErr: `failed to parse metrics of pod "r1"`,
+	}, {
+		desc:        "metrics server unavailable",
+		reactErr:    apierrors.NewServiceUnavailable("the server is currently unable to handle the request"),
+		wantErr:     "metrics API unavailable",
+		unavailable: true,
+	}, {
+		desc:        "metrics API not found",
+		reactErr:    apierrors.NewNotFound(podMetricsGVR.GroupResource(), ""),
+		wantErr:     "metrics API unavailable",
+		unavailable: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			mc := dfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList"})
+			for _, o := range tt.objs {
+				if err := mc.Tracker().Create(podMetricsGVR, o, "test"); err != nil {
+					t.Fatalf("failed to add pod metrics: %v", err)
+				}
+			}
+			if tt.reactErr != nil {
+				mc.PrependReactor("list", "pods", func(ktest.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.reactErr
+				})
+			}
+			m := &Manager{topo: &tpb.Topology{Name: "test"}, kClient: kf, mClient: mc, nodes: nodes}
+			got, err := m.Metrics(context.Background())
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Metrics() unexpected err: %s", s)
+			}
+			var mErr *MetricsUnavailableError
+			if errors.As(err, &mErr) != tt.unavailable {
+				t.Errorf("Metrics() got err %v, want MetricsUnavailableError: %v", err, tt.unavailable)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("Metrics() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
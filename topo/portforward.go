@@ -0,0 +1,100 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	log "k8s.io/klog/v2"
+)
+
+// Stubbed out for testing.
+var newPortForwardDialer = func(cfg *rest.Config, method string, u *url.URL) (httpstream.Dialer, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return spdy.NewDialer(upgrader, &http.Client{Transport: transport}, method, u), nil
+}
+
+// PortForward forwards localPort on localhost to remotePort of the pod of the
+// provided node. PortForward returns once the forwarder is listening. The
+// forwarder runs until the returned cancel function is called or ctx is
+// done. For nodes with multiple pods the first pod is used.
+func (m *Manager) PortForward(ctx context.Context, nodeName string, localPort, remotePort int) (cancel func(), err error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	pods, err := n.Pods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("node %q has no pods", nodeName)
+	}
+	p := pods[0]
+	u := m.kClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(p.Namespace).
+		Name(p.Name).
+		SubResource("portforward").
+		URL()
+	dialer, err := newPortForwardDialer(m.rCfg, http.MethodPost, u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port forward dialer: %w", err)
+	}
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	ports := []string{fmt.Sprintf("%d:%d", localPort, remotePort)}
+	fw, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, ports, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port forwarder: %w", err)
+	}
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() { close(stopCh) })
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		return nil, fmt.Errorf("failed to forward port %d to %s:%d: %w", localPort, p.Name, remotePort, err)
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+	log.Infof("Forwarding localhost:%d to %s:%d", localPort, p.Name, remotePort)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-stopCh:
+		}
+	}()
+	return cancel, nil
+}
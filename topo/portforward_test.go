@@ -0,0 +1,168 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/h-fam/errdiff"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+)
+
+type fakeStream struct {
+	headers http.Header
+}
+
+func (s *fakeStream) Read([]byte) (int, error)    { return 0, io.EOF }
+func (s *fakeStream) Write(b []byte) (int, error) { return len(b), nil }
+func (s *fakeStream) Close() error                { return nil }
+func (s *fakeStream) Reset() error                { return nil }
+func (s *fakeStream) Headers() http.Header        { return s.headers }
+func (s *fakeStream) Identifier() uint32          { return 0 }
+
+type fakeStreamConn struct {
+	streams chan http.Header
+	closed  chan bool
+}
+
+func (c *fakeStreamConn) CreateStream(h http.Header) (httpstream.Stream, error) {
+	c.streams <- h
+	return &fakeStream{headers: h}, nil
+}
+
+func (c *fakeStreamConn) Close() error                       { return nil }
+func (c *fakeStreamConn) CloseChan() <-chan bool             { return c.closed }
+func (c *fakeStreamConn) SetIdleTimeout(time.Duration)       {}
+func (c *fakeStreamConn) RemoveStreams(...httpstream.Stream) {}
+
+type fakeDialer struct {
+	conn *fakeStreamConn
+	err  error
+}
+
+func (d *fakeDialer) Dial(...string) (httpstream.Connection, string, error) {
+	if d.err != nil {
+		return nil, "", d.err
+	}
+	return d.conn, portforward.PortForwardProtocolV1Name, nil
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("failed to find free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestPortForward(t *testing.T) {
+	ctx := context.Background()
+	kClient, err := kubernetes.NewForConfig(&rest.Config{Host: "localhost"})
+	if err != nil {
+		t.Fatalf("failed to create clientset: %v", err)
+	}
+	nodeClient := kfake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}})
+	m := &Manager{
+		topo:    &tpb.Topology{Name: "test"},
+		kClient: kClient,
+		rCfg:    &rest.Config{Host: "localhost"},
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: nodeClient, Proto: &tpb.Node{Name: "r1"}}},
+			"r2": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: nodeClient, Proto: &tpb.Node{Name: "r2"}}},
+		},
+	}
+	origNewPortForwardDialer := newPortForwardDialer
+	defer func() {
+		newPortForwardDialer = origNewPortForwardDialer
+	}()
+
+	tests := []struct {
+		desc    string
+		node    string
+		dialErr error
+		wantErr string
+	}{{
+		desc: "success",
+		node: "r1",
+	}, {
+		desc:    "dial failure",
+		node:    "r1",
+		dialErr: fmt.Errorf("connection refused"),
+		wantErr: "connection refused",
+	}, {
+		desc:    "no pods",
+		node:    "r2",
+		wantErr: "failed to get pods",
+	}, {
+		desc:    "node not found",
+		node:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			conn := &fakeStreamConn{streams: make(chan http.Header, 2), closed: make(chan bool)}
+			var gotURL *url.URL
+			newPortForwardDialer = func(_ *rest.Config, _ string, u *url.URL) (httpstream.Dialer, error) {
+				gotURL = u
+				return &fakeDialer{conn: conn, err: tt.dialErr}, nil
+			}
+			localPort := freePort(t)
+			cancel, err := m.PortForward(ctx, tt.node, localPort, 9339)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("PortForward() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			defer cancel()
+			if want := "/api/v1/namespaces/test/pods/r1/portforward"; gotURL.Path != want {
+				t.Errorf("PortForward() got url path %q, want %q", gotURL.Path, want)
+			}
+			c, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", localPort))
+			if err != nil {
+				t.Fatalf("failed to connect to forwarded port: %v", err)
+			}
+			defer c.Close()
+			select {
+			case h := <-conn.streams:
+				if got, want := h.Get(corev1.PortHeader), "9339"; got != want {
+					t.Errorf("PortForward() got remote port %q, want %q", got, want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("PortForward() did not create a stream for the connection")
+			}
+			cancel()
+			// Cancel must be safe to call more than once.
+			cancel()
+		})
+	}
+}
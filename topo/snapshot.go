@@ -0,0 +1,287 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	log "k8s.io/klog/v2"
+)
+
+var (
+	configMapGVK = corev1.SchemeGroupVersion.WithKind("ConfigMap")
+	serviceGVK   = corev1.SchemeGroupVersion.WithKind("Service")
+	podGVK       = corev1.SchemeGroupVersion.WithKind("Pod")
+	topologyGVK  = topologyv1.SchemeGroupVersion.WithKind("Topology")
+)
+
+// Snapshot writes the config maps, services, meshnet topologies and pods of
+// the topology to w as a stream of JSON objects. The stream can be re-applied
+// to the cluster with Restore.
+func (m *Manager) Snapshot(ctx context.Context, w io.Writer) error {
+	r, err := m.Resources(ctx)
+	if err != nil {
+		return err
+	}
+	var objs []runtime.Object
+	var cms []*corev1.ConfigMap
+	for _, cm := range r.ConfigMaps {
+		cms = append(cms, cm)
+	}
+	sort.Slice(cms, func(i, j int) bool { return cms[i].Name < cms[j].Name })
+	for _, cm := range cms {
+		cm.TypeMeta = metav1.TypeMeta{APIVersion: configMapGVK.GroupVersion().String(), Kind: configMapGVK.Kind}
+		objs = append(objs, cm)
+	}
+	var svcs []*corev1.Service
+	for _, s := range r.Services {
+		svcs = append(svcs, s...)
+	}
+	sort.Slice(svcs, func(i, j int) bool { return svcs[i].Name < svcs[j].Name })
+	for _, s := range svcs {
+		s.TypeMeta = metav1.TypeMeta{APIVersion: serviceGVK.GroupVersion().String(), Kind: serviceGVK.Kind}
+		objs = append(objs, s)
+	}
+	var topos []*topologyv1.Topology
+	for _, t := range r.Topologies {
+		topos = append(topos, t)
+	}
+	sort.Slice(topos, func(i, j int) bool { return topos[i].Name < topos[j].Name })
+	for _, t := range topos {
+		t.TypeMeta = metav1.TypeMeta{APIVersion: topologyGVK.GroupVersion().String(), Kind: topologyGVK.Kind}
+		objs = append(objs, t)
+	}
+	var pods []*corev1.Pod
+	for _, p := range r.Pods {
+		pods = append(pods, p...)
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for _, p := range pods {
+		p.TypeMeta = metav1.TypeMeta{APIVersion: podGVK.GroupVersion().String(), Kind: podGVK.Kind}
+		objs = append(objs, p)
+	}
+	enc := json.NewEncoder(w)
+	for _, o := range objs {
+		if err := enc.Encode(o); err != nil {
+			return fmt.Errorf("failed to encode %T: %w", o, err)
+		}
+	}
+	return nil
+}
+
+// Restore reads a stream of resources written by Snapshot from r and applies
+// them to the topology namespace. Resources which already exist are updated,
+// keeping the immutable fields assigned by the cluster, so Restore can be
+// called repeatedly with the same snapshot. Pods which already exist are left
+// untouched as their spec cannot be updated.
+func (m *Manager) Restore(ctx context.Context, r io.Reader) error {
+	if _, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.namespace(), metav1.GetOptions{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get namespace %q: %w", m.namespace(), err)
+		}
+		if _, err := m.kClient.CoreV1().Namespaces().Create(ctx, m.namespaceResource(), metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create namespace %q: %w", m.namespace(), err)
+		}
+	}
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		var tm metav1.TypeMeta
+		if err := json.Unmarshal(raw, &tm); err != nil {
+			return fmt.Errorf("failed to decode snapshot resource type: %w", err)
+		}
+		if err := m.restore(ctx, tm.GroupVersionKind(), raw); err != nil {
+			return err
+		}
+	}
+}
+
+// Rename moves the topology to the namespace of newName. The namespace is
+// created, the meshnet topologies and services of the topology are copied to
+// it and the old namespace is deleted before the topology proto is updated
+// with the new name. Rename is best effort: pods and config maps are not
+// copied, so the nodes will likely need to be created again in the new
+// namespace.
+func (m *Manager) Rename(ctx context.Context, newName string) error {
+	oldName := m.namespace()
+	if newName == "" {
+		return fmt.Errorf("new topology name must not be empty")
+	}
+	newNS := m.resourcePrefix + newName
+	if newNS == oldName {
+		return nil
+	}
+	topos, err := m.tClient.Topology(oldName).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list topologies in namespace %q: %w", oldName, err)
+	}
+	svcs, err := m.kClient.CoreV1().Services(oldName).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list services in namespace %q: %w", oldName, err)
+	}
+	ns := m.namespaceResource()
+	ns.Name = newNS
+	if _, err := m.kClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create namespace %q: %w", newNS, err)
+	}
+	for i := range topos.Items {
+		t := &topos.Items[i]
+		m.resetObjectMeta(&t.ObjectMeta)
+		t.Namespace = newNS
+		if _, err := m.tClient.Topology(newNS).Create(ctx, t, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to copy topology %q: %w", t.Name, err)
+		}
+	}
+	for i := range svcs.Items {
+		s := &svcs.Items[i]
+		m.resetObjectMeta(&s.ObjectMeta)
+		s.Namespace = newNS
+		s.Status = corev1.ServiceStatus{}
+		s.Spec.ClusterIP = ""
+		s.Spec.ClusterIPs = nil
+		if _, err := m.kClient.CoreV1().Services(newNS).Create(ctx, s, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to copy service %q: %w", s.Name, err)
+		}
+	}
+	prop := metav1.DeletePropagationForeground
+	if err := m.kClient.CoreV1().Namespaces().Delete(ctx, oldName, metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
+		return fmt.Errorf("failed to delete namespace %q: %w", oldName, err)
+	}
+	m.topo.Name = newName
+	return nil
+}
+
+// restore creates or updates the resource of kind gvk encoded in b.
+func (m *Manager) restore(ctx context.Context, gvk schema.GroupVersionKind, b []byte) error {
+	switch gvk {
+	case configMapGVK:
+		cm := &corev1.ConfigMap{}
+		if err := json.Unmarshal(b, cm); err != nil {
+			return fmt.Errorf("failed to decode config map: %w", err)
+		}
+		m.resetObjectMeta(&cm.ObjectMeta)
+		c := m.kClient.CoreV1().ConfigMaps(m.namespace())
+		cur, err := c.Get(ctx, cm.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			_, err = c.Create(ctx, cm, metav1.CreateOptions{})
+		case err == nil:
+			cm.ResourceVersion = cur.ResourceVersion
+			_, err = c.Update(ctx, cm, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore config map %q: %w", cm.Name, err)
+		}
+	case serviceGVK:
+		s := &corev1.Service{}
+		if err := json.Unmarshal(b, s); err != nil {
+			return fmt.Errorf("failed to decode service: %w", err)
+		}
+		m.resetObjectMeta(&s.ObjectMeta)
+		s.Status = corev1.ServiceStatus{}
+		c := m.kClient.CoreV1().Services(m.namespace())
+		cur, err := c.Get(ctx, s.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			// The cluster IPs of the snapshot may already be in use.
+			s.Spec.ClusterIP = ""
+			s.Spec.ClusterIPs = nil
+			_, err = c.Create(ctx, s, metav1.CreateOptions{})
+		case err == nil:
+			s.ResourceVersion = cur.ResourceVersion
+			s.Spec.ClusterIP = cur.Spec.ClusterIP
+			s.Spec.ClusterIPs = cur.Spec.ClusterIPs
+			_, err = c.Update(ctx, s, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore service %q: %w", s.Name, err)
+		}
+	case podGVK:
+		p := &corev1.Pod{}
+		if err := json.Unmarshal(b, p); err != nil {
+			return fmt.Errorf("failed to decode pod: %w", err)
+		}
+		m.resetObjectMeta(&p.ObjectMeta)
+		// Let the scheduler place the pod again.
+		p.Spec.NodeName = ""
+		p.Status = corev1.PodStatus{}
+		c := m.kClient.CoreV1().Pods(m.namespace())
+		_, err := c.Get(ctx, p.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			_, err = c.Create(ctx, p, metav1.CreateOptions{})
+		case err == nil:
+			log.Infof("Pod %q already exists, skipping", p.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore pod %q: %w", p.Name, err)
+		}
+	case topologyGVK:
+		t := &topologyv1.Topology{}
+		if err := json.Unmarshal(b, t); err != nil {
+			return fmt.Errorf("failed to decode topology: %w", err)
+		}
+		m.resetObjectMeta(&t.ObjectMeta)
+		c := m.tClient.Topology(m.namespace())
+		cur, err := c.Get(ctx, t.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			_, err = c.Create(ctx, t, metav1.CreateOptions{})
+		case err == nil:
+			t.ResourceVersion = cur.ResourceVersion
+			var obj map[string]interface{}
+			if obj, err = runtime.DefaultUnstructuredConverter.ToUnstructured(t); err == nil {
+				_, err = c.Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{})
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore topology %q: %w", t.Name, err)
+		}
+	default:
+		return fmt.Errorf("unsupported resource %q in snapshot", gvk)
+	}
+	return nil
+}
+
+// resetObjectMeta clears the fields of om assigned by the cluster so the
+// object can be applied to the topology namespace again.
+func (m *Manager) resetObjectMeta(om *metav1.ObjectMeta) {
+	om.Namespace = m.namespace()
+	om.UID = ""
+	om.ResourceVersion = ""
+	om.Generation = 0
+	om.CreationTimestamp = metav1.Time{}
+	om.DeletionTimestamp = nil
+	om.DeletionGracePeriodSeconds = nil
+	om.OwnerReferences = nil
+	om.ManagedFields = nil
+}
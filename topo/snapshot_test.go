@@ -0,0 +1,256 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/h-fam/errdiff"
+	tfake "github.com/networkop/meshnet-cni/api/clientset/v1beta1/fake"
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
+	tpb "github.com/openconfig/kne/proto/topo"
+	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1009), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1009),
+		}},
+	}
+	newManager := func(t *testing.T, k8sObjects, topoObjects []runtime.Object) *Manager {
+		t.Helper()
+		tf, err := tfake.NewSimpleClientset(topoObjects...)
+		if err != nil {
+			t.Fatalf("cannot create fake topology clientset: %v", err)
+		}
+		m, err := New(proto.Clone(topo).(*tpb.Topology),
+			WithClusterConfig(&rest.Config{}),
+			WithKubeClient(kfake.NewSimpleClientset(k8sObjects...)),
+			WithTopoClient(tf),
+		)
+		if err != nil {
+			t.Fatalf("New() failed to create new topology manager: %v", err)
+		}
+		return m
+	}
+	src := newManager(t, []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", UID: "pod-uid", ResourceVersion: "10"},
+			Spec:       corev1.PodSpec{NodeName: "worker-1", Containers: []corev1.Container{{Name: "r1", Image: "foo:latest"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test", UID: "svc-uid"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.1.1.1", Ports: []corev1.ServicePort{{Name: "ssh", Port: 22}}},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1-config", Namespace: "test"},
+			Data:       map[string]string{"config": "hostname r1"},
+		},
+	}, []runtime.Object{
+		&topologyv1.Topology{
+			TypeMeta:   metav1.TypeMeta{Kind: "Topology", APIVersion: "networkop.co.uk/v1beta1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+			Spec:       topologyv1.TopologySpec{Links: []topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}},
+		},
+	})
+	var snapshot bytes.Buffer
+	if err := src.Snapshot(ctx, &snapshot); err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+
+	dst := newManager(t, nil, nil)
+	// Restoring twice must be idempotent.
+	for i := 0; i < 2; i++ {
+		if err := dst.Restore(ctx, bytes.NewReader(snapshot.Bytes())); err != nil {
+			t.Fatalf("Restore() failed on attempt %d: %v", i, err)
+		}
+	}
+	if _, err := dst.kClient.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{}); err != nil {
+		t.Errorf("Restore() did not create namespace: %v", err)
+	}
+	pod, err := dst.kClient.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Restore() did not create pod: %v", err)
+	}
+	if pod.UID != "" || pod.Spec.NodeName != "" || pod.Status.Phase != "" {
+		t.Errorf("Restore() kept cluster assigned pod fields: uid %q, node %q, phase %q", pod.UID, pod.Spec.NodeName, pod.Status.Phase)
+	}
+	if s := cmp.Diff([]corev1.Container{{Name: "r1", Image: "foo:latest"}}, pod.Spec.Containers); s != "" {
+		t.Errorf("Restore() unexpected pod containers diff (-want +got):\n%s", s)
+	}
+	svc, err := dst.kClient.CoreV1().Services("test").Get(ctx, "service-r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Restore() did not create service: %v", err)
+	}
+	if svc.UID != "" || svc.Spec.ClusterIP != "" {
+		t.Errorf("Restore() kept cluster assigned service fields: uid %q, cluster ip %q", svc.UID, svc.Spec.ClusterIP)
+	}
+	cm, err := dst.kClient.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Restore() did not create config map: %v", err)
+	}
+	if s := cmp.Diff(map[string]string{"config": "hostname r1"}, cm.Data); s != "" {
+		t.Errorf("Restore() unexpected config map data diff (-want +got):\n%s", s)
+	}
+	topology, err := dst.tClient.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Restore() did not create topology: %v", err)
+	}
+	if s := cmp.Diff([]topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}, topology.Spec.Links); s != "" {
+		t.Errorf("Restore() unexpected topology links diff (-want +got):\n%s", s)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc     string
+		snapshot string
+		wantErr  string
+	}{{
+		desc:     "invalid json",
+		snapshot: `{"kind": "Pod"`,
+		wantErr:  "failed to decode snapshot",
+	}, {
+		desc:     "unsupported kind",
+		snapshot: `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "foo"}}`,
+		wantErr:  "unsupported resource",
+	}, {
+		desc:     "version mismatch",
+		snapshot: `{"apiVersion": "networkop.co.uk/v1alpha1", "kind": "Topology", "metadata": {"name": "r1"}}`,
+		wantErr:  "unsupported resource",
+	}, {
+		desc:     "empty",
+		snapshot: "",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			m := &Manager{
+				topo:    &tpb.Topology{Name: "test"},
+				kClient: kfake.NewSimpleClientset(),
+				tClient: tf,
+			}
+			err = m.Restore(ctx, strings.NewReader(tt.snapshot))
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("Restore() unexpected err: %s", s)
+			}
+		})
+	}
+}
+
+func TestRename(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc    string
+		newName string
+		objs    []runtime.Object
+		wantErr string
+	}{{
+		desc:    "success",
+		newName: "renamed",
+	}, {
+		desc:    "same name",
+		newName: "test",
+	}, {
+		desc:    "empty name",
+		wantErr: "must not be empty",
+	}, {
+		desc:    "namespace exists",
+		newName: "renamed",
+		objs:    []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "renamed"}}},
+		wantErr: `failed to create namespace "renamed"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset(&topologyv1.Topology{
+				TypeMeta:   metav1.TypeMeta{Kind: "Topology", APIVersion: "networkop.co.uk/v1beta1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+				Spec:       topologyv1.TopologySpec{Links: []topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}},
+			})
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(append([]runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test", UID: "svc-uid"},
+					Spec:       corev1.ServiceSpec{ClusterIP: "10.1.1.1", Ports: []corev1.ServicePort{{Name: "ssh", Port: 22}}},
+				},
+			}, tt.objs...)...)
+			m := &Manager{
+				topo:    &tpb.Topology{Name: "test"},
+				kClient: kf,
+				tClient: tf,
+			}
+			err = m.Rename(ctx, tt.newName)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Rename() unexpected err: %s", s)
+			}
+			if err != nil {
+				if m.topo.Name != "test" {
+					t.Errorf("Rename() failed but changed topology name to %q", m.topo.Name)
+				}
+				return
+			}
+			if m.topo.Name != tt.newName {
+				t.Errorf("Rename() got topology name %q, want %q", m.topo.Name, tt.newName)
+			}
+			if tt.newName == "test" {
+				return
+			}
+			if _, err := kf.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{}); err == nil {
+				t.Errorf("Rename() did not delete old namespace")
+			}
+			if _, err := kf.CoreV1().Namespaces().Get(ctx, tt.newName, metav1.GetOptions{}); err != nil {
+				t.Errorf("Rename() did not create namespace: %v", err)
+			}
+			svc, err := kf.CoreV1().Services(tt.newName).Get(ctx, "service-r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Rename() did not copy service: %v", err)
+			}
+			if svc.UID != "" || svc.Spec.ClusterIP != "" {
+				t.Errorf("Rename() kept cluster assigned service fields: uid %q, cluster ip %q", svc.UID, svc.Spec.ClusterIP)
+			}
+			topology, err := tf.Topology(tt.newName).Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Rename() did not copy topology: %v", err)
+			}
+			if s := cmp.Diff([]topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}, topology.Spec.Links); s != "" {
+				t.Errorf("Rename() unexpected topology links diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
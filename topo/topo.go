@@ -15,11 +15,22 @@
 package topo
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -34,13 +45,24 @@ import (
 	epb "github.com/openconfig/kne/proto/event"
 	tpb "github.com/openconfig/kne/proto/topo"
 	"github.com/openconfig/kne/topo/node"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -67,11 +89,72 @@ type Manager struct {
 	topo           *tpb.Topology
 	nodes          map[string]node.Node
 	kubecfg        string
+	kubeContext    string
 	kClient        kubernetes.Interface
 	tClient        topologyclientv1.Interface
+	mClient        dynamic.Interface
 	rCfg           *rest.Config
 	basePath       string
 	skipDeleteWait bool
+	// waitForDelete is the timeout of polling for the deletion of the
+	// topology namespace in Delete instead of watching for it.
+	waitForDelete time.Duration
+	// parallelism is the number of nodes created concurrently by push.
+	parallelism int
+
+	// labels are added to the topology namespace and all nodes.
+	labels map[string]string
+	// annotations are added to the topology namespace.
+	annotations map[string]string
+	// defaultResources are the resource requests and limits of nodes
+	// which do not set their own constraints.
+	defaultResources *corev1.ResourceRequirements
+	// nodeDefaults are merged under the protos of nodes of the vendor.
+	nodeDefaults map[tpb.Vendor]*tpb.Node
+	// serviceAccount is the k8s service account the node pods run as.
+	serviceAccount string
+	// podSecurityContext is the security context of the node pods.
+	podSecurityContext *corev1.PodSecurityContext
+	// imagePullSecrets are the secrets used to pull the node images.
+	imagePullSecrets []corev1.LocalObjectReference
+	// registryCredential is stored in the first of imagePullSecrets.
+	registryCredential *RegistryCredential
+	// includeConfigMaps adds the startup config of the nodes to Show.
+	includeConfigMaps bool
+	// audit records the topology operations if set.
+	audit *auditLog
+	// nodeFactory creates all nodes instead of the registered vendor
+	// implementations if set.
+	nodeFactory node.NewNodeFn
+
+	// pollInterval is the initial interval between node status checks.
+	pollInterval time.Duration
+	// maxPollInterval caps the exponential backoff between node status checks.
+	maxPollInterval time.Duration
+	// statusCallback is called with each node status checked by Create.
+	statusCallback StatusCallback
+	// probeCommand builds the command run by TestConnectivity.
+	probeCommand ProbeCommand
+	// watchFormat is the format Watch prints events in.
+	watchFormat string
+	// upsert makes push update the resources which already exist instead of
+	// failing.
+	upsert bool
+	// resourcePrefix is prepended to the topology name to form the
+	// namespace of the topology.
+	resourcePrefix string
+	// upgradeTimeout is how long UpgradeNode waits for the upgraded node to
+	// be ready before rolling back.
+	upgradeTimeout time.Duration
+	// strictLinks makes Create fail on ValidateLinks warnings instead of
+	// only logging them.
+	strictLinks bool
+	// linkWarnings are the ValidateLinks warnings for the topology as given,
+	// before load fills in the interfaces of the links.
+	linkWarnings []ValidationWarning
+
+	// mu serializes the nodes and links added to a running topology.
+	mu sync.Mutex
 
 	// If reportUsage is set, report anonymous usage metrics.
 	reportUsage bool
@@ -95,6 +178,14 @@ func WithKubecfg(k string) Option {
 	}
 }
 
+// WithKubeContext sets the context of the kubeconfig used to determine the
+// cluster config instead of the current context.
+func WithKubeContext(c string) Option {
+	return func(m *Manager) {
+		m.kubeContext = c
+	}
+}
+
 func WithKubeClient(c kubernetes.Interface) Option {
 	return func(m *Manager) {
 		m.kClient = c
@@ -107,6 +198,13 @@ func WithTopoClient(c topologyclientv1.Interface) Option {
 	}
 }
 
+// WithMetricsClient sets the client used to query the pod metrics API.
+func WithMetricsClient(c dynamic.Interface) Option {
+	return func(m *Manager) {
+		m.mClient = c
+	}
+}
+
 func WithClusterConfig(r *rest.Config) Option {
 	return func(m *Manager) {
 		m.rCfg = r
@@ -135,6 +233,14 @@ func WithProgress(b bool) Option {
 	}
 }
 
+// WithParallelism sets the number of nodes created concurrently when the
+// topology is pushed. Values less than 2 create the nodes sequentially.
+func WithParallelism(n int) Option {
+	return func(m *Manager) {
+		m.parallelism = n
+	}
+}
+
 // WithSkipDeleteWait will not wait for resources to be cleaned up before Delete returns.
 func WithSkipDeleteWait(b bool) Option {
 	return func(m *Manager) {
@@ -142,22 +248,187 @@ func WithSkipDeleteWait(b bool) Option {
 	}
 }
 
+// WithWaitForDelete makes Delete wait with WaitForDelete for up to timeout
+// for the topology namespace to be deleted. It has no effect if
+// WithSkipDeleteWait is set.
+func WithWaitForDelete(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.waitForDelete = timeout
+	}
+}
+
+// WithPollInterval sets the initial interval between node status checks
+// while waiting for the topology to come up, and between service checks in
+// WaitForService.
+func WithPollInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.pollInterval = d
+	}
+}
+
+// WithMaxPollInterval sets the upper bound the node status and service check
+// intervals back off to.
+func WithMaxPollInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.maxPollInterval = d
+	}
+}
+
+// StatusCallback is called with the phase of a node each time its status is
+// checked and the time elapsed since the checks started.
+type StatusCallback func(nodeName, phase string, elapsed time.Duration)
+
+// WithStatusCallback calls f, in addition to logging, for each node status
+// checked while Create waits for the nodes to be running, such as to display
+// progress.
+func WithStatusCallback(f StatusCallback) Option {
+	return func(m *Manager) {
+		m.statusCallback = f
+	}
+}
+
+// WithDefaultResources sets the CPU and memory requests and limits used for
+// every node in the topology. Constraints set on a node take precedence over
+// the defaults for the same resource, and the defaults take precedence over
+// the vendor defaults.
+func WithDefaultResources(r *corev1.ResourceRequirements) Option {
+	return func(m *Manager) {
+		m.defaultResources = r
+	}
+}
+
+// WithServiceAccount sets the k8s service account the pods of all nodes in
+// the topology run as. The service account is created in the topology
+// namespace if it does not already exist.
+func WithServiceAccount(name string) Option {
+	return func(m *Manager) {
+		m.serviceAccount = name
+	}
+}
+
+// WithPodSecurityContext sets the security context of the pods of all nodes
+// in the topology, such as to meet the pod security standards enforced by the
+// cluster. The security context set in a node config takes precedence.
+func WithPodSecurityContext(sc *corev1.PodSecurityContext) Option {
+	return func(m *Manager) {
+		m.podSecurityContext = sc
+	}
+}
+
+// WithResourcePrefix prepends p to the name of the namespace holding all of
+// the resources of the topology, such as to let several users create
+// topologies of the same name in a shared cluster. The pods, services and
+// other resources of the topology are named as without a prefix as they are
+// scoped to the prefixed namespace. The topology proto keeps its unprefixed
+// name, and the prefix is also applied to the new names passed to Clone and
+// Rename.
+func WithResourcePrefix(p string) Option {
+	return func(m *Manager) {
+		m.resourcePrefix = p
+	}
+}
+
+// WithImagePullSecrets sets the secrets in the topology namespace used to pull
+// the images of all nodes in the topology from private registries.
+func WithImagePullSecrets(secrets ...corev1.LocalObjectReference) Option {
+	return func(m *Manager) {
+		m.imagePullSecrets = secrets
+	}
+}
+
+// RegistryCredential is the login to a private container registry.
+type RegistryCredential struct {
+	Server   string
+	Username string
+	Password string
+}
+
+// WithRegistryCredential sets the registry login stored in the first secret
+// set by WithImagePullSecrets. The secret is created in the topology
+// namespace, or updated if it already exists, when the topology is pushed.
+func WithRegistryCredential(c *RegistryCredential) Option {
+	return func(m *Manager) {
+		m.registryCredential = c
+	}
+}
+
+// WithIncludeConfigMaps sets whether Show returns the startup config of each
+// node, read from the ConfigMap holding it, in addition to its services.
+func WithIncludeConfigMaps(b bool) Option {
+	return func(m *Manager) {
+		m.includeConfigMaps = b
+	}
+}
+
+// WithNodeFactory sets the function used to create every node in the
+// topology, bypassing the implementations registered with node.Vendor. This
+// allows tests to use fake nodes without registering vendors globally.
+func WithNodeFactory(f node.NewNodeFn) Option {
+	return func(m *Manager) {
+		m.nodeFactory = f
+	}
+}
+
+// WithNodeDefaults sets default values for all nodes of vendor v in the
+// topology. The defaults are deep merged under each node proto so that fields
+// set on the node take precedence. Lists set on the node replace the defaults.
+func WithNodeDefaults(v tpb.Vendor, defaults *tpb.Node) Option {
+	return func(m *Manager) {
+		if m.nodeDefaults == nil {
+			m.nodeDefaults = map[tpb.Vendor]*tpb.Node{}
+		}
+		m.nodeDefaults[v] = defaults
+	}
+}
+
+// WithLabels sets labels that are added to the topology namespace and every
+// node in the topology. Labels set on a node take precedence.
+func WithLabels(l map[string]string) Option {
+	return func(m *Manager) {
+		m.labels = l
+	}
+}
+
+// WithAnnotations sets annotations that are added to the topology namespace,
+// such as those required by network policies or admission webhooks.
+func WithAnnotations(a map[string]string) Option {
+	return func(m *Manager) {
+		m.annotations = a
+	}
+}
+
 // New creates a new Manager based on the provided topology. The cluster config
 // passed from the WithClusterConfig option overrides the determined in-cluster
 // config. If neither of these configurations can be used then the kubecfg passed
-// from the WithKubecfg option will be used to determine the cluster config.
+// from the WithKubecfg option will be used to determine the cluster config. If
+// a context is passed from the WithKubeContext option the in-cluster config is
+// skipped and that context of the kubecfg is used.
 func New(topo *tpb.Topology, opts ...Option) (*Manager, error) {
 	if topo == nil {
 		return nil, fmt.Errorf("topology cannot be nil")
 	}
 	m := &Manager{
-		topo:  topo,
-		nodes: map[string]node.Node{},
+		topo:            topo,
+		nodes:           map[string]node.Node{},
+		pollInterval:    defaultPollInterval,
+		maxPollInterval: defaultMaxPollInterval,
 	}
 	for _, o := range opts {
 		o(m)
 	}
-	if m.rCfg == nil {
+	switch {
+	case m.rCfg != nil:
+	case m.kubeContext != "":
+		log.Infof("Using context %q of kubeconfig: %q", m.kubeContext, m.kubecfg)
+		rCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: m.kubecfg},
+			&clientcmd.ConfigOverrides{CurrentContext: m.kubeContext},
+		).ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		m.rCfg = rCfg
+	default:
 		log.Infof("Trying in-cluster configuration")
 		rCfg, err := rest.InClusterConfig()
 		if err != nil {
@@ -183,9 +454,26 @@ func New(topo *tpb.Topology, opts ...Option) (*Manager, error) {
 		}
 		m.tClient = tClient
 	}
+	if m.mClient == nil {
+		mClient, err := dynamic.NewForConfig(m.rCfg)
+		if err != nil {
+			return nil, err
+		}
+		m.mClient = mClient
+	}
+	if m.audit != nil {
+		m.audit.user = m.kubeUser()
+	}
+	if err := m.Validate(); err != nil {
+		m.record("load", err)
+		return nil, fmt.Errorf("failed to validate topology: %w", err)
+	}
+	m.linkWarnings = ValidateLinks(m.topo)
 	if err := m.load(); err != nil {
+		m.record("load", err)
 		return nil, fmt.Errorf("failed to load topology: %w", err)
 	}
+	m.record("load", nil)
 	log.V(1).Infof("Created manager for topology:\n%v", prototext.Format(m.topo))
 	return m, nil
 }
@@ -210,6 +498,25 @@ var (
 		return metrics.NewReporter(ctx, project, topic)
 	}
 	deleteWatchTimeout = 30 * time.Second
+	sleep              = time.Sleep
+)
+
+const (
+	defaultPollInterval     = 100 * time.Millisecond
+	defaultMaxPollInterval  = 5 * time.Second
+	defaultUpgradeTimeout   = 5 * time.Minute
+	defaultReconnectTimeout = 5 * time.Minute
+
+	// NetworkPolicyName is the name of the NetworkPolicy created by
+	// CreateNetworkPolicy in the topology namespace.
+	NetworkPolicyName = "kne-isolation"
+
+	// PausedAnnotation is set on the pods of a node stopped by Pause.
+	PausedAnnotation = "kne.google.com/paused"
+	// VersionAnnotation and CommitAnnotation record the release set by
+	// TagRelease on the topology namespace and node pods.
+	VersionAnnotation = "kne.google.com/version"
+	CommitAnnotation  = "kne.google.com/commit"
 )
 
 type metricsReporter interface {
@@ -239,11 +546,15 @@ func (m *Manager) reportCreateEvent(ctx context.Context) func(error) {
 
 // Create creates the topology in the cluster.
 func (m *Manager) Create(ctx context.Context, timeout time.Duration) (rerr error) {
+	defer func() { m.record("create", rerr) }()
 	log.V(1).Infof("Topology:\n%v", prototext.Format(m.topo))
 	if m.reportUsage {
 		finish := m.reportCreateEvent(ctx)
 		defer func() { finish(rerr) }()
 	}
+	if err := m.validateLinks(); err != nil {
+		return err
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	// Watch the containter status of the pods so we can fail if a container fails to start running.
 	if w, err := pods.NewWatcher(ctx, m.kClient, cancel); err != nil {
@@ -274,11 +585,120 @@ func (m *Manager) Create(ctx context.Context, timeout time.Duration) (rerr error
 	return nil
 }
 
-// Delete deletes the topology from the cluster.
-func (m *Manager) Delete(ctx context.Context) error {
+// validateLinks logs the ValidateLinks warnings for the topology as it was
+// passed to New. If strict link validation is set the warnings are returned as
+// an error.
+func (m *Manager) validateLinks() error {
+	var errs errlist.List
+	for _, w := range m.linkWarnings {
+		log.Warningf("Topology %q: %s", m.topo.GetName(), w)
+		if m.strictLinks {
+			errs.Add(fmt.Errorf("%s", w))
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return fmt.Errorf("invalid links in topology %q: %w", m.topo.GetName(), err)
+	}
+	return nil
+}
+
+// Clone creates a copy of the topology named newName in the cluster. The
+// topology proto is deep-copied and loaded by a new manager with the same
+// cluster config and settings as m, which opts are applied after. The nodes of
+// the copy are pushed to the cluster but not checked for readiness.
+func (m *Manager) Clone(ctx context.Context, newName string, opts ...Option) (*Manager, error) {
+	if newName == "" {
+		return nil, fmt.Errorf("new topology name must not be empty")
+	}
+	if newName == m.topo.GetName() {
+		return nil, fmt.Errorf("new topology name must differ from %q", newName)
+	}
+	t := m.unloaded()
+	t.Name = newName
+	c, err := New(t, append([]Option{m.settings()}, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load topology %q: %w", newName, err)
+	}
+	if err := c.push(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create topology %q: %w", newName, err)
+	}
+	log.Infof("Topology %q cloned to %q", m.topo.GetName(), newName)
+	return c, nil
+}
+
+// unloaded returns a copy of the topology proto with the peers filled in by
+// load cleared, so that its links can be loaded again.
+func (m *Manager) unloaded() *tpb.Topology {
+	t := proto.Clone(m.topo).(*tpb.Topology)
+	for _, n := range t.Nodes {
+		for _, intf := range n.Interfaces {
+			intf.PeerName = ""
+			intf.PeerIntName = ""
+		}
+	}
+	return t
+}
+
+// settings returns an Option applying the cluster config and settings of m.
+func (m *Manager) settings() Option {
+	return func(c *Manager) {
+		c.progress = m.progress
+		c.kubecfg = m.kubecfg
+		c.kubeContext = m.kubeContext
+		c.kClient = m.kClient
+		c.tClient = m.tClient
+		c.mClient = m.mClient
+		c.rCfg = m.rCfg
+		c.basePath = m.basePath
+		c.skipDeleteWait = m.skipDeleteWait
+		c.waitForDelete = m.waitForDelete
+		c.parallelism = m.parallelism
+		c.labels = m.labels
+		c.annotations = m.annotations
+		c.defaultResources = m.defaultResources
+		c.nodeDefaults = m.nodeDefaults
+		c.serviceAccount = m.serviceAccount
+		c.podSecurityContext = m.podSecurityContext
+		c.imagePullSecrets = m.imagePullSecrets
+		c.registryCredential = m.registryCredential
+		c.includeConfigMaps = m.includeConfigMaps
+		c.audit = m.audit
+		c.nodeFactory = m.nodeFactory
+		c.pollInterval = m.pollInterval
+		c.maxPollInterval = m.maxPollInterval
+		c.statusCallback = m.statusCallback
+		c.probeCommand = m.probeCommand
+		c.watchFormat = m.watchFormat
+		c.strictLinks = m.strictLinks
+		c.upgradeTimeout = m.upgradeTimeout
+		c.upsert = m.upsert
+		c.resourcePrefix = m.resourcePrefix
+		c.reportUsage = m.reportUsage
+		c.reportUsageProjectID = m.reportUsageProjectID
+		c.reportUsageTopicID = m.reportUsageTopicID
+	}
+}
+
+// TopologyNotFoundError is returned when the namespace of a topology does
+// not exist in the cluster.
+type TopologyNotFoundError struct {
+	Name string
+}
+
+func (e TopologyNotFoundError) Error() string {
+	return fmt.Sprintf("topology %q does not exist in cluster", e.Name)
+}
+
+// Delete deletes the topology from the cluster. If the topology namespace
+// does not exist a TopologyNotFoundError is returned.
+func (m *Manager) Delete(ctx context.Context) (rerr error) {
+	defer func() { m.record("delete", rerr) }()
 	log.Infof("Topology:\n%v", prototext.Format(m.topo))
-	if _, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.topo.Name, metav1.GetOptions{}); err != nil {
-		return fmt.Errorf("topology %q does not exist in cluster", m.topo.Name)
+	if _, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.namespace(), metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return TopologyNotFoundError{Name: m.namespace()}
+		}
+		return fmt.Errorf("failed to get namespace %q: %w", m.namespace(), err)
 	}
 
 	// Delete topology nodes.
@@ -293,13 +713,17 @@ func (m *Manager) Delete(ctx context.Context) error {
 		log.Warningf("Failed to delete meshnet topologies for topology %q: %v", m.topo.GetName(), err)
 	}
 
-	if m.skipDeleteWait {
+	if m.skipDeleteWait || m.waitForDelete > 0 {
 		// Delete the namespace.
 		prop := metav1.DeletePropagationForeground
-		if err := m.kClient.CoreV1().Namespaces().Delete(ctx, m.topo.Name, metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
-			return fmt.Errorf("failed to delete namespace %q: %w", m.topo.Name, err)
+		if err := m.kClient.CoreV1().Namespaces().Delete(ctx, m.namespace(), metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
+			return fmt.Errorf("failed to delete namespace %q: %w", m.namespace(), err)
 		}
-		return nil
+		if m.skipDeleteWait {
+			return nil
+		}
+		log.Infof("Waiting for namespace %q to be deleted", m.namespace())
+		return m.WaitForDelete(ctx, m.waitForDelete)
 	}
 
 	// Watch for namespace deletion.
@@ -308,23 +732,118 @@ func (m *Manager) Delete(ctx context.Context) error {
 	go func() {
 		tCtx, cancel := context.WithTimeout(ctx, deleteWatchTimeout)
 		defer cancel()
-		waitNSDeleted(tCtx, m.kClient, m.topo.Name, c)
+		waitNSDeleted(tCtx, m.kClient, m.namespace(), c)
 	}()
 
 	// Delete the namespace.
 	prop := metav1.DeletePropagationForeground
-	if err := m.kClient.CoreV1().Namespaces().Delete(ctx, m.topo.Name, metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
-		return fmt.Errorf("failed to delete namespace %q: %w", m.topo.Name, err)
+	if err := m.kClient.CoreV1().Namespaces().Delete(ctx, m.namespace(), metav1.DeleteOptions{PropagationPolicy: &prop}); err != nil {
+		return fmt.Errorf("failed to delete namespace %q: %w", m.namespace(), err)
 	}
 
 	// Wait for namespace deletion.
-	log.Infof("Waiting for namespace %q to be deleted", m.topo.Name)
+	log.Infof("Waiting for namespace %q to be deleted", m.namespace())
 	if err := <-c; err != nil {
-		return fmt.Errorf("failed to wait for namespace %q deletion: %w", m.topo.Name, err)
+		return fmt.Errorf("failed to wait for namespace %q deletion: %w", m.namespace(), err)
 	}
 	return nil
 }
 
+// WaitForDelete polls until the topology namespace no longer exists or the
+// timeout elapses. It is used by Delete if WithWaitForDelete is set and by
+// callers of Delete with WithSkipDeleteWait set which need to know when the
+// topology can be created again. On timeout the resources remaining in the
+// namespace are reported.
+func (m *Manager) WaitForDelete(ctx context.Context, timeout time.Duration) error {
+	interval := m.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	tCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		_, err := m.kClient.CoreV1().Namespaces().Get(tCtx, m.namespace(), metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			log.Infof("Namespace %q deleted", m.namespace())
+			return nil
+		case err != nil && tCtx.Err() == nil:
+			return fmt.Errorf("failed to get namespace %q: %w", m.namespace(), err)
+		}
+		select {
+		case <-tCtx.Done():
+			return fmt.Errorf("timed out after %v waiting for namespace %q deletion, remaining resources: %s", timeout, m.namespace(), m.remainingResources(ctx))
+		default:
+		}
+		sleep(interval)
+	}
+}
+
+// WaitForService polls the service of the provided node until its
+// LoadBalancer has been assigned an external address or the timeout elapses.
+// The interval between polls backs off exponentially from the poll interval
+// up to the max poll interval.
+func (m *Manager) WaitForService(ctx context.Context, nodeName string, timeout time.Duration) (*corev1.Service, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	interval := m.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := m.maxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+	tCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		svcs, err := n.Services(tCtx)
+		switch {
+		case err == nil:
+			for _, s := range svcs {
+				if len(s.Status.LoadBalancer.Ingress) > 0 {
+					return s, nil
+				}
+			}
+		case !apierrors.IsNotFound(err) && tCtx.Err() == nil:
+			return nil, fmt.Errorf("failed to get service of node %q: %w", nodeName, err)
+		}
+		select {
+		case <-tCtx.Done():
+			return nil, fmt.Errorf("timed out after %v waiting for service of node %q to get an external address", timeout, nodeName)
+		default:
+		}
+		sleep(interval)
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// remainingResources returns a description of the pods and services left in
+// the topology namespace.
+func (m *Manager) remainingResources(ctx context.Context) string {
+	var remaining []string
+	if pods, err := m.kClient.CoreV1().Pods(m.namespace()).List(ctx, metav1.ListOptions{}); err == nil {
+		for _, p := range pods.Items {
+			remaining = append(remaining, "pod/"+p.Name)
+		}
+	}
+	if svcs, err := m.kClient.CoreV1().Services(m.namespace()).List(ctx, metav1.ListOptions{}); err == nil {
+		for _, s := range svcs.Items {
+			remaining = append(remaining, "service/"+s.Name)
+		}
+	}
+	if len(remaining) == 0 {
+		return "none"
+	}
+	sort.Strings(remaining)
+	return strings.Join(remaining, ", ")
+}
+
 // waitNSDeleted waits for a namespace to be deleted. Write a non-nil error to the errCh
 // if waiting is unsuccessful. Else write a nil error to errCh and then return.
 func waitNSDeleted(ctx context.Context, kClient kubernetes.Interface, ns string, errCh chan error) {
@@ -360,126 +879,635 @@ func waitNSDeleted(ctx context.Context, kClient kubernetes.Interface, ns string,
 }
 
 // Show returns the topology information including services and node health.
+// Services which are missing or have no external IP yet are marked pending
+// rather than failing the request. Services which cannot be mapped are skipped
+// and their errors are returned along with the response built from the rest.
 func (m *Manager) Show(ctx context.Context) (*cpb.ShowTopologyResponse, error) {
 	log.Infof("Topology:\n%v", prototext.Format(m.topo))
-	r, err := m.Resources(ctx)
+	r, err := m.resources(ctx, true)
 	if err != nil {
 		return nil, err
 	}
+	var errs errlist.List
 	for _, n := range m.topo.Nodes {
 		if len(n.Services) == 0 {
 			n.Services = map[uint32]*tpb.Service{}
 		}
 		services, ok := r.Services[n.Name]
 		if !ok {
-			return nil, fmt.Errorf("services for node %s not found", n.Name)
+			log.Warningf("Services for node %s not found, marking them pending", n.Name)
+			for _, svc := range n.Services {
+				svc.OutsideIp = ""
+				svc.Status = tpb.Service_STATUS_PENDING
+			}
+			continue
 		}
 		for _, svc := range services {
 			if err := populateServiceMap(svc, n.Services); err != nil {
-				return nil, err
+				log.Warningf("Skipping service %s of node %s: %v", svc.Name, n.Name, err)
+				errs.Add(fmt.Errorf("node %q: %w", n.Name, err))
 			}
 		}
+		populateIngressHosts(services, r.Ingresses, n.Services)
 	}
 	stateMap := &stateMap{}
 	for _, n := range m.nodes {
 		phase, _ := n.Status(ctx)
 		stateMap.setNodeState(n.Name(), phase)
 	}
-	return &cpb.ShowTopologyResponse{
-		State:    stateMap.topologyState(),
-		Topology: m.topo,
-	}, nil
-}
-
-func (m *Manager) Watch(ctx context.Context) error {
-	watcher, err := m.tClient.Topology(m.topo.Name).Watch(ctx, metav1.ListOptions{})
-	if err != nil {
-		return err
+	nodeStates := map[string]string{}
+	for name, state := range stateMap.nodeStates() {
+		nodeStates[name] = string(state)
 	}
-	ch := watcher.ResultChan()
-	for e := range ch {
-		fmt.Println(e.Type)
-		pretty.Print(e.Object)
-		fmt.Println("")
+	resp := &cpb.ShowTopologyResponse{
+		State:      stateMap.topologyState(),
+		Topology:   m.topo,
+		NodeStates: nodeStates,
 	}
-	return nil
+	if m.includeConfigMaps {
+		resp.NodeConfigs = nodeConfigs(m.topo.Nodes, r.ConfigMaps)
+	}
+	return resp, errs.Err()
 }
 
-// Nodes returns a map of node names to implementations in the current topology.
-func (m *Manager) Nodes() map[string]node.Node {
-	return m.nodes
+// Endpoint is an address at which a node service can be reached.
+type Endpoint struct {
+	Host string
+	Port uint32
 }
 
-// load populates the internal fields of the topology proto.
-func (m *Manager) load() error {
-	nMap := map[string]*tpb.Node{}
-	for _, n := range m.topo.Nodes {
-		if len(n.Interfaces) == 0 {
-			n.Interfaces = map[string]*tpb.Interface{}
-		}
-		for k := range n.Interfaces {
-			if n.Interfaces[k].IntName == "" {
-				n.Interfaces[k].IntName = k
+// ServiceEndpoints returns the endpoints of the services of each node keyed by
+// node name and service port. The external load balancer address is used if
+// one has been assigned, otherwise the cluster IP of the service is used.
+// Nodes whose services do not exist are left out.
+func (m *Manager) ServiceEndpoints(ctx context.Context) (map[string]map[uint32]Endpoint, error) {
+	r, err := m.resources(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := map[string]map[uint32]Endpoint{}
+	for name, services := range r.Services {
+		eps := map[uint32]Endpoint{}
+		for _, s := range services {
+			host := serviceHost(s)
+			for _, p := range s.Spec.Ports {
+				eps[uint32(p.Port)] = Endpoint{Host: host, Port: uint32(p.Port)}
 			}
 		}
-		nMap[n.Name] = n
+		endpoints[name] = eps
 	}
-	uid := 0
-	for _, l := range m.topo.Links {
-		log.Infof("Adding Link: %s:%s %s:%s", l.ANode, l.AInt, l.ZNode, l.ZInt)
-		if l.ANode == l.ZNode {
-			return fmt.Errorf("invalid link: hardware loopback %s:%s %s:%s not supported", l.ANode, l.AInt, l.ZNode, l.ZInt)
-		}
-		aNode, ok := nMap[l.ANode]
-		if !ok {
-			return fmt.Errorf("invalid topology: missing node %q", l.ANode)
+	return endpoints, nil
+}
+
+// serviceHost returns the load balancer address of s, or its cluster IP if
+// it has none.
+func serviceHost(s *corev1.Service) string {
+	if ing := s.Status.LoadBalancer.Ingress; len(ing) > 0 {
+		switch {
+		case ing[0].IP != "":
+			return ing[0].IP
+		case ing[0].Hostname != "":
+			return ing[0].Hostname
 		}
-		aInt, ok := aNode.Interfaces[l.AInt]
-		if !ok {
-			aInt = &tpb.Interface{
-				IntName: l.AInt,
+	}
+	return s.Spec.ClusterIP
+}
+
+// TerminalPortName is the name of the service port of the web terminal of a
+// node.
+const TerminalPortName = "terminal"
+
+// TerminalNotExposedError is returned by NodeTerminalURL when the node has
+// no service port named TerminalPortName.
+type TerminalNotExposedError struct {
+	Node string
+}
+
+func (e TerminalNotExposedError) Error() string {
+	return fmt.Sprintf("node %q does not expose a web terminal", e.Node)
+}
+
+// NodeTerminalURL returns the URL of the web terminal of the provided node,
+// served on the service port named TerminalPortName. The host is the load
+// balancer address of the service, or its cluster IP if it has none. If the
+// node has no such port a TerminalNotExposedError is returned.
+func (m *Manager) NodeTerminalURL(ctx context.Context, nodeName string) (string, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return "", status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	services, err := n.Services(ctx)
+	switch {
+	case apierrors.IsNotFound(err):
+		return "", TerminalNotExposedError{Node: nodeName}
+	case err != nil:
+		return "", fmt.Errorf("failed to get services for node %q: %w", nodeName, err)
+	}
+	for _, s := range services {
+		for _, p := range s.Spec.Ports {
+			if p.Name == TerminalPortName {
+				u := url.URL{Scheme: "http", Host: net.JoinHostPort(serviceHost(s), strconv.Itoa(int(p.Port)))}
+				return u.String(), nil
 			}
-			aNode.Interfaces[l.AInt] = aInt
 		}
-		zNode, ok := nMap[l.ZNode]
-		if !ok {
-			return fmt.Errorf("invalid topology: missing node %q", l.ZNode)
+	}
+	return "", TerminalNotExposedError{Node: nodeName}
+}
+
+// nodeConfigs returns the startup config of each node found in cms keyed by
+// node name. The config is read from the config_file key of the ConfigMap
+// referenced by the node config, or of the ConfigMap created for the node.
+func nodeConfigs(nodes []*tpb.Node, cms map[string]*corev1.ConfigMap) map[string]string {
+	configs := map[string]string{}
+	for _, n := range nodes {
+		name := n.GetConfig().GetConfigMapRef()
+		if name == "" {
+			name = fmt.Sprintf("%s-config", n.GetName())
 		}
-		zInt, ok := zNode.Interfaces[l.ZInt]
+		cm, ok := cms[name]
 		if !ok {
-			zInt = &tpb.Interface{
-				IntName: l.ZInt,
-			}
-			zNode.Interfaces[l.ZInt] = zInt
-		}
-		if aInt.PeerName != "" {
-			return fmt.Errorf("interface %s:%s already connected", l.ANode, l.AInt)
+			continue
 		}
-		if zInt.PeerName != "" {
-			return fmt.Errorf("interface %s:%s already connected", l.ZNode, l.ZInt)
+		if data, ok := cm.Data[n.GetConfig().GetConfigFile()]; ok {
+			configs[n.GetName()] = data
 		}
-		aInt.PeerName = l.ZNode
-		aInt.PeerIntName = l.ZInt
-		aInt.Uid = int64(uid)
-		zInt.PeerName = l.ANode
-		zInt.PeerIntName = l.AInt
-		zInt.Uid = int64(uid)
-		uid++
 	}
-	for k, n := range nMap {
-		log.Infof("Adding Node: %s:%s", n.Name, n.Vendor)
-		nn, err := node.New(m.topo.Name, n, m.kClient, m.rCfg, m.basePath, m.kubecfg)
-		if err != nil {
-			return fmt.Errorf("failed to load topology: %w", err)
-		}
-		m.nodes[k] = nn
+	return configs
+}
+
+// WatchHandler is called for each event received while watching the meshnet
+// topology resources.
+type WatchHandler func(eventType watch.EventType, obj runtime.Object)
+
+// WithUpsert makes pushing the topology, such as by Create, re-entrant. The
+// meshnet topologies which already exist are updated and the nodes which
+// already have pods have their service and config updated instead of failing,
+// such as to complete a partially created topology. The pods of existing
+// nodes are left as they are.
+func WithUpsert(b bool) Option {
+	return func(m *Manager) {
+		m.upsert = b
 	}
-	return nil
 }
 
-// setLinkPeer finds the peer pod name and peer interface name for a given interface.
-func setLinkPeer(nodeName string, podName string, link *topologyv1.Link, peerSpecs []*topologyv1.Topology) error {
-	for _, peerSpec := range peerSpecs {
+// WithUpgradeTimeout sets how long UpgradeNode waits for the upgraded node to
+// be ready before rolling back to the previous image. The default is 5
+// minutes.
+func WithUpgradeTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		m.upgradeTimeout = d
+	}
+}
+
+// WithStrictLinks makes Create fail if ValidateLinks returns any warnings
+// for the topology instead of only logging them.
+func WithStrictLinks(b bool) Option {
+	return func(m *Manager) {
+		m.strictLinks = b
+	}
+}
+
+// Formats in which Watch prints events.
+const (
+	WatchFormatText = "text"
+	WatchFormatJSON = "json"
+)
+
+// WithWatchFormat sets the format Watch prints events in, WatchFormatText or
+// WatchFormatJSON. The default is WatchFormatText.
+func WithWatchFormat(f string) Option {
+	return func(m *Manager) {
+		m.watchFormat = f
+	}
+}
+
+// Watch prints the meshnet topology resource events to stdout in the format
+// set by WithWatchFormat.
+func (m *Manager) Watch(ctx context.Context) error {
+	handler, err := watchHandler(os.Stdout, m.watchFormat)
+	if err != nil {
+		return err
+	}
+	return m.WatchWithHandler(ctx, handler)
+}
+
+// watchEvent is a meshnet topology resource event printed by Watch as JSON.
+type watchEvent struct {
+	Type      watch.EventType `json:"type"`
+	Name      string          `json:"name"`
+	Phase     string          `json:"phase"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// watchHandler returns a WatchHandler writing the events to w in format.
+func watchHandler(w io.Writer, format string) (WatchHandler, error) {
+	switch format {
+	case "", WatchFormatText:
+		return func(eventType watch.EventType, obj runtime.Object) {
+			fmt.Fprintln(w, eventType)
+			fmt.Fprintf(w, "%# v", pretty.Formatter(obj))
+			fmt.Fprintln(w, "")
+		}, nil
+	case WatchFormatJSON:
+		enc := json.NewEncoder(w)
+		return func(eventType watch.EventType, obj runtime.Object) {
+			t, err := decodeTopology(obj)
+			if err != nil {
+				log.Warningf("Skipping %s event: %v", eventType, err)
+				return
+			}
+			// meshnet sets the container of a topology once its pod has
+			// been wired up.
+			phase := "Pending"
+			if t.Status.ContainerID != "" {
+				phase = "Running"
+			}
+			if err := enc.Encode(watchEvent{Type: eventType, Name: t.Name, Phase: phase, Timestamp: time.Now().UTC()}); err != nil {
+				log.Warningf("Failed to write %s event: %v", eventType, err)
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown watch format %q", format)
+	}
+}
+
+// WatchWithHandler calls handler, in order, for each meshnet topology resource
+// event until the watch is closed.
+func (m *Manager) WatchWithHandler(ctx context.Context, handler WatchHandler) error {
+	watcher, err := m.tClient.Topology(m.namespace()).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+	for e := range watcher.ResultChan() {
+		handler(e.Type, e.Object)
+	}
+	return nil
+}
+
+// Nodes returns a map of node names to implementations in the current topology.
+func (m *Manager) Nodes() map[string]node.Node {
+	return m.nodes
+}
+
+// NodeFilter selects nodes by their proto fields. Zero fields match all
+// nodes and the set fields must all match.
+type NodeFilter struct {
+	Vendor tpb.Vendor
+	Type   tpb.Node_Type
+	// Labels must all be set on the node with the same values.
+	Labels map[string]string
+}
+
+func (f NodeFilter) match(pb *tpb.Node) bool {
+	if f.Vendor != tpb.Vendor_UNKNOWN && pb.GetVendor() != f.Vendor {
+		return false
+	}
+	if f.Type != tpb.Node_UNKNOWN && pb.GetType() != f.Type {
+		return false
+	}
+	for k, v := range f.Labels {
+		if l, ok := pb.GetLabels()[k]; !ok || l != v {
+			return false
+		}
+	}
+	return true
+}
+
+// FindNodes returns the nodes matching filter sorted by node name.
+func (m *Manager) FindNodes(filter NodeFilter) []node.Node {
+	var nodes []node.Node
+	for _, n := range m.nodes {
+		if filter.match(n.GetProto()) {
+			nodes = append(nodes, n)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name() < nodes[j].Name() })
+	return nodes
+}
+
+// TopologySize holds the counts of the resources in a topology.
+type TopologySize struct {
+	Nodes    int
+	Links    int
+	Services int
+	// Vendors are the distinct vendors of the nodes in ascending order.
+	Vendors []tpb.Vendor
+}
+
+// TopologySize returns the size of the topology proto. Unlike Resources it
+// does not query the cluster.
+func (m *Manager) TopologySize() TopologySize {
+	s := TopologySize{
+		Nodes: len(m.topo.GetNodes()),
+		Links: len(m.topo.GetLinks()),
+	}
+	vendors := map[tpb.Vendor]bool{}
+	for _, n := range m.topo.GetNodes() {
+		s.Services += len(n.GetServices())
+		if !vendors[n.GetVendor()] {
+			vendors[n.GetVendor()] = true
+			s.Vendors = append(s.Vendors, n.GetVendor())
+		}
+	}
+	sort.Slice(s.Vendors, func(i, j int) bool { return s.Vendors[i] < s.Vendors[j] })
+	return s
+}
+
+// Validate checks the topology for duplicate node names, links to nodes
+// that do not exist, links connecting an interface to itself, interfaces
+// used by more than one link and node ports requested by more than one node.
+// All violations found are returned together.
+func (m *Manager) Validate() error {
+	var errs errlist.List
+	nodes := map[string]bool{}
+	for _, n := range m.topo.Nodes {
+		if nodes[n.Name] {
+			errs.Add(fmt.Errorf("invalid topology: duplicate node %q", n.Name))
+		}
+		nodes[n.Name] = true
+	}
+	ints := map[string]bool{}
+	for _, l := range m.topo.Links {
+		if l.ANode == l.ZNode && l.AInt == l.ZInt {
+			errs.Add(fmt.Errorf("invalid link: interface %s:%s connected to itself", l.ANode, l.AInt))
+			continue
+		}
+		for _, e := range [][2]string{{l.ANode, l.AInt}, {l.ZNode, l.ZInt}} {
+			if !nodes[e[0]] {
+				errs.Add(fmt.Errorf("invalid topology: missing node %q", e[0]))
+				continue
+			}
+			k := e[0] + ":" + e[1]
+			// Wildcard interfaces are assigned distinct interfaces by load.
+			if strings.HasSuffix(e[1], "*") {
+				continue
+			}
+			if ints[k] {
+				errs.Add(fmt.Errorf("interface %s already connected", k))
+			}
+			ints[k] = true
+		}
+	}
+	if err := node.ValidateNodePorts(m.topo.Nodes); err != nil {
+		errs.Add(fmt.Errorf("invalid topology: %w", err))
+	}
+	return errs.Err()
+}
+
+// load populates the internal fields of the topology proto.
+func (m *Manager) load() error {
+	if err := expandWildcards(m.topo); err != nil {
+		return err
+	}
+	nMap := map[string]*tpb.Node{}
+	for _, n := range m.topo.Nodes {
+		if len(n.Interfaces) == 0 {
+			n.Interfaces = map[string]*tpb.Interface{}
+		}
+		for k := range n.Interfaces {
+			if n.Interfaces[k].IntName == "" {
+				n.Interfaces[k].IntName = k
+			}
+		}
+		nMap[n.Name] = n
+	}
+	uid := 0
+	for _, l := range m.topo.Links {
+		log.Infof("Adding Link: %s:%s %s:%s", l.ANode, l.AInt, l.ZNode, l.ZInt)
+		if l.ANode == l.ZNode {
+			return fmt.Errorf("invalid link: hardware loopback %s:%s %s:%s not supported", l.ANode, l.AInt, l.ZNode, l.ZInt)
+		}
+		aNode, ok := nMap[l.ANode]
+		if !ok {
+			return fmt.Errorf("invalid topology: missing node %q", l.ANode)
+		}
+		aInt, ok := aNode.Interfaces[l.AInt]
+		if !ok {
+			aInt = &tpb.Interface{
+				IntName: l.AInt,
+			}
+			aNode.Interfaces[l.AInt] = aInt
+		}
+		zNode, ok := nMap[l.ZNode]
+		if !ok {
+			return fmt.Errorf("invalid topology: missing node %q", l.ZNode)
+		}
+		zInt, ok := zNode.Interfaces[l.ZInt]
+		if !ok {
+			zInt = &tpb.Interface{
+				IntName: l.ZInt,
+			}
+			zNode.Interfaces[l.ZInt] = zInt
+		}
+		if aInt.PeerName != "" {
+			return fmt.Errorf("interface %s:%s already connected", l.ANode, l.AInt)
+		}
+		if zInt.PeerName != "" {
+			return fmt.Errorf("interface %s:%s already connected", l.ZNode, l.ZInt)
+		}
+		aInt.PeerName = l.ZNode
+		aInt.PeerIntName = l.ZInt
+		aInt.Uid = int64(uid)
+		zInt.PeerName = l.ANode
+		zInt.PeerIntName = l.AInt
+		zInt.Uid = int64(uid)
+		if err := applyLinkConfig(l, aInt, zInt); err != nil {
+			return err
+		}
+		uid++
+	}
+	for k, n := range nMap {
+		log.Infof("Adding Node: %s:%s", n.Name, n.Vendor)
+		m.applyNodeDefaults(n)
+		nn, err := m.newNode(n, m.kClient, m.rCfg)
+		if err != nil {
+			return fmt.Errorf("failed to load topology: %w", err)
+		}
+		m.nodes[k] = nn
+	}
+	return nil
+}
+
+// applyNodeDefaults merges the vendor defaults, labels and default resources
+// of the manager into n without overriding the fields n sets.
+func (m *Manager) applyNodeDefaults(n *tpb.Node) {
+	if d, ok := m.nodeDefaults[n.Vendor]; ok {
+		mergeDefaults(n.ProtoReflect(), proto.Clone(d).ProtoReflect())
+	}
+	for lk, lv := range m.labels {
+		if n.Labels == nil {
+			n.Labels = map[string]string{}
+		}
+		if _, ok := n.Labels[lk]; !ok {
+			n.Labels[lk] = lv
+		}
+	}
+	if m.defaultResources != nil {
+		for ck, cv := range node.ToConstraints(*m.defaultResources) {
+			if n.Constraints == nil {
+				n.Constraints = map[string]string{}
+			}
+			if _, ok := n.Constraints[ck]; !ok {
+				n.Constraints[ck] = cv
+			}
+		}
+	}
+}
+
+// maxWildcardInterfaces is the highest interface number assigned to a
+// wildcard link endpoint of a node which does not declare its interfaces.
+const maxWildcardInterfaces = 256
+
+// expandWildcards replaces the link endpoint interfaces ending in "*", such
+// as "eth*", with the next interface with the prefix and a number which is not
+// used by another link of the node, in link order. If the node declares
+// interfaces with the prefix only those are assigned, otherwise the numbers
+// 1 to maxWildcardInterfaces are used.
+func expandWildcards(t *tpb.Topology) error {
+	nodes := map[string]*tpb.Node{}
+	for _, n := range t.Nodes {
+		nodes[n.Name] = n
+	}
+	used := map[string]map[string]bool{}
+	for _, l := range t.Links {
+		for _, e := range [][2]string{{l.ANode, l.AInt}, {l.ZNode, l.ZInt}} {
+			if strings.HasSuffix(e[1], "*") {
+				continue
+			}
+			if used[e[0]] == nil {
+				used[e[0]] = map[string]bool{}
+			}
+			used[e[0]][e[1]] = true
+		}
+	}
+	expand := func(nodeName, intf string) (string, error) {
+		prefix := strings.TrimSuffix(intf, "*")
+		var slots []string
+		if n, ok := nodes[nodeName]; ok && len(n.Interfaces) > 0 {
+			nums := map[string]int{}
+			for k := range n.Interfaces {
+				if i, err := strconv.Atoi(strings.TrimPrefix(k, prefix)); err == nil && strings.HasPrefix(k, prefix) {
+					slots = append(slots, k)
+					nums[k] = i
+				}
+			}
+			sort.Slice(slots, func(i, j int) bool { return nums[slots[i]] < nums[slots[j]] })
+		}
+		if len(slots) == 0 {
+			for i := 1; i <= maxWildcardInterfaces; i++ {
+				slots = append(slots, fmt.Sprintf("%s%d", prefix, i))
+			}
+		}
+		if used[nodeName] == nil {
+			used[nodeName] = map[string]bool{}
+		}
+		for _, s := range slots {
+			if !used[nodeName][s] {
+				used[nodeName][s] = true
+				return s, nil
+			}
+		}
+		return "", fmt.Errorf("no interface available for %s:%s", nodeName, intf)
+	}
+	for _, l := range t.Links {
+		var err error
+		if strings.HasSuffix(l.AInt, "*") {
+			if l.AInt, err = expand(l.ANode, l.AInt); err != nil {
+				return err
+			}
+		}
+		if strings.HasSuffix(l.ZInt, "*") {
+			if l.ZInt, err = expand(l.ZNode, l.ZInt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newNode creates the node for pb using the provided clients. The node
+// factory is used if set, otherwise the implementation registered for the
+// vendor of pb.
+func (m *Manager) newNode(pb *tpb.Node, kClient kubernetes.Interface, rCfg *rest.Config) (node.Node, error) {
+	if m.nodeFactory == nil {
+		return node.New(m.namespace(), pb, kClient, rCfg, m.basePath, m.kubecfg, node.WithServiceAccount(m.serviceAccount), node.WithPodSecurityContext(m.podSecurityContext), node.WithImagePullSecrets(m.imagePullSecrets))
+	}
+	return m.nodeFactory(&node.Impl{
+		Namespace:          m.namespace(),
+		Proto:              pb,
+		KubeClient:         kClient,
+		RestConfig:         rCfg,
+		BasePath:           m.basePath,
+		Kubecfg:            m.kubecfg,
+		ServiceAccount:     m.serviceAccount,
+		PodSecurityContext: m.podSecurityContext,
+		ImagePullSecrets:   m.imagePullSecrets,
+	})
+}
+
+// applyLinkConfig sets the MTU and VLAN of the link config of l on the
+// endpoint interfaces so node implementations can act on them. Interfaces
+// which already set a different value are an error.
+func applyLinkConfig(l *tpb.Link, ints ...*tpb.Interface) error {
+	cfg := l.GetConfig()
+	if cfg == nil {
+		return nil
+	}
+	if cfg.GetMtu() < 0 || cfg.GetVlanId() < 0 {
+		return fmt.Errorf("invalid link config %s:%s %s:%s: mtu and vlan_id must not be negative", l.ANode, l.AInt, l.ZNode, l.ZInt)
+	}
+	for _, intf := range ints {
+		if mtu := uint32(cfg.GetMtu()); mtu != 0 {
+			if intf.Mtu != 0 && intf.Mtu != mtu {
+				return fmt.Errorf("interface %s mtu %d conflicts with link mtu %d", intf.IntName, intf.Mtu, mtu)
+			}
+			intf.Mtu = mtu
+		}
+		if vlan := cfg.GetVlanId(); vlan != 0 {
+			if intf.VlanId != 0 && intf.VlanId != vlan {
+				return fmt.Errorf("interface %s vlan %d conflicts with link vlan %d", intf.IntName, intf.VlanId, vlan)
+			}
+			intf.VlanId = vlan
+		}
+	}
+	return nil
+}
+
+// mergeDefaults sets the fields of defaults which are unset in dst. Messages
+// set in both are merged recursively and missing map entries are added. Lists
+// and oneofs set in dst are left untouched. Values of defaults are shared with
+// dst so callers must pass a copy.
+func mergeDefaults(dst, defaults protoreflect.Message) {
+	defaults.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if od := fd.ContainingOneof(); od != nil && dst.WhichOneof(od) != nil && dst.WhichOneof(od) != fd {
+			return true
+		}
+		if !dst.Has(fd) {
+			dst.Set(fd, v)
+			return true
+		}
+		switch {
+		case fd.IsMap():
+			dm := dst.Mutable(fd).Map()
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				if !dm.Has(k) {
+					dm.Set(k, mv)
+				}
+				return true
+			})
+		case fd.IsList():
+			// Lists set in dst replace the defaults.
+		case fd.Message() != nil:
+			mergeDefaults(dst.Mutable(fd).Message(), v.Message())
+		}
+		return true
+	})
+}
+
+// setLinkPeer finds the peer pod name and peer interface name for a given interface.
+func setLinkPeer(nodeName string, podName string, link *topologyv1.Link, peerSpecs []*topologyv1.Topology) error {
+	for _, peerSpec := range peerSpecs {
 		for _, peerLink := range peerSpec.Spec.Links {
 			// make sure self ifc and peer ifc belong to same link (and hence UID) but are not the same interfaces
 			if peerLink.UID == link.UID && !(nodeName == link.PeerPod && peerLink.LocalIntf == link.LocalIntf) {
@@ -531,193 +1559,2277 @@ func (m *Manager) topologySpecs(ctx context.Context) ([]*topologyv1.Topology, er
 	return topos, nil
 }
 
-// push deploys the topology to the cluster.
-func (m *Manager) push(ctx context.Context) error {
-	if _, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.topo.Name, metav1.GetOptions{}); err != nil {
-		log.Infof("Creating namespace for topology: %q", m.topo.Name)
-		ns := &corev1.Namespace{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: m.topo.Name,
-			},
-		}
-		sNs, err := m.kClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to create namespace %q: %w", ns, err)
-		}
-		log.Infof("Server Namespace: %+v", sNs)
-	}
+// namespace returns the name of the namespace of the topology, which is the
+// name of the topology with the resource prefix prepended.
+func (m *Manager) namespace() string {
+	return m.resourcePrefix + m.topo.GetName()
+}
 
-	if err := m.createMeshnetTopologies(ctx); err != nil {
-		return fmt.Errorf("failed to create meshnet topologies: %w", err)
+// namespaceResource returns the namespace resource for the topology.
+func (m *Manager) namespaceResource() *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        m.namespace(),
+			Labels:      m.labels,
+			Annotations: m.annotations,
+		},
 	}
+}
 
-	log.Infof("Creating Node Pods")
-	for _, n := range m.nodes {
-		if err := n.Create(ctx); err != nil {
-			return fmt.Errorf("failed to create node %s: %w", n, err)
-		}
-		log.Infof("Node %s resource created", n)
+// DryRun writes the resources that Create would apply to the cluster to w as
+// a multi-document YAML stream. The resources are rendered from the specs of
+// the nodes without using the cluster clients so no changes are made to the
+// cluster. The pods of nodes which are created by a vendor controller cannot
+// be rendered and are left out. If w is nil, os.Stdout is used.
+func (m *Manager) DryRun(ctx context.Context, w io.Writer) error {
+	if w == nil {
+		w = os.Stdout
 	}
+	var cms []*corev1.ConfigMap
+	var svcs []*corev1.Service
+	var pods []*corev1.Pod
 	for _, n := range m.nodes {
-		err := m.GenerateSelfSigned(ctx, n.Name())
+		pod, err := n.PodSpec(ctx)
 		switch {
+		case status.Code(err) == codes.Unimplemented:
+			log.Infof("Skipping pod of node %s: %v", n.Name(), err)
+		case err != nil:
+			return fmt.Errorf("failed to render node %s: %w", n, err)
 		default:
-			return fmt.Errorf("failed to generate cert for node %s: %w", n, err)
-		case err == nil, status.Code(err) == codes.Unimplemented:
+			pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+			pod.Namespace = m.namespace()
+			pods = append(pods, pod)
+		}
+		ncms, err := n.ConfigMaps()
+		if err != nil {
+			return fmt.Errorf("failed to render config of node %s: %w", n, err)
+		}
+		for _, cm := range ncms {
+			cm.Namespace = m.namespace()
+			cms = append(cms, cm)
+		}
+		if len(n.GetProto().GetServices()) != 0 {
+			svc := node.DefaultService(n.GetProto())
+			svc.Namespace = m.namespace()
+			svcs = append(svcs, svc)
 		}
 	}
-	return nil
-}
-
-// createMeshnetTopologies creates meshnet resources for all available nodes.
-func (m *Manager) createMeshnetTopologies(ctx context.Context) error {
-	log.Infof("Getting topology specs for namespace %s", m.topo.Name)
-	topologies, err := m.topologySpecs(ctx)
+	topos, err := m.topologySpecs(ctx)
 	if err != nil {
-		return fmt.Errorf("could not get meshnet topologies: %v", err)
+		return fmt.Errorf("failed to render meshnet topologies: %w", err)
 	}
-	log.V(2).Infof("Got topology specs for namespace %s: %+v", m.topo.Name, topologies)
-	for _, t := range topologies {
-		log.Infof("Creating topology for meshnet node %s", t.ObjectMeta.Name)
-		sT, err := m.tClient.Topology(m.topo.Name).Create(ctx, t, metav1.CreateOptions{})
+	sort.Slice(cms, func(i, j int) bool { return cms[i].Name < cms[j].Name })
+	sort.Slice(svcs, func(i, j int) bool { return svcs[i].Name < svcs[j].Name })
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	sort.Slice(topos, func(i, j int) bool { return topos[i].Name < topos[j].Name })
+
+	ns := m.namespaceResource()
+	ns.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"}
+	objs := []runtime.Object{ns}
+	for _, cm := range cms {
+		objs = append(objs, cm)
+	}
+	for _, svc := range svcs {
+		objs = append(objs, svc)
+	}
+	for _, pod := range pods {
+		objs = append(objs, pod)
+	}
+	for _, t := range topos {
+		t.TypeMeta = metav1.TypeMeta{APIVersion: topologyv1.SchemeGroupVersion.String(), Kind: "Topology"}
+		t.Namespace = m.namespace()
+		objs = append(objs, t)
+	}
+	for _, o := range objs {
+		b, err := yaml.Marshal(o)
 		if err != nil {
-			return fmt.Errorf("could not create topology for meshnet node %s: %v", t.ObjectMeta.Name, err)
+			return fmt.Errorf("failed to marshal %T: %w", o, err)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+			return err
 		}
-		log.V(1).Infof("Meshnet Node:\n%+v\n", sT)
 	}
 	return nil
 }
 
-// deleteMeshnetTopologies deletes meshnet resources for all available nodes.
-func (m *Manager) deleteMeshnetTopologies(ctx context.Context) error {
-	nodes, err := m.topologyResources(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get meshnet nodes: %w", err)
-	}
-	var errs errlist.List
-	for _, n := range nodes {
-		if err := m.tClient.Topology(m.topo.Name).Delete(ctx, n.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil {
-			errs.Add(fmt.Errorf("failed to delete meshnet node %q: %w", n.ObjectMeta.Name, err))
-		}
+// push deploys the topology to the cluster.
+func (m *Manager) push(ctx context.Context) error {
+	if _, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.namespace(), metav1.GetOptions{}); err != nil {
+		log.Infof("Creating namespace for topology: %q", m.namespace())
+		ns := m.namespaceResource()
+		sNs, err := m.kClient.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create namespace %q: %w", ns, err)
+		}
+		log.Infof("Server Namespace: %+v", sNs)
+	}
+
+	if err := m.createServiceAccount(ctx); err != nil {
+		return err
+	}
+
+	if err := m.createImagePullSecret(ctx); err != nil {
+		return err
+	}
+
+	if err := m.createMeshnetTopologies(ctx); err != nil {
+		return fmt.Errorf("failed to create meshnet topologies: %w", err)
+	}
+
+	if err := m.createConfigMaps(ctx); err != nil {
+		return err
+	}
+
+	log.Infof("Creating Node Pods")
+	if err := m.createNodes(ctx); err != nil {
+		return err
+	}
+	return m.GenerateCerts(ctx)
+}
+
+// createConfigMaps creates the ConfigMaps referenced by the config_map_ref of
+// the node configs from their config data. Nodes without config data require
+// the referenced ConfigMap to already exist in the topology namespace.
+func (m *Manager) createConfigMaps(ctx context.Context) error {
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c := m.kClient.CoreV1().ConfigMaps(m.namespace())
+	for _, name := range names {
+		cfg := m.nodes[name].GetProto().GetConfig()
+		ref := cfg.GetConfigMapRef()
+		if ref == "" {
+			continue
+		}
+		var data []byte
+		switch v := cfg.GetConfigData().(type) {
+		case *tpb.Config_File:
+			b, err := os.ReadFile(filepath.Join(m.basePath, v.File))
+			if err != nil {
+				return fmt.Errorf("failed to read config of node %q: %w", name, err)
+			}
+			data = b
+		case *tpb.Config_Data:
+			data = v.Data
+		}
+		if data == nil {
+			if _, err := c.Get(ctx, ref, metav1.GetOptions{}); err != nil {
+				return fmt.Errorf("failed to get config map %q of node %q: %w", ref, name, err)
+			}
+			continue
+		}
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   ref,
+				Labels: m.labels,
+			},
+			Data: map[string]string{
+				cfg.GetConfigFile(): string(data),
+			},
+		}
+		_, err := c.Create(ctx, cm, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			_, err = c.Update(ctx, cm, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create config map %q of node %q: %w", ref, name, err)
+		}
+		log.Infof("Created config map %q for node %q", ref, name)
+	}
+	return nil
+}
+
+// createServiceAccount creates the service account set by WithServiceAccount
+// in the topology namespace if it does not already exist.
+func (m *Manager) createServiceAccount(ctx context.Context) error {
+	if m.serviceAccount == "" {
+		return nil
+	}
+	_, err := m.kClient.CoreV1().ServiceAccounts(m.namespace()).Get(ctx, m.serviceAccount, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return nil
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get service account %q: %w", m.serviceAccount, err)
+	}
+	log.Infof("Creating service account %q", m.serviceAccount)
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   m.serviceAccount,
+			Labels: m.labels,
+		},
+	}
+	if _, err := m.kClient.CoreV1().ServiceAccounts(m.namespace()).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create service account %q: %w", m.serviceAccount, err)
+	}
+	return nil
+}
+
+// dockerConfigJSON returns the content of a kubernetes.io/dockerconfigjson
+// secret holding c.
+func (c *RegistryCredential) dockerConfigJSON() ([]byte, error) {
+	type auth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	return json.Marshal(map[string]map[string]auth{
+		"auths": {
+			c.Server: {
+				Username: c.Username,
+				Password: c.Password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Password)),
+			},
+		},
+	})
+}
+
+// createImagePullSecret stores the registry credential, if set, in the first
+// image pull secret, creating the secret if it does not already exist.
+func (m *Manager) createImagePullSecret(ctx context.Context) error {
+	if m.registryCredential == nil {
+		return nil
+	}
+	if len(m.imagePullSecrets) == 0 {
+		return fmt.Errorf("registry credential for %q requires an image pull secret", m.registryCredential.Server)
+	}
+	name := m.imagePullSecrets[0].Name
+	b, err := m.registryCredential.dockerConfigJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode registry credential: %w", err)
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: m.labels,
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: b},
+	}
+	c := m.kClient.CoreV1().Secrets(m.namespace())
+	if _, err := c.Create(ctx, s, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create image pull secret %q: %w", name, err)
+		}
+		if _, err := c.Update(ctx, s, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update image pull secret %q: %w", name, err)
+		}
+		log.Infof("Updated image pull secret %q", name)
+		return nil
+	}
+	log.Infof("Created image pull secret %q", name)
+	return nil
+}
+
+// createNodes creates the resources of all nodes, creating up to
+// m.parallelism nodes concurrently. The first failure cancels the creation
+// of the remaining nodes.
+func (m *Manager) createNodes(ctx context.Context) error {
+	if m.parallelism < 2 {
+		for _, n := range m.nodes {
+			if err := m.createNode(ctx, n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(m.parallelism)
+	for _, n := range m.nodes {
+		n := n
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+			return m.createNode(gCtx, n)
+		})
+	}
+	return g.Wait()
+}
+
+// createNode creates the resources of n. If upsert is set and n already has
+// pods, the service and config of n are updated instead.
+func (m *Manager) createNode(ctx context.Context, n node.Node) error {
+	if m.upsert {
+		pods, err := nodePods(ctx, n)
+		if err != nil {
+			return fmt.Errorf("failed to get pods for node %s: %w", n, err)
+		}
+		if len(pods) != 0 {
+			a, ok := n.(node.ResourceApplier)
+			if !ok {
+				log.Infof("Node %s already exists, skipping", n)
+				return nil
+			}
+			if err := a.ApplyConfig(ctx); err != nil {
+				return fmt.Errorf("failed to update config of node %s: %w", n, err)
+			}
+			if err := a.ApplyService(ctx); err != nil {
+				return fmt.Errorf("failed to update service of node %s: %w", n, err)
+			}
+			log.Infof("Node %s already exists, updated its service and config", n)
+			return nil
+		}
+	}
+	if err := n.Create(ctx); err != nil {
+		return fmt.Errorf("failed to create node %s: %w", n, err)
+	}
+	log.Infof("Node %s resource created", n)
+	return nil
+}
+
+// createMeshnetTopologies creates meshnet resources for all available nodes.
+func (m *Manager) createMeshnetTopologies(ctx context.Context) error {
+	log.Infof("Getting topology specs for namespace %s", m.namespace())
+	topologies, err := m.topologySpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get meshnet topologies: %v", err)
+	}
+	log.V(2).Infof("Got topology specs for namespace %s: %+v", m.namespace(), topologies)
+	for _, t := range topologies {
+		log.Infof("Creating topology for meshnet node %s", t.ObjectMeta.Name)
+		sT, err := m.tClient.Topology(m.namespace()).Create(ctx, t, metav1.CreateOptions{})
+		if m.upsert && apierrors.IsAlreadyExists(err) {
+			sT, err = m.updateMeshnetTopology(ctx, t)
+		}
+		if err != nil {
+			return fmt.Errorf("could not create topology for meshnet node %s: %v", t.ObjectMeta.Name, err)
+		}
+		log.V(1).Infof("Meshnet Node:\n%+v\n", sT)
+	}
+	return nil
+}
+
+// updateMeshnetTopology replaces the spec of the existing meshnet topology of
+// the same name as t with the spec of t.
+func (m *Manager) updateMeshnetTopology(ctx context.Context, t *topologyv1.Topology) (*topologyv1.Topology, error) {
+	log.Infof("Updating existing topology for meshnet node %s", t.ObjectMeta.Name)
+	c := m.tClient.Topology(m.namespace())
+	cur, err := c.Get(ctx, t.ObjectMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cur.Spec = t.Spec
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cur)
+	if err != nil {
+		return nil, err
+	}
+	return c.Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{})
+}
+
+// Reconnect recreates the link between aNode:aInt and zNode:zInt by deleting
+// and re-creating the meshnet topologies of both nodes, such as after the
+// meshnet daemon has dropped the link. The link may be given in either
+// direction. Meshnet only plumbs the links of a pod when it starts, so the
+// pods of both nodes are restarted and an error is returned if the link is
+// not plumbed into both pods within defaultReconnectTimeout.
+func (m *Manager) Reconnect(ctx context.Context, aNode, aInt, zNode, zInt string) error {
+	var ends []node.Node
+	for _, name := range []string{aNode, zNode} {
+		n, ok := m.nodes[name]
+		if !ok {
+			return fmt.Errorf("node %q not found", name)
+		}
+		ends = append(ends, n)
+	}
+	found := false
+	for _, l := range m.topo.Links {
+		if (l.ANode == aNode && l.AInt == aInt && l.ZNode == zNode && l.ZInt == zInt) ||
+			(l.ANode == zNode && l.AInt == zInt && l.ZNode == aNode && l.ZInt == aInt) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("link %s:%s %s:%s not found", aNode, aInt, zNode, zInt)
+	}
+	uid := int(ends[0].GetProto().GetInterfaces()[aInt].GetUid())
+	if err := m.recreateMeshnetTopologies(ctx, ends...); err != nil {
+		return err
+	}
+	for _, n := range ends {
+		if err := n.Restart(ctx); err != nil {
+			return fmt.Errorf("failed to restart node %q: %w", n.Name(), err)
+		}
+	}
+	wctx, cancel := context.WithTimeout(ctx, defaultReconnectTimeout)
+	defer cancel()
+	if err := m.waitLinkPlumbed(wctx, aNode, zNode, uid); err != nil {
+		return fmt.Errorf("link %s:%s %s:%s was not plumbed: %w", aNode, aInt, zNode, zInt, err)
+	}
+	log.Infof("Reconnected link %s:%s %s:%s", aNode, aInt, zNode, zInt)
+	return nil
+}
+
+// waitLinkPlumbed waits until meshnet has plumbed the link with the provided
+// uid into the pods of both nodes.
+func (m *Manager) waitLinkPlumbed(ctx context.Context, aNode, zNode string, uid int) error {
+	interval := m.pollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		plumbed := true
+		for _, name := range []string{aNode, zNode} {
+			t, err := m.tClient.Topology(m.namespace()).Get(ctx, name, metav1.GetOptions{})
+			switch {
+			case apierrors.IsNotFound(err):
+				plumbed = false
+			case err != nil:
+				return err
+			case !linkPlumbed(t, uid):
+				plumbed = false
+			}
+		}
+		if plumbed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// recreateMeshnetTopologies deletes and re-creates the meshnet topologies of
+// the provided nodes.
+func (m *Manager) recreateMeshnetTopologies(ctx context.Context, ends ...node.Node) error {
+	names := map[string]bool{}
+	for _, n := range ends {
+		specs, err := n.TopologySpecs(ctx)
+		if err != nil {
+			return fmt.Errorf("could not fetch topology specs for node %s: %v", n.Name(), err)
+		}
+		for _, spec := range specs {
+			names[spec.ObjectMeta.Name] = true
+		}
+	}
+	// The peers of the links are only resolved across all nodes.
+	specs, err := m.topologySpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get meshnet topologies: %v", err)
+	}
+	for _, t := range specs {
+		if !names[t.ObjectMeta.Name] {
+			continue
+		}
+		if err := m.tClient.Topology(m.namespace()).Delete(ctx, t.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete meshnet node %q: %w", t.ObjectMeta.Name, err)
+		}
+		if _, err := m.tClient.Topology(m.namespace()).Create(ctx, t, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("could not create topology for meshnet node %s: %w", t.ObjectMeta.Name, err)
+		}
+		log.Infof("Recreated topology for meshnet node %s", t.ObjectMeta.Name)
+	}
+	return nil
+}
+
+// AddNode adds the node pb to the running topology. The meshnet topology and
+// the resources of the node are created before the node is added to the
+// topology proto and Nodes. Interfaces of pb with a peer are linked to the
+// interface of the peer node, which must not already be connected. Meshnet
+// only plumbs the links of a pod when it starts, so the pods of the peers are
+// restarted once the node is created. If the node cannot be created its
+// resources are deleted and the meshnet topologies of the peers are restored.
+func (m *Manager) AddNode(ctx context.Context, pb *tpb.Node) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if pb.GetName() == "" {
+		return fmt.Errorf("node name must not be empty")
+	}
+	if _, ok := m.nodes[pb.GetName()]; ok {
+		return fmt.Errorf("node %q already exists", pb.GetName())
+	}
+	pb = proto.Clone(pb).(*tpb.Node)
+	if pb.Interfaces == nil {
+		pb.Interfaces = map[string]*tpb.Interface{}
+	}
+	var intNames []string
+	for name := range pb.Interfaces {
+		intNames = append(intNames, name)
+	}
+	sort.Strings(intNames)
+	type peerInt struct {
+		n    node.Node
+		name string
+		orig *tpb.Interface
+		intf *tpb.Interface
+	}
+	var peerInts []peerInt
+	var peers []node.Node
+	var links []*tpb.Link
+	seen := map[string]bool{}
+	peerNames := map[string]bool{}
+	uid := m.nextLinkUID()
+	for _, name := range intNames {
+		intf := pb.Interfaces[name]
+		if intf.GetPeerName() == "" {
+			continue
+		}
+		peer, ok := m.nodes[intf.PeerName]
+		if !ok {
+			return fmt.Errorf("peer node %q of interface %s:%s not found", intf.PeerName, pb.Name, name)
+		}
+		if intf.PeerIntName == "" {
+			return fmt.Errorf("interface %s:%s has a peer node but no peer interface", pb.Name, name)
+		}
+		key := intf.PeerName + ":" + intf.PeerIntName
+		orig, ok := peer.GetProto().GetInterfaces()[intf.PeerIntName]
+		if (ok && orig.GetPeerName() != "") || seen[key] {
+			return fmt.Errorf("interface %s already connected", key)
+		}
+		seen[key] = true
+		pIntf := &tpb.Interface{IntName: intf.PeerIntName}
+		if ok {
+			pIntf = proto.Clone(orig).(*tpb.Interface)
+		}
+		intf.Uid = uid
+		pIntf.PeerName, pIntf.PeerIntName, pIntf.Uid = pb.Name, name, uid
+		uid++
+		peerInts = append(peerInts, peerInt{n: peer, name: intf.PeerIntName, orig: orig, intf: pIntf})
+		if !peerNames[intf.PeerName] {
+			peers = append(peers, peer)
+			peerNames[intf.PeerName] = true
+		}
+		links = append(links, &tpb.Link{ANode: pb.Name, AInt: name, ZNode: intf.PeerName, ZInt: intf.PeerIntName})
+	}
+	m.applyNodeDefaults(pb)
+	n, err := m.newNode(pb, m.kClient, m.rCfg)
+	if err != nil {
+		return fmt.Errorf("failed to load node %q: %w", pb.Name, err)
+	}
+	// The peers of the links are only resolved across all nodes so the node
+	// is added before its meshnet topology is created.
+	m.nodes[pb.Name] = n
+	for _, pi := range peerInts {
+		ppb := pi.n.GetProto()
+		if ppb.Interfaces == nil {
+			ppb.Interfaces = map[string]*tpb.Interface{}
+		}
+		ppb.Interfaces[pi.name] = pi.intf
+	}
+	err = m.recreateMeshnetTopologies(ctx, append([]node.Node{n}, peers...)...)
+	if err == nil {
+		if err = n.Create(ctx); err != nil {
+			err = fmt.Errorf("failed to create node %s: %w", pb.Name, err)
+		}
+	}
+	if err != nil {
+		// Roll back the resources of the node and the meshnet topologies of
+		// its peers so the cluster matches the topology again.
+		var errs errlist.List
+		errs.Add(err)
+		if err := n.Delete(ctx); err != nil {
+			errs.Add(fmt.Errorf("failed to delete node %s: %w", pb.Name, err))
+		}
+		specs, err := n.TopologySpecs(ctx)
+		if err != nil {
+			errs.Add(fmt.Errorf("could not fetch topology specs for node %s: %v", pb.Name, err))
+		}
+		for _, t := range specs {
+			if err := m.tClient.Topology(m.namespace()).Delete(ctx, t.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				errs.Add(fmt.Errorf("failed to delete meshnet node %q: %w", t.ObjectMeta.Name, err))
+			}
+		}
+		delete(m.nodes, pb.Name)
+		for _, pi := range peerInts {
+			if pi.orig == nil {
+				delete(pi.n.GetProto().Interfaces, pi.name)
+			} else {
+				pi.n.GetProto().Interfaces[pi.name] = pi.orig
+			}
+		}
+		if len(peers) > 0 {
+			if err := m.recreateMeshnetTopologies(ctx, peers...); err != nil {
+				errs.Add(err)
+			}
+		}
+		return errs.Err()
+	}
+	m.topo.Nodes = append(m.topo.Nodes, pb)
+	m.topo.Links = append(m.topo.Links, links...)
+	log.Infof("Node %q added to topology %q", pb.Name, m.namespace())
+	var errs errlist.List
+	for _, peer := range peers {
+		if err := peer.Restart(ctx); err != nil {
+			errs.Add(fmt.Errorf("failed to restart peer %s to plumb links of node %s: %w", peer.Name(), pb.Name, err))
+		}
+	}
+	return errs.Err()
+}
+
+// AddLink adds the link l between two nodes of the running topology. The
+// meshnet topologies of both nodes are re-created with the link. If they
+// cannot be re-created the interfaces of the nodes are left unchanged.
+func (m *Manager) AddLink(ctx context.Context, l *tpb.Link) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l.GetANode() == l.GetZNode() {
+		return fmt.Errorf("invalid link: hardware loopback %s:%s %s:%s not supported", l.ANode, l.AInt, l.ZNode, l.ZInt)
+	}
+	var ends []node.Node
+	var ints []*tpb.Interface
+	var added []bool
+	for _, e := range [][2]string{{l.GetANode(), l.GetAInt()}, {l.GetZNode(), l.GetZInt()}} {
+		n, ok := m.nodes[e[0]]
+		if !ok {
+			return fmt.Errorf("node %q not found", e[0])
+		}
+		pb := n.GetProto()
+		intf, ok := pb.Interfaces[e[1]]
+		if ok && intf.PeerName != "" {
+			return fmt.Errorf("interface %s:%s already connected", e[0], e[1])
+		}
+		if !ok {
+			intf = &tpb.Interface{IntName: e[1]}
+		}
+		ends = append(ends, n)
+		ints = append(ints, intf)
+		added = append(added, !ok)
+	}
+	l = proto.Clone(l).(*tpb.Link)
+	aInt, zInt := proto.Clone(ints[0]).(*tpb.Interface), proto.Clone(ints[1]).(*tpb.Interface)
+	uid := m.nextLinkUID()
+	aInt.PeerName, aInt.PeerIntName, aInt.Uid = l.ZNode, l.ZInt, uid
+	zInt.PeerName, zInt.PeerIntName, zInt.Uid = l.ANode, l.AInt, uid
+	if err := applyLinkConfig(l, aInt, zInt); err != nil {
+		return err
+	}
+	aPB, zPB := ends[0].GetProto(), ends[1].GetProto()
+	if aPB.Interfaces == nil {
+		aPB.Interfaces = map[string]*tpb.Interface{}
+	}
+	if zPB.Interfaces == nil {
+		zPB.Interfaces = map[string]*tpb.Interface{}
+	}
+	aPB.Interfaces[l.AInt], zPB.Interfaces[l.ZInt] = aInt, zInt
+	if err := m.recreateMeshnetTopologies(ctx, ends...); err != nil {
+		for i, e := range []struct {
+			pb  *tpb.Node
+			int string
+		}{{aPB, l.AInt}, {zPB, l.ZInt}} {
+			if added[i] {
+				delete(e.pb.Interfaces, e.int)
+			} else {
+				e.pb.Interfaces[e.int] = ints[i]
+			}
+		}
+		return err
+	}
+	m.topo.Links = append(m.topo.Links, l)
+	log.Infof("Link %s:%s %s:%s added to topology %q", l.ANode, l.AInt, l.ZNode, l.ZInt, m.namespace())
+	return nil
+}
+
+// nextLinkUID returns a link uid not used by any link of the topology. Links
+// may have been removed so the number of links cannot be used.
+func (m *Manager) nextLinkUID() int64 {
+	var uid int64
+	for _, n := range m.nodes {
+		for _, intf := range n.GetProto().GetInterfaces() {
+			if intf.GetPeerName() != "" && intf.GetUid() >= uid {
+				uid = intf.GetUid() + 1
+			}
+		}
+	}
+	return uid
+}
+
+// RemoveNode removes the node from the running topology, deleting its
+// resources and meshnet topology. A node with links is only removed if force
+// is set, in which case its links are removed from the topology and the
+// meshnet topologies of its peers are re-created without them.
+func (m *Manager) RemoveNode(ctx context.Context, nodeName string, force bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	var links []*tpb.Link
+	peers := map[string]node.Node{}
+	for _, l := range m.topo.Links {
+		if l.ANode == nodeName || l.ZNode == nodeName {
+			links = append(links, l)
+		}
+	}
+	if len(links) > 0 && !force {
+		return fmt.Errorf("node %q has %d links, set force to remove them", nodeName, len(links))
+	}
+	if err := n.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete node %q: %w", nodeName, err)
+	}
+	specs, err := n.TopologySpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch topology specs for node %s: %v", nodeName, err)
+	}
+	for _, t := range specs {
+		if err := m.tClient.Topology(m.namespace()).Delete(ctx, t.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete meshnet node %q: %w", t.ObjectMeta.Name, err)
+		}
+	}
+	var keep []*tpb.Link
+	for _, l := range m.topo.Links {
+		switch {
+		case l.ANode == nodeName && l.ZNode == nodeName:
+		case l.ANode == nodeName:
+			peers[l.ZNode] = m.nodes[l.ZNode]
+			delete(m.nodes[l.ZNode].GetProto().Interfaces, l.ZInt)
+		case l.ZNode == nodeName:
+			peers[l.ANode] = m.nodes[l.ANode]
+			delete(m.nodes[l.ANode].GetProto().Interfaces, l.AInt)
+		default:
+			keep = append(keep, l)
+		}
+	}
+	m.topo.Links = keep
+	var nodes []*tpb.Node
+	for _, pb := range m.topo.Nodes {
+		if pb.GetName() != nodeName {
+			nodes = append(nodes, pb)
+		}
+	}
+	m.topo.Nodes = nodes
+	delete(m.nodes, nodeName)
+	if len(peers) > 0 {
+		var ends []node.Node
+		for _, p := range peers {
+			ends = append(ends, p)
+		}
+		if err := m.recreateMeshnetTopologies(ctx, ends...); err != nil {
+			return fmt.Errorf("failed to remove links of node %q from peers: %w", nodeName, err)
+		}
+	}
+	log.Infof("Node %q removed from topology %q", nodeName, m.namespace())
+	return nil
+}
+
+// SyncFromCluster adds the nodes of the topology which exist in the cluster,
+// such as nodes created by another process, but are not known to m. The
+// nodes are reconstructed from the labels and containers of the pods in the
+// topology namespace. Pods without an app label or with a vendor label which
+// does not name a registered vendor are skipped.
+func (m *Manager) SyncFromCluster(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pods, err := m.kClient.CoreV1().Pods(m.namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of topology %q: %w", m.namespace(), err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	var errs errlist.List
+	for _, p := range pods.Items {
+		name := p.Labels["app"]
+		if name == "" {
+			continue
+		}
+		if _, ok := m.nodes[name]; ok {
+			continue
+		}
+		pb := podNodeProto(name, &p)
+		if pb.Vendor == tpb.Vendor_UNKNOWN {
+			log.Warningf("Skipping pod %q of node %q with unknown vendor %q", p.Name, name, p.Labels["vendor"])
+			continue
+		}
+		n, err := m.newNode(pb, m.kClient, m.rCfg)
+		if err != nil {
+			errs.Add(fmt.Errorf("failed to sync node %q: %w", name, err))
+			continue
+		}
+		m.nodes[name] = n
+		m.topo.Nodes = append(m.topo.Nodes, pb)
+		log.Infof("Synced node %q from pod %q", name, p.Name)
+	}
+	return errs.Err()
+}
+
+// podNodeProto returns the proto of the node name reconstructed from its pod.
+func podNodeProto(name string, p *corev1.Pod) *tpb.Node {
+	pb := &tpb.Node{
+		Name:   name,
+		Vendor: tpb.Vendor(tpb.Vendor_value[p.Labels["vendor"]]),
+		Model:  p.Labels["model"],
+		Os:     p.Labels["os"],
+		Labels: map[string]string{},
+		Config: &tpb.Config{},
+	}
+	for k, v := range p.Labels {
+		if k != "app" && k != "topo" {
+			pb.Labels[k] = v
+		}
+	}
+	for _, c := range p.Spec.Containers {
+		if c.Name == name || pb.Config.Image == "" {
+			pb.Config.Image = c.Image
+		}
+	}
+	return pb
+}
+
+// deleteMeshnetTopologies deletes meshnet resources for all available nodes.
+func (m *Manager) deleteMeshnetTopologies(ctx context.Context) error {
+	nodes, err := m.topologyResources(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get meshnet nodes: %w", err)
+	}
+	var errs errlist.List
+	for _, n := range nodes {
+		if err := m.tClient.Topology(m.namespace()).Delete(ctx, n.ObjectMeta.Name, metav1.DeleteOptions{}); err != nil {
+			errs.Add(fmt.Errorf("failed to delete meshnet node %q: %w", n.ObjectMeta.Name, err))
+		}
+	}
+	return errs.Err()
+}
+
+// CheckNodeStatus waits for up to timeout for all nodes of the topology to be
+// running and ready. A timeout of zero waits without bound.
+func (m *Manager) CheckNodeStatus(ctx context.Context, timeout time.Duration) error {
+	return m.checkNodeStatus(ctx, timeout)
+}
+
+// checkNodeStatus reports node status, ignores for unimplemented nodes.
+// Nodes are polled with an exponential backoff starting at the poll interval
+// and capped at the max poll interval.
+func (m *Manager) checkNodeStatus(ctx context.Context, timeout time.Duration) error {
+	foundAll := false
+	processed := make(map[string]bool)
+	interval := m.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := m.maxPollInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+
+	// Check until end state or timeout sec expired
+	start := time.Now()
+	for (timeout == 0 || time.Since(start) < timeout) && !foundAll {
+		foundAll = true
+		for name, n := range m.nodes {
+			if _, ok := processed[name]; ok {
+				continue
+			}
+
+			phase, err := n.Status(ctx)
+			if m.statusCallback != nil {
+				m.statusCallback(name, string(phase), time.Since(start))
+			}
+			if err != nil || phase == node.StatusFailed {
+				return fmt.Errorf("Node %s: Status %s Reason %v", n, phase, err)
+			}
+			if phase != node.StatusRunning {
+				foundAll = false
+				continue
+			}
+			ready, err := n.Ready(ctx)
+			if err != nil {
+				return fmt.Errorf("Node %s: Status %s Reason %v", n, phase, err)
+			}
+			if ready {
+				var remaining time.Duration
+				if timeout > 0 {
+					if remaining = timeout - time.Since(start); remaining < time.Millisecond {
+						remaining = time.Millisecond
+					}
+				}
+				// Vendors which cannot list the interfaces of their nodes
+				// opt out with an Unimplemented error.
+				if err := n.WaitForInterfaces(ctx, remaining); err != nil && status.Code(err) != codes.Unimplemented {
+					return fmt.Errorf("Node %s: Status %s Reason %v", n, phase, err)
+				}
+				log.Infof("Node %s: Status %s", n, phase)
+				processed[name] = true
+			} else {
+				foundAll = false
+			}
+		}
+		if foundAll {
+			break
+		}
+		sleep(interval)
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+	if !foundAll {
+		log.Warningf("Failed to determine status of some node resources in %d sec", timeout)
+	}
+	return nil
+}
+
+// UnhealthyNodes returns the sorted names of the nodes which are not in the
+// running phase. Unlike checkNodeStatus the status of each node is only
+// queried once.
+func (m *Manager) UnhealthyNodes(ctx context.Context) ([]string, error) {
+	var names []string
+	for name, n := range m.nodes {
+		phase, err := n.Status(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status of node %q: %w", name, err)
+		}
+		if phase != node.StatusRunning {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// HealthScore returns the fraction of nodes in the topology which are in the
+// running phase, from 0.0 to 1.0. A topology without nodes is fully healthy.
+func (m *Manager) HealthScore(ctx context.Context) (float64, error) {
+	if len(m.nodes) == 0 {
+		return 1, nil
+	}
+	unhealthy, err := m.UnhealthyNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return float64(len(m.nodes)-len(unhealthy)) / float64(len(m.nodes)), nil
+}
+
+// NodeHealth is the diagnostic information of the pods of a node.
+type NodeHealth struct {
+	Name         string
+	Phase        corev1.PodPhase
+	RestartCount int32
+	// OOMKilled is set if any container of the node was killed for running
+	// out of memory.
+	OOMKilled bool
+	// LastError describes why a container is waiting or last terminated
+	// with an error, if any.
+	LastError string
+}
+
+// HealthCheck returns the health of each node in the topology, sorted by node
+// name, from the container statuses of its pods. Nodes without pods have the
+// unknown phase.
+func (m *Manager) HealthCheck(ctx context.Context) ([]NodeHealth, error) {
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var health []NodeHealth
+	for _, name := range names {
+		pods, err := nodePods(ctx, m.nodes[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pods for node %q: %w", name, err)
+		}
+		h := NodeHealth{Name: name, Phase: corev1.PodUnknown}
+		for i, p := range pods {
+			if i == 0 {
+				h.Phase = p.Status.Phase
+			}
+			for _, cs := range p.Status.ContainerStatuses {
+				h.RestartCount += cs.RestartCount
+				for _, t := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+					if t != nil && t.Reason == "OOMKilled" {
+						h.OOMKilled = true
+					}
+				}
+				if h.LastError == "" {
+					h.LastError = containerError(cs)
+				}
+			}
+		}
+		health = append(health, h)
+	}
+	return health, nil
+}
+
+// containerError returns why the container is waiting or last terminated
+// with an error, or "" if it did not.
+func containerError(cs corev1.ContainerStatus) string {
+	if w := cs.State.Waiting; w != nil && w.Reason != "" && w.Reason != "ContainerCreating" {
+		if w.Message != "" {
+			return fmt.Sprintf("container %s: %s: %s", cs.Name, w.Reason, w.Message)
+		}
+		return fmt.Sprintf("container %s: %s", cs.Name, w.Reason)
+	}
+	for _, t := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+		if t != nil && t.ExitCode != 0 {
+			return fmt.Sprintf("container %s: %s (exit code %d)", cs.Name, t.Reason, t.ExitCode)
+		}
+	}
+	return ""
+}
+
+type Resources struct {
+	Services   map[string][]*corev1.Service
+	Pods       map[string][]*corev1.Pod
+	ConfigMaps map[string]*corev1.ConfigMap
+	Topologies map[string]*topologyv1.Topology
+	Ingresses  map[string]*networkingv1.Ingress
+}
+
+// Resources gets the currently configured resources from the topology.
+func (m *Manager) Resources(ctx context.Context) (*Resources, error) {
+	return m.resources(ctx, false)
+}
+
+// resources gets the currently configured resources from the topology. If
+// skipMissingServices is set, nodes whose service does not exist are left out
+// of the returned services instead of failing.
+func (m *Manager) resources(ctx context.Context, skipMissingServices bool) (*Resources, error) {
+	r := Resources{
+		Services:   map[string][]*corev1.Service{},
+		Pods:       map[string][]*corev1.Pod{},
+		ConfigMaps: map[string]*corev1.ConfigMap{},
+		Topologies: map[string]*topologyv1.Topology{},
+		Ingresses:  map[string]*networkingv1.Ingress{},
+	}
+
+	for nodeName, n := range m.nodes {
+		pods, err := n.Pods(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("could not get pods for node %s: %v", nodeName, err)
+		}
+		r.Pods[nodeName] = pods
+
+		services, err := n.Services(ctx)
+		switch {
+		case skipMissingServices && apierrors.IsNotFound(err):
+			log.Warningf("Service for node %s not found: %v", nodeName, err)
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("could not get services for node %s: %v", nodeName, err)
+		}
+		r.Services[nodeName] = services
+	}
+
+	cms, err := m.kClient.CoreV1().ConfigMaps(m.namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get config maps: %v", err)
+	}
+	for i := range cms.Items {
+		r.ConfigMaps[cms.Items[i].Name] = &cms.Items[i]
+	}
+
+	tList, err := m.topologyResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tList {
+		r.Topologies[t.Name] = t
+	}
+
+	// Ingresses are optional so a cluster which does not serve them, or does
+	// not allow them to be listed, is treated as having none.
+	ings, err := m.kClient.NetworkingV1().Ingresses(m.namespace()).List(ctx, metav1.ListOptions{})
+	switch {
+	case apierrors.IsForbidden(err), apierrors.IsNotFound(err):
+		log.Warningf("Could not get ingresses, skipping them: %v", err)
+	case err != nil:
+		return nil, fmt.Errorf("could not get ingresses: %v", err)
+	default:
+		for i := range ings.Items {
+			r.Ingresses[ings.Items[i].Name] = &ings.Items[i]
+		}
+	}
+
+	return &r, nil
+}
+
+// Topologies returns the meshnet topology CRDs of the topology.
+func (m *Manager) Topologies(ctx context.Context) ([]topologyv1.Topology, error) {
+	items, err := m.topologyResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	topologies := make([]topologyv1.Topology, len(items))
+	for i, t := range items {
+		topologies[i] = *t
+	}
+	return topologies, nil
+}
+
+// topologyResources gets the topology CRDs for the cluster.
+func (m *Manager) topologyResources(ctx context.Context) ([]*topologyv1.Topology, error) {
+	topology, err := m.tClient.Topology(m.namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topology CRDs: %v", err)
+	}
+
+	items := make([]*topologyv1.Topology, len(topology.Items))
+	for i := range items {
+		items[i] = &topology.Items[i]
+	}
+
+	return items, nil
+}
+
+// LinkState is the operational state of a link in the topology.
+type LinkState struct {
+	UID   int
+	ANode string
+	AInt  string
+	ZNode string
+	ZInt  string
+	// Up is true if meshnet has plumbed both ends of the link.
+	Up bool
+}
+
+// LinkStatus returns the state of each link in the topology keyed by
+// "anode:aint-znode:zint". The UID of each link is the meshnet link UID set on
+// the interfaces of its nodes. A link is reported up when the meshnet
+// topologies of both nodes have been plumbed into their pods and neither side
+// has skipped the link waiting on its peer.
+func (m *Manager) LinkStatus(ctx context.Context) (map[string]LinkState, error) {
+	topologies, err := m.topologyResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tMap := map[string]*topologyv1.Topology{}
+	for _, t := range topologies {
+		tMap[t.Name] = t
+	}
+	links := map[string]LinkState{}
+	for _, l := range m.topo.Links {
+		n, ok := m.nodes[l.ANode]
+		if !ok {
+			return nil, fmt.Errorf("node %q of link %s:%s-%s:%s not found", l.ANode, l.ANode, l.AInt, l.ZNode, l.ZInt)
+		}
+		intf, ok := n.GetProto().GetInterfaces()[l.AInt]
+		if !ok {
+			return nil, fmt.Errorf("interface %s:%s not found", l.ANode, l.AInt)
+		}
+		uid := int(intf.GetUid())
+		links[fmt.Sprintf("%s:%s-%s:%s", l.ANode, l.AInt, l.ZNode, l.ZInt)] = LinkState{
+			UID:   uid,
+			ANode: l.ANode,
+			AInt:  l.AInt,
+			ZNode: l.ZNode,
+			ZInt:  l.ZInt,
+			Up:    linkPlumbed(tMap[l.ANode], uid) && linkPlumbed(tMap[l.ZNode], uid),
+		}
+	}
+	return links, nil
+}
+
+// linkPlumbed returns true if the meshnet topology t has been plumbed into
+// its pod and has not skipped the link with the provided uid.
+func linkPlumbed(t *topologyv1.Topology, uid int) bool {
+	if t == nil || t.Status.NetNS == "" {
+		return false
+	}
+	for _, s := range t.Status.Skipped {
+		if s.LinkId == int64(uid) {
+			return false
+		}
+	}
+	return true
+}
+
+// LinkConflict is a meshnet link UID used by more than one link.
+type LinkConflict struct {
+	UID int
+	// Links are the sorted links sharing the UID, formatted as
+	// "anode:aint-znode:zint" with the endpoints in name order.
+	Links []string
+}
+
+// CheckLinkStatus returns the link UIDs which are shared by different links
+// in the meshnet topologies of the namespace, ordered by UID. Each link is
+// expected to appear with the same UID in the topologies of both of its
+// nodes; any other reuse of a UID causes meshnet to plumb the wrong links.
+func (m *Manager) CheckLinkStatus(ctx context.Context) ([]LinkConflict, error) {
+	topologies, err := m.topologyResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uids := map[int]map[string]bool{}
+	for _, t := range topologies {
+		for _, l := range t.Spec.Links {
+			a := fmt.Sprintf("%s:%s", t.Name, l.LocalIntf)
+			z := fmt.Sprintf("%s:%s", l.PeerPod, l.PeerIntf)
+			if z < a {
+				a, z = z, a
+			}
+			if uids[l.UID] == nil {
+				uids[l.UID] = map[string]bool{}
+			}
+			uids[l.UID][a+"-"+z] = true
+		}
+	}
+	var conflicts []LinkConflict
+	for uid, links := range uids {
+		if len(links) < 2 {
+			continue
+		}
+		c := LinkConflict{UID: uid}
+		for l := range links {
+			c.Links = append(c.Links, l)
+		}
+		sort.Strings(c.Links)
+		conflicts = append(conflicts, c)
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].UID < conflicts[j].UID })
+	return conflicts, nil
+}
+
+// ReconcileAction is a change made by ReconcileServices.
+type ReconcileAction struct {
+	Node    string
+	Service string
+	// Action is "create" or "update".
+	Action string
+}
+
+// ReconcileServices recreates the services of nodes which are missing and
+// updates services whose type or ports no longer match the services in the
+// node proto, such as after a cluster migration. The actions taken are
+// returned in node name order.
+func (m *Manager) ReconcileServices(ctx context.Context) ([]ReconcileAction, error) {
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var actions []ReconcileAction
+	for _, name := range names {
+		pb := m.nodes[name].GetProto()
+		if len(pb.GetServices()) == 0 {
+			continue
+		}
+		want := node.DefaultService(pb)
+		c := m.kClient.CoreV1().Services(m.namespace())
+		cur, err := c.Get(ctx, want.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			if _, err := c.Create(ctx, want, metav1.CreateOptions{}); err != nil {
+				return actions, fmt.Errorf("failed to create service %q: %w", want.Name, err)
+			}
+			log.Infof("Created missing service %q for node %q", want.Name, name)
+			actions = append(actions, ReconcileAction{Node: name, Service: want.Name, Action: "create"})
+			continue
+		case err != nil:
+			return actions, fmt.Errorf("failed to get service %q: %w", want.Name, err)
+		}
+		if cur.Spec.Type == want.Spec.Type && servicePortsMatch(cur.Spec.Ports, want.Spec.Ports) {
+			continue
+		}
+		cur = cur.DeepCopy()
+		cur.Spec.Type = want.Spec.Type
+		cur.Spec.Ports = want.Spec.Ports
+		if _, err := c.Update(ctx, cur, metav1.UpdateOptions{}); err != nil {
+			return actions, fmt.Errorf("failed to update service %q: %w", want.Name, err)
+		}
+		log.Infof("Updated service %q for node %q", want.Name, name)
+		actions = append(actions, ReconcileAction{Node: name, Service: want.Name, Action: "update"})
+	}
+	return actions, nil
+}
+
+// servicePortsMatch returns true if got exposes the same ports as want. Node
+// ports assigned by the cluster are ignored unless set in want.
+func servicePortsMatch(got, want []corev1.ServicePort) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	ports := map[int32]corev1.ServicePort{}
+	for _, p := range got {
+		ports[p.Port] = p
+	}
+	for _, w := range want {
+		p, ok := ports[w.Port]
+		if !ok || p.TargetPort != w.TargetPort || p.Protocol != w.Protocol {
+			return false
+		}
+		if w.NodePort != 0 && p.NodePort != w.NodePort {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNodeService returns the service of the provided node which maps to the
+// inside port servicePort, populated with the current cluster info. If
+// several services map to the port the one with the lowest outside port is
+// returned. A status.NotFound error is returned if the node or the service
+// does not exist.
+func (m *Manager) GetNodeService(ctx context.Context, nodeName string, servicePort uint32) (*tpb.Service, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	services, err := n.Services(ctx)
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, status.Errorf(codes.NotFound, "services for node %q not found", nodeName)
+	case err != nil:
+		return nil, fmt.Errorf("could not get services for node %s: %w", nodeName, err)
+	}
+	sMap := map[uint32]*tpb.Service{}
+	for k, v := range n.GetProto().GetServices() {
+		sMap[k] = proto.Clone(v).(*tpb.Service)
+	}
+	for _, svc := range services {
+		if err := populateServiceMap(svc, sMap); err != nil {
+			return nil, err
+		}
+	}
+	var found *tpb.Service
+	for _, svc := range sMap {
+		if svc.GetInside() != servicePort || svc.GetOutside() == 0 {
+			continue
+		}
+		if found == nil || svc.GetOutside() < found.GetOutside() {
+			found = svc
+		}
+	}
+	if found == nil {
+		return nil, status.Errorf(codes.NotFound, "service with inside port %d not found for node %q", servicePort, nodeName)
+	}
+	return found, nil
+}
+
+// ConfigPush will push config to the provided node. If the node does
+// not fulfill ConfigPusher then status.Unimplemented error will be returned.
+func (m *Manager) ConfigPush(ctx context.Context, nodeName string, r io.Reader) (rerr error) {
+	defer func() { m.record("config_push", rerr) }()
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	cp, ok := n.(node.ConfigPusher)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement ConfigPusher interface", nodeName)
+	}
+	return cp.ConfigPush(ctx, r)
+}
+
+// ApplyConfig pushes the config read from r to all nodes of the provided
+// vendor concurrently. The errors of nodes which failed to be configured,
+// including nodes which do not fulfill ConfigPusher, are returned keyed by
+// node name. An error is returned if the config cannot be read or ctx is
+// done before all pushes complete. A push failing with a context error is
+// fatal and cancels the other pushes.
+func (m *Manager) ApplyConfig(ctx context.Context, vendor tpb.Vendor, r io.Reader) (map[string]error, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	var mu sync.Mutex
+	errs := map[string]error{}
+	g, gCtx := errgroup.WithContext(ctx)
+	for name, n := range m.nodes {
+		if n.GetProto().GetVendor() != vendor {
+			continue
+		}
+		name := name
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+			err := m.ConfigPush(gCtx, name, bytes.NewReader(b))
+			switch {
+			case err == nil:
+			case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+				return fmt.Errorf("failed to push config to node %q: %w", name, err)
+			default:
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return errs, err
+	}
+	if err := ctx.Err(); err != nil {
+		return errs, err
+	}
+	return errs, nil
+}
+
+// PushConfigs pushes the configs to the nodes they are keyed by concurrently.
+// The running config of each node fulfilling ConfigPuller is pulled before
+// any config is pushed. If any push fails, the pulled configs are pushed back
+// to the nodes which were configured successfully. The rollback is best
+// effort: nodes which do not fulfill ConfigPuller are left with the new
+// config and rollback failures are returned along with the push failures.
+func (m *Manager) PushConfigs(ctx context.Context, configs map[string]io.Reader) error {
+	cfgs := map[string][]byte{}
+	for name, r := range configs {
+		n, ok := m.nodes[name]
+		if !ok {
+			return fmt.Errorf("node %q not found", name)
+		}
+		if _, ok := n.(node.ConfigPusher); !ok {
+			return status.Errorf(codes.Unimplemented, "node %q does not implement ConfigPusher interface", name)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read config for node %q: %w", name, err)
+		}
+		cfgs[name] = b
+	}
+	prev := map[string][]byte{}
+	for name := range cfgs {
+		if _, ok := m.nodes[name].(node.ConfigPuller); !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := m.ConfigPull(ctx, name, &buf); err != nil {
+			return fmt.Errorf("failed to pull config of node %q: %w", name, err)
+		}
+		prev[name] = buf.Bytes()
+	}
+	var mu sync.Mutex
+	var errs errlist.List
+	var pushed []string
+	var g errgroup.Group
+	for name, b := range cfgs {
+		name, b := name, b
+		g.Go(func() error {
+			err := m.ConfigPush(ctx, name, bytes.NewReader(b))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs.Add(fmt.Errorf("failed to push config to node %q: %w", name, err))
+				return nil
+			}
+			pushed = append(pushed, name)
+			return nil
+		})
+	}
+	g.Wait()
+	if errs.Err() == nil {
+		return nil
+	}
+	sort.Strings(pushed)
+	for _, name := range pushed {
+		b, ok := prev[name]
+		if !ok {
+			log.Warningf("Unable to roll back config of node %q: node does not implement ConfigPuller interface", name)
+			continue
+		}
+		log.Infof("Rolling back config of node %q", name)
+		if err := m.ConfigPush(ctx, name, bytes.NewReader(b)); err != nil {
+			errs.Add(fmt.Errorf("failed to roll back config of node %q: %w", name, err))
+		}
 	}
 	return errs.Err()
 }
 
-// checkNodeStatus reports node status, ignores for unimplemented nodes.
-func (m *Manager) checkNodeStatus(ctx context.Context, timeout time.Duration) error {
-	foundAll := false
-	processed := make(map[string]bool)
+// NodeVersion returns the software version running on the provided node. If
+// the node fulfills Versioner the version reported by the node is returned,
+// otherwise the tag of the image of the node container is used.
+func (m *Manager) NodeVersion(ctx context.Context, nodeName string) (string, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return "", fmt.Errorf("node %q not found", nodeName)
+	}
+	pods, err := n.Pods(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get pods of node %q: %w", nodeName, err)
+	}
+	if len(pods) == 0 {
+		return "", fmt.Errorf("node %q has no pods", nodeName)
+	}
+	c := nodeContainer(pods[0], n.Name())
+	if c == nil {
+		return "", fmt.Errorf("pod %q of node %q has no containers", pods[0].Name, nodeName)
+	}
+	tag := imageTag(c.Image)
+	v, ok := n.(node.Versioner)
+	if !ok {
+		return tag, nil
+	}
+	version, err := v.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get version of node %q: %w", nodeName, err)
+	}
+	if version == "" {
+		return tag, nil
+	}
+	return version, nil
+}
 
-	// Check until end state or timeout sec expired
-	start := time.Now()
-	for (timeout == 0 || time.Since(start) < timeout) && !foundAll {
-		foundAll = true
-		for name, n := range m.nodes {
-			if _, ok := processed[name]; ok {
+// imageTag returns the tag or digest of the container image reference
+// image, defaulting to "latest".
+func imageTag(image string) string {
+	if i := strings.LastIndex(image, "@"); i != -1 {
+		return image[i+1:]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[i+1:]
+	}
+	return "latest"
+}
+
+// ConfigPull writes the running config of the provided node to w. If the
+// node does not fulfill ConfigPuller then nothing is written and nil is
+// returned.
+func (m *Manager) ConfigPull(ctx context.Context, nodeName string, w io.Writer) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	cp, ok := n.(node.ConfigPuller)
+	if !ok {
+		log.Infof("Node %q does not implement ConfigPuller interface, skipping config pull", nodeName)
+		return nil
+	}
+	return cp.PullConfig(ctx, w)
+}
+
+// BackupAllConfigs writes the saved startup config of each node to
+// dir/<node name>.conf, creating dir if needed. Nodes which implement
+// neither node.Backuper nor node.ConfigPuller are skipped. The errors of all
+// nodes are returned together.
+func (m *Manager) BackupAllConfigs(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory %q: %w", dir, err)
+	}
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs errlist.List
+	for _, name := range names {
+		var b bytes.Buffer
+		err := node.BackupConfig(ctx, m.nodes[name], &b)
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			log.Infof("Node %q does not implement Backuper interface, skipping config backup", name)
+			continue
+		case err != nil:
+			errs.Add(fmt.Errorf("failed to back up config of node %q: %w", name, err))
+			continue
+		}
+		path := filepath.Join(dir, name+".conf")
+		if err := os.WriteFile(path, b.Bytes(), 0644); err != nil {
+			errs.Add(fmt.Errorf("failed to write config of node %q: %w", name, err))
+			continue
+		}
+		log.Infof("Backed up config of node %q to %s", name, path)
+	}
+	return errs.Err()
+}
+
+// ExecCommand runs cmd in the pod of the provided node and returns the
+// command output. If the node does not fulfill Execer then
+// status.Unimplemented error will be returned.
+func (m *Manager) ExecCommand(ctx context.Context, nodeName string, cmd []string) (stdout, stderr string, err error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return "", "", fmt.Errorf("node %q not found", nodeName)
+	}
+	e, ok := n.(node.Execer)
+	if !ok {
+		return "", "", status.Errorf(codes.Unimplemented, "node %q does not implement Execer interface", nodeName)
+	}
+	var outBuf, errBuf bytes.Buffer
+	err = e.Exec(ctx, cmd, nil, &outBuf, &errBuf)
+	return outBuf.String(), errBuf.String(), err
+}
+
+// CopyFile copies the local file srcPath to destPath in the provided node.
+// If the node does not fulfill FileCopier then status.Unimplemented error
+// will be returned.
+func (m *Manager) CopyFile(ctx context.Context, nodeName, srcPath, destPath string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	fc, ok := n.(node.FileCopier)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement FileCopier interface", nodeName)
+	}
+	return fc.CopyFile(ctx, srcPath, destPath)
+}
+
+// NodeLogs returns a stream of the logs of the provided node. For nodes with
+// multiple pods the logs of the first pod are returned. The caller must close
+// the returned reader.
+func (m *Manager) NodeLogs(ctx context.Context, nodeName string, opts corev1.PodLogOptions) (io.ReadCloser, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	pods, err := n.Pods(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("node %q has no pods", nodeName)
+	}
+	return m.kClient.CoreV1().Pods(m.namespace()).GetLogs(pods[0].Name, &opts).Stream(ctx)
+}
+
+// TailNodeLogs returns the last lines of the logs of the provided node.
+func (m *Manager) TailNodeLogs(ctx context.Context, nodeName string, lines int64) (string, error) {
+	r, err := m.NodeLogs(ctx, nodeName, corev1.PodLogOptions{TailLines: &lines})
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs of node %q: %w", nodeName, err)
+	}
+	return string(b), nil
+}
+
+// nodePodNames returns the names of the pods of the provided node. Nodes
+// without pods use the node name, which is the pod name used by node.Impl,
+// so events from failed pod creation are still found.
+func (m *Manager) nodePodNames(ctx context.Context, nodeName string) (map[string]bool, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	pods, err := nodePods(ctx, n)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	names := map[string]bool{}
+	for _, p := range pods {
+		names[p.Name] = true
+	}
+	if len(names) == 0 {
+		names[nodeName] = true
+	}
+	return names, nil
+}
+
+// NodeEvents returns the Kubernetes events of the pods of the provided node.
+func (m *Manager) NodeEvents(ctx context.Context, nodeName string) ([]corev1.Event, error) {
+	names, err := m.nodePodNames(ctx, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	var events []corev1.Event
+	for name := range names {
+		el, err := m.kClient.CoreV1().Events(m.namespace()).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("involvedObject.name", name).String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events for pod %q: %w", name, err)
+		}
+		for _, e := range el.Items {
+			if e.InvolvedObject.Kind == "Pod" && e.InvolvedObject.Name == name {
+				events = append(events, e)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp.Before(&events[j].LastTimestamp)
+	})
+	return events, nil
+}
+
+// WatchNodeEvents calls handler, in order, for each Kubernetes event of the
+// pods of the provided node until the watch is closed or ctx is done.
+func (m *Manager) WatchNodeEvents(ctx context.Context, nodeName string, handler func(corev1.Event)) error {
+	names, err := m.nodePodNames(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	watcher, err := m.kClient.CoreV1().Events(m.namespace()).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to watch events: %w", err)
+	}
+	defer watcher.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			ev, ok := e.Object.(*corev1.Event)
+			if !ok || e.Type == watch.Deleted {
 				continue
 			}
+			if ev.InvolvedObject.Kind == "Pod" && names[ev.InvolvedObject.Name] {
+				handler(*ev)
+			}
+		}
+	}
+}
 
-			phase, err := n.Status(ctx)
-			if err != nil || phase == node.StatusFailed {
-				return fmt.Errorf("Node %s: Status %s Reason %v", n, phase, err)
+// UpdateNode merges patch into the proto of the provided node and applies
+// the change to the running node without recreating the topology. The patch
+// is merged with proto.Merge so map entries are replaced, except that the
+// command and args of the patch replace those of the node rather than being
+// appended. If the update fails the node proto is restored. If the node does
+// not fulfill Updater then status.Unimplemented error will be returned.
+func (m *Manager) UpdateNode(ctx context.Context, nodeName string, patch *tpb.Node) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	u, ok := n.(node.Updater)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement Updater interface", nodeName)
+	}
+	if patch.GetName() != "" && patch.GetName() != nodeName {
+		return fmt.Errorf("patch for node %q cannot rename node to %q", nodeName, patch.GetName())
+	}
+	pb := n.GetProto()
+	orig := proto.Clone(pb)
+	proto.Merge(pb, patch)
+	if c := patch.GetConfig(); c != nil {
+		if len(c.GetCommand()) != 0 {
+			pb.Config.Command = append([]string(nil), c.GetCommand()...)
+		}
+		if len(c.GetArgs()) != 0 {
+			pb.Config.Args = append([]string(nil), c.GetArgs()...)
+		}
+	}
+	if err := u.Update(ctx); err != nil {
+		proto.Reset(pb)
+		proto.Merge(pb, orig)
+		return fmt.Errorf("failed to update node %q: %w", nodeName, err)
+	}
+	return nil
+}
+
+// ResetCfg will reset the config for the provided node. If the node does
+// not fulfill Resetter then status.Unimplemented error will be returned.
+func (m *Manager) ResetCfg(ctx context.Context, nodeName string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	r, ok := n.(node.Resetter)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement Resetter interface", nodeName)
+	}
+	return r.ResetCfg(ctx)
+}
+
+// RestartNode restarts the node in place. The services and meshnet links of
+// the node are kept.
+func (m *Manager) RestartNode(ctx context.Context, nodeName string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	return n.Restart(ctx)
+}
+
+// UpgradeNode sets the image of the container of the provided node to
+// newImage in place and waits for the container to run newImage and the node
+// to be ready again. If the node is not ready with newImage within the upgrade
+// timeout, the previous image is restored. If the node does not exist a
+// status.NotFound error is returned.
+func (m *Manager) UpgradeNode(ctx context.Context, nodeName, newImage string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	pods, err := n.Pods(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("node %q has no pods", nodeName)
+	}
+	p := pods[0]
+	var c *corev1.Container
+	for i := range p.Spec.Containers {
+		if p.Spec.Containers[i].Name == nodeName {
+			c = &p.Spec.Containers[i]
+			break
+		}
+	}
+	if c == nil {
+		if len(p.Spec.Containers) == 0 {
+			return fmt.Errorf("pod %q of node %q has no containers", p.Name, nodeName)
+		}
+		c = &p.Spec.Containers[0]
+	}
+	oldImage := c.Image
+	if err := m.setImage(ctx, p, c.Name, newImage); err != nil {
+		return err
+	}
+	log.Infof("Upgrading node %q from image %q to %q", nodeName, oldImage, newImage)
+	timeout := m.upgradeTimeout
+	if timeout <= 0 {
+		timeout = defaultUpgradeTimeout
+	}
+	wctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// The node is still ready with the old container until the kubelet
+	// replaces it, so wait for the new image before checking readiness.
+	err = m.waitContainerImage(wctx, p, c.Name, newImage)
+	if err == nil {
+		err = node.WaitForReady(wctx, n, 0)
+	}
+	if err != nil {
+		log.Warningf("Node %q not ready with image %q, rolling back to %q", nodeName, newImage, oldImage)
+		if rErr := m.setImage(ctx, p, c.Name, oldImage); rErr != nil {
+			return fmt.Errorf("failed to upgrade node %q: %v, failed to roll back: %w", nodeName, err, rErr)
+		}
+		return fmt.Errorf("failed to upgrade node %q, rolled back to image %q: %w", nodeName, oldImage, err)
+	}
+	if cfg := n.GetProto().GetConfig(); cfg != nil {
+		cfg.Image = newImage
+	}
+	return nil
+}
+
+// waitContainerImage waits until container of pod p runs image. The
+// container runs image once its status reports image or a new image ID, or it
+// has restarted since p was read.
+func (m *Manager) waitContainerImage(ctx context.Context, p *corev1.Pod, container, image string) error {
+	var old corev1.ContainerStatus
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.Name == container {
+			old = cs
+		}
+	}
+	interval := m.pollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		cur, err := m.kClient.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get pod %q: %w", p.Name, err)
+		}
+		for _, cs := range cur.Status.ContainerStatuses {
+			if cs.Name != container {
+				continue
 			}
-			if phase == node.StatusRunning {
-				log.Infof("Node %s: Status %s", n, phase)
-				processed[name] = true
-			} else {
-				foundAll = false
+			if cs.Image == image || cs.RestartCount > old.RestartCount || (old.ImageID != "" && cs.ImageID != "" && cs.ImageID != old.ImageID) {
+				return nil
 			}
 		}
-		time.Sleep(100 * time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("container %q of pod %q not running image %q: %w", container, p.Name, image, ctx.Err())
+		case <-time.After(interval):
+		}
 	}
-	if !foundAll {
-		log.Warningf("Failed to determine status of some node resources in %d sec", timeout)
+}
+
+// setImage patches the image of container in pod p.
+func (m *Manager) setImage(ctx context.Context, p *corev1.Pod, container, image string) error {
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"containers": []map[string]string{{"name": container, "image": image}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := m.kClient.CoreV1().Pods(p.Namespace).Patch(ctx, p.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to set image of pod %q to %q: %w", p.Name, image, err)
+	}
+	return nil
+}
+
+// NodePodSpec returns the pod the provided node would create, without
+// submitting it to the cluster, for pre-flight inspection. Nodes whose pod is
+// created by a vendor controller return a status.Unimplemented error.
+func (m *Manager) NodePodSpec(ctx context.Context, nodeName string) (*corev1.Pod, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	return n.PodSpec(ctx)
+}
+
+// Scale will set the number of replicas for the provided node. If the node
+// does not fulfill Scaler then status.Unimplemented error will be returned.
+func (m *Manager) Scale(ctx context.Context, nodeName string, replicas int32) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	s, ok := n.(node.Scaler)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement Scaler interface", nodeName)
+	}
+	return s.Scale(ctx, replicas)
+}
+
+// nodePods returns the pods of n, treating pods which do not exist as none.
+func nodePods(ctx context.Context, n node.Node) ([]*corev1.Pod, error) {
+	pods, err := n.Pods(ctx)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return pods, err
+}
+
+// pausableNode returns the node with the provided name if its pods are
+// created by KNE rather than by a vendor controller.
+func (m *Manager) pausableNode(ctx context.Context, nodeName string) (node.Node, error) {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	if _, err := n.PodSpec(ctx); status.Code(err) == codes.Unimplemented {
+		return nil, status.Errorf(codes.Unimplemented, "pod of node %q is managed by its controller", nodeName)
+	}
+	return n, nil
+}
+
+// setPaused sets or clears PausedAnnotation on the meshnet topology of the
+// provided node, which records the paused state across managers.
+func (m *Manager) setPaused(ctx context.Context, nodeName string, paused bool) error {
+	c := m.tClient.Topology(m.namespace())
+	t, err := c.Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if _, ok := t.Annotations[PausedAnnotation]; ok == paused {
+		return nil
+	}
+	if paused {
+		if t.Annotations == nil {
+			t.Annotations = map[string]string{}
+		}
+		t.Annotations[PausedAnnotation] = "true"
+	} else {
+		delete(t.Annotations, PausedAnnotation)
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(t)
+	if err != nil {
+		return err
+	}
+	_, err = c.Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{})
+	return err
+}
+
+// Pause stops the provided node without deleting the rest of the topology.
+// The meshnet topology of the node is annotated with PausedAnnotation so that
+// Resume can be called by another manager, and the node pods are annotated
+// and then deleted. If the node does not exist a status.NotFound error is
+// returned and nothing is done. Nodes whose pods are managed by a vendor
+// controller return a status.Unimplemented error as the controller would
+// re-create the pods.
+func (m *Manager) Pause(ctx context.Context, nodeName string) error {
+	n, err := m.pausableNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	if err := m.setPaused(ctx, nodeName, true); err != nil {
+		return fmt.Errorf("failed to mark node %q paused: %w", nodeName, err)
+	}
+	pods, err := nodePods(ctx, n)
+	if err != nil {
+		return fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"true"}}}`, PausedAnnotation))
+	for _, p := range pods {
+		if _, err := m.kClient.CoreV1().Pods(p.Namespace).Patch(ctx, p.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to annotate pod %q: %w", p.Name, err)
+		}
+		if err := m.kClient.CoreV1().Pods(p.Namespace).Delete(ctx, p.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %q: %w", p.Name, err)
+		}
+		log.Infof("Paused pod %q of node %q", p.Name, nodeName)
+	}
+	return nil
+}
+
+// Resume restarts the provided node stopped by Pause. The paused annotation
+// is removed from any remaining pods and the node is re-created if it has no
+// pods. The meshnet topology of the node is only unmarked once the node has
+// pods again. If the node does not exist a status.NotFound error is returned
+// and nothing is done.
+func (m *Manager) Resume(ctx context.Context, nodeName string) error {
+	n, err := m.pausableNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	pods, err := nodePods(ctx, n)
+	if err != nil {
+		return fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, PausedAnnotation))
+	for _, p := range pods {
+		if _, ok := p.Annotations[PausedAnnotation]; !ok {
+			continue
+		}
+		if _, err := m.kClient.CoreV1().Pods(p.Namespace).Patch(ctx, p.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to remove annotation from pod %q: %w", p.Name, err)
+		}
+	}
+	if len(pods) == 0 {
+		// Resources other than the pods, such as services, are left in place
+		// by Pause so they already exist and are checked for below.
+		if err := n.Create(ctx); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to re-create node %q: %w", nodeName, err)
+		}
+		pods, err = nodePods(ctx, n)
+		if err != nil {
+			return fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
+		}
+		if len(pods) == 0 {
+			return fmt.Errorf("node %q has no pods after being re-created", nodeName)
+		}
+	}
+	if err := m.setPaused(ctx, nodeName, false); err != nil {
+		return fmt.Errorf("failed to mark node %q resumed: %w", nodeName, err)
 	}
+	log.Infof("Resumed node %q", nodeName)
 	return nil
 }
 
-type Resources struct {
-	Services   map[string][]*corev1.Service
-	Pods       map[string][]*corev1.Pod
-	ConfigMaps map[string]*corev1.ConfigMap
-	Topologies map[string]*topologyv1.Topology
+// ReleaseInfo is the release of the test framework recorded on a topology by
+// TagRelease.
+type ReleaseInfo struct {
+	Version string
+	Commit  string
 }
 
-// Resources gets the currently configured resources from the topology.
-func (m *Manager) Resources(ctx context.Context) (*Resources, error) {
-	r := Resources{
-		Services:   map[string][]*corev1.Service{},
-		Pods:       map[string][]*corev1.Pod{},
-		ConfigMaps: map[string]*corev1.ConfigMap{},
-		Topologies: map[string]*topologyv1.Topology{},
+// TagRelease annotates the topology namespace and the pods of all nodes with
+// version and commitHash, recording the release of the test framework used
+// with the topology. Existing release annotations are replaced.
+func (m *Manager) TagRelease(ctx context.Context, version, commitHash string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{
+				VersionAnnotation: version,
+				CommitAnnotation:  commitHash,
+			},
+		},
+	})
+	if err != nil {
+		return err
 	}
-
-	for nodeName, n := range m.nodes {
-		pods, err := n.Pods(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("could not get pods for node %s: %v", nodeName, err)
+	if _, err := m.kClient.CoreV1().Namespaces().Patch(ctx, m.namespace(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return TopologyNotFoundError{Name: m.namespace()}
 		}
-		r.Pods[nodeName] = pods
-
-		services, err := n.Services(ctx)
+		return fmt.Errorf("failed to annotate namespace %q: %w", m.namespace(), err)
+	}
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pods, err := nodePods(ctx, m.nodes[name])
 		if err != nil {
-			return nil, fmt.Errorf("could not get services for node %s: %v", nodeName, err)
+			return fmt.Errorf("failed to get pods for node %q: %w", name, err)
+		}
+		for _, p := range pods {
+			if _, err := m.kClient.CoreV1().Pods(p.Namespace).Patch(ctx, p.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("failed to annotate pod %q: %w", p.Name, err)
+			}
 		}
-		r.Services[nodeName] = services
 	}
+	log.Infof("Tagged topology %q with release %s (%s)", m.namespace(), version, commitHash)
+	return nil
+}
 
-	tList, err := m.topologyResources(ctx)
+// ReleaseInfo returns the release recorded by TagRelease on the topology
+// namespace. The fields are empty if the topology was not tagged. If the
+// namespace does not exist a TopologyNotFoundError is returned.
+func (m *Manager) ReleaseInfo(ctx context.Context) (*ReleaseInfo, error) {
+	ns, err := m.kClient.CoreV1().Namespaces().Get(ctx, m.namespace(), metav1.GetOptions{})
 	if err != nil {
-		return nil, err
-	}
-	for _, t := range tList {
-		r.Topologies[t.Name] = t
+		if apierrors.IsNotFound(err) {
+			return nil, TopologyNotFoundError{Name: m.namespace()}
+		}
+		return nil, fmt.Errorf("failed to get namespace %q: %w", m.namespace(), err)
 	}
-
-	return &r, nil
+	return &ReleaseInfo{
+		Version: ns.Annotations[VersionAnnotation],
+		Commit:  ns.Annotations[CommitAnnotation],
+	}, nil
 }
 
-// topologyResources gets the topology CRDs for the cluster.
-func (m *Manager) topologyResources(ctx context.Context) ([]*topologyv1.Topology, error) {
-	topology, err := m.tClient.Topology(m.topo.Name).List(ctx, metav1.ListOptions{})
+// AnnotateNode sets the annotations on the pods of the provided node with a
+// strategic merge patch, without restarting them. Annotations with an empty
+// value are removed. If the node does not exist a status.NotFound error is
+// returned and nothing is done.
+func (m *Manager) AnnotateNode(ctx context.Context, nodeName string, annotations map[string]string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return status.Errorf(codes.NotFound, "node %q not found", nodeName)
+	}
+	as := map[string]interface{}{}
+	for k, v := range annotations {
+		if v == "" {
+			as[k] = nil
+			continue
+		}
+		as[k] = v
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": as}})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get topology CRDs: %v", err)
+		return fmt.Errorf("failed to encode annotations: %w", err)
 	}
-
-	items := make([]*topologyv1.Topology, len(topology.Items))
-	for i := range items {
-		items[i] = &topology.Items[i]
+	pods, err := nodePods(ctx, n)
+	if err != nil {
+		return fmt.Errorf("failed to get pods for node %q: %w", nodeName, err)
 	}
-
-	return items, nil
+	for _, p := range pods {
+		if _, err := m.kClient.CoreV1().Pods(p.Namespace).Patch(ctx, p.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to annotate pod %q: %w", p.Name, err)
+		}
+	}
+	return nil
 }
 
-// ConfigPush will push config to the provided node. If the node does
-// not fulfill ConfigPusher then status.Unimplemented error will be returned.
-func (m *Manager) ConfigPush(ctx context.Context, nodeName string, r io.Reader) error {
+// ExposeNode creates a LoadBalancer service in the topology namespace
+// exposing port of the provided node, such as for a debugging agent started
+// after the topology was created. The protocol defaults to TCP. If the node
+// does not exist a status.NotFound error is returned.
+func (m *Manager) ExposeNode(ctx context.Context, nodeName string, port int32, protocol corev1.Protocol) (*corev1.Service, error) {
 	n, ok := m.nodes[nodeName]
 	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+		return nil, status.Errorf(codes.NotFound, "node %q not found", nodeName)
 	}
-	cp, ok := n.(node.ConfigPusher)
-	if !ok {
-		return status.Errorf(codes.Unimplemented, "node %q does not implement ConfigPusher interface", nodeName)
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
 	}
-	return cp.ConfigPush(ctx, r)
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("service-%s-%s-%d", nodeName, strings.ToLower(string(protocol)), port),
+			Labels: map[string]string{
+				"pod": nodeName,
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       fmt.Sprintf("port-%d", port),
+				Protocol:   protocol,
+				Port:       port,
+				TargetPort: intstr.FromInt(int(port)),
+			}},
+			Selector: map[string]string{
+				"app": nodeName,
+			},
+			Type: corev1.ServiceTypeLoadBalancer,
+		},
+	}
+	for k, v := range n.GetProto().GetLabels() {
+		if _, ok := s.Labels[k]; !ok {
+			s.Labels[k] = v
+		}
+	}
+	s, err := m.kClient.CoreV1().Services(m.namespace()).Create(ctx, s, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expose port %d of node %q: %w", port, nodeName, err)
+	}
+	log.Infof("Exposed port %d/%s of node %q with service %q", port, protocol, nodeName, s.Name)
+	return s, nil
 }
 
-// ResetCfg will reset the config for the provided node. If the node does
-// not fulfill Resetter then status.Unimplemented error will be returned.
-func (m *Manager) ResetCfg(ctx context.Context, nodeName string) error {
-	n, ok := m.nodes[nodeName]
-	if !ok {
-		return fmt.Errorf("node %q not found", nodeName)
+// CreateNetworkPolicy creates a NetworkPolicy in the topology namespace
+// allowing all traffic between the pods of the topology. If allowExternal is
+// false, other traffic to and from the pods is denied except for connections
+// to the ports exposed by the node services and DNS lookups to kube-dns.
+// Otherwise all traffic is allowed.
+func (m *Manager) CreateNetworkPolicy(ctx context.Context, allowExternal bool) error {
+	np := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: NetworkPolicyName,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		},
 	}
-	r, ok := n.(node.Resetter)
-	if !ok {
-		return status.Errorf(codes.Unimplemented, "node %q does not implement Resetter interface", nodeName)
+	if allowExternal {
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{}}
+		np.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{}}
+	} else {
+		topoPeer := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+		np.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{From: topoPeer}}
+		ports, err := m.servicePorts(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get service ports of topology %q: %w", m.namespace(), err)
+		}
+		if len(ports) > 0 {
+			np.Spec.Ingress = append(np.Spec.Ingress, networkingv1.NetworkPolicyIngressRule{Ports: ports})
+		}
+		np.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{{To: topoPeer}, dnsEgressRule()}
 	}
-	return r.ResetCfg(ctx)
+	if _, err := m.kClient.NetworkingV1().NetworkPolicies(m.namespace()).Create(ctx, np, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create network policy for topology %q: %w", m.namespace(), err)
+	}
+	log.Infof("Created network policy %q for topology %q", NetworkPolicyName, m.namespace())
+	return nil
+}
+
+// dnsEgressRule returns the egress rule allowing DNS lookups to the
+// kube-dns pods of the cluster.
+func dnsEgressRule() networkingv1.NetworkPolicyEgressRule {
+	udp, tcp := corev1.ProtocolUDP, corev1.ProtocolTCP
+	port := intstr.FromInt(53)
+	return networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+		}},
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &udp, Port: &port},
+			{Protocol: &tcp, Port: &port},
+		},
+	}
+}
+
+// servicePorts returns the container ports targeted by the node services,
+// sorted by port number and protocol. The ports of the services in the
+// topology namespace, such as those created by ExposeNode, are used with their
+// protocol. The services of the node protos are TCP.
+func (m *Manager) servicePorts(ctx context.Context) ([]networkingv1.NetworkPolicyPort, error) {
+	type key struct {
+		port     int32
+		protocol corev1.Protocol
+	}
+	seen := map[key]bool{}
+	var keys []key
+	add := func(k key) {
+		if k.port != 0 && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, n := range m.nodes {
+		for _, s := range n.GetProto().GetServices() {
+			add(key{port: int32(s.GetInside()), protocol: corev1.ProtocolTCP})
+		}
+	}
+	services, err := m.kClient.CoreV1().Services(m.namespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range services.Items {
+		for _, p := range s.Spec.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			// Named target ports cannot be resolved without the pods.
+			if p.TargetPort.Type == intstr.Int {
+				add(key{port: p.TargetPort.IntVal, protocol: protocol})
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].port != keys[j].port {
+			return keys[i].port < keys[j].port
+		}
+		return keys[i].protocol < keys[j].protocol
+	})
+	var ports []networkingv1.NetworkPolicyPort
+	for _, k := range keys {
+		port := intstr.FromInt(int(k.port))
+		protocol := k.protocol
+		ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port})
+	}
+	return ports, nil
 }
 
 // GenerateSelfSigned will create self signed certs on the provided node.
@@ -739,13 +3851,148 @@ func (m *Manager) GenerateSelfSigned(ctx context.Context, nodeName string) error
 	return c.GenerateSelfSigned(ctx)
 }
 
-// populateServiceMap modifies m to contain the full service info.
+// GenerateCerts creates self signed certs on all nodes concurrently. Nodes
+// which do not fulfill Certer are skipped. The errors of all nodes which
+// failed to generate their certs are returned together.
+func (m *Manager) GenerateCerts(ctx context.Context) error {
+	var mu sync.Mutex
+	errs := map[string]error{}
+	var g errgroup.Group
+	for name := range m.nodes {
+		name := name
+		g.Go(func() error {
+			err := m.GenerateSelfSigned(ctx, name)
+			if err != nil && status.Code(err) != codes.Unimplemented {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	var names []string
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errList errlist.List
+	for _, name := range names {
+		errList.Add(fmt.Errorf("failed to generate cert for node %s: %w", name, errs[name]))
+	}
+	return errList.Err()
+}
+
+// RevokeCert will revoke the certs on the provided node. If the node does
+// not fulfill CertRevoker then status.Unimplemented error will be returned.
+func (m *Manager) RevokeCert(ctx context.Context, nodeName string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	c, ok := n.(node.CertRevoker)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "node %q does not implement CertRevoker interface", nodeName)
+	}
+	return c.RevokeCert(ctx)
+}
+
+// ResetNode deletes and recreates a single node, including its meshnet
+// resources, without affecting the rest of the topology.
+func (m *Manager) ResetNode(ctx context.Context, nodeName string) error {
+	n, ok := m.nodes[nodeName]
+	if !ok {
+		return fmt.Errorf("node %q not found", nodeName)
+	}
+	// Resolve the meshnet specs before deleting anything so the peers of the
+	// node's links can still be determined.
+	owned, err := n.TopologySpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("could not fetch topology specs for node %s: %w", nodeName, err)
+	}
+	names := map[string]bool{}
+	for _, t := range owned {
+		names[t.ObjectMeta.Name] = true
+	}
+	all, err := m.topologySpecs(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get meshnet topologies: %w", err)
+	}
+	log.Infof("Resetting node %s", n)
+	if err := n.Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete node %s: %w", n, err)
+	}
+	for name := range names {
+		if err := m.tClient.Topology(m.namespace()).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete meshnet node %q: %w", name, err)
+		}
+	}
+	if err := m.waitNodeDeleted(ctx, n); err != nil {
+		return fmt.Errorf("failed to wait for node %s deletion: %w", n, err)
+	}
+	for _, t := range all {
+		if !names[t.ObjectMeta.Name] {
+			continue
+		}
+		if _, err := m.tClient.Topology(m.namespace()).Create(ctx, t, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("could not create topology for meshnet node %s: %w", t.ObjectMeta.Name, err)
+		}
+	}
+	if err := n.Create(ctx); err != nil {
+		return fmt.Errorf("failed to create node %s: %w", n, err)
+	}
+	if err := m.GenerateSelfSigned(ctx, nodeName); err != nil && status.Code(err) != codes.Unimplemented {
+		return fmt.Errorf("failed to generate cert for node %s: %w", n, err)
+	}
+	log.Infof("Node %s reset", n)
+	return nil
+}
+
+// waitNodeDeleted polls until no pods exist for the node.
+func (m *Manager) waitNodeDeleted(ctx context.Context, n node.Node) error {
+	ctx, cancel := context.WithTimeout(ctx, deleteWatchTimeout)
+	defer cancel()
+	interval := m.pollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	for {
+		pods, err := n.Pods(ctx)
+		switch {
+		case apierrors.IsNotFound(err):
+			return nil
+		case err != nil:
+			return err
+		case len(pods) == 0:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context canceled before node %q deleted", n.Name())
+		default:
+		}
+		sleep(interval)
+	}
+}
+
+// populateServiceMap modifies m to contain the full service info. Services
+// without an external load balancer IP are populated with the cluster info only
+// and marked pending. An error is returned if the load balancer ingress of the
+// service has no address, in which case m is left unchanged.
 var populateServiceMap = func(s *corev1.Service, m map[uint32]*tpb.Service) error {
 	if s == nil || m == nil {
 		return fmt.Errorf("service and map must not be nil")
 	}
-	if len(s.Status.LoadBalancer.Ingress) == 0 {
-		return fmt.Errorf("service %s has no external loadbalancer configured", s.Name)
+	outsideIP := ""
+	status := tpb.Service_STATUS_AVAILABLE
+	switch ing := s.Status.LoadBalancer.Ingress; {
+	case len(ing) == 0:
+		log.Warningf("Service %s has no external loadbalancer configured, marking it pending", s.Name)
+		status = tpb.Service_STATUS_PENDING
+	case ing[0].IP == "" && ing[0].Hostname == "":
+		return fmt.Errorf("service %s has a loadbalancer ingress without an address", s.Name)
+	default:
+		outsideIP = ing[0].IP
 	}
 	for _, p := range s.Spec.Ports {
 		k := uint32(p.Port)
@@ -763,11 +4010,45 @@ var populateServiceMap = func(s *corev1.Service, m map[uint32]*tpb.Service) erro
 		service.Inside = uint32(p.TargetPort.IntVal)
 		service.NodePort = uint32(p.NodePort)
 		service.InsideIp = s.Spec.ClusterIP
-		service.OutsideIp = s.Status.LoadBalancer.Ingress[0].IP
+		service.OutsideIp = outsideIP
+		service.Status = status
 	}
 	return nil
 }
 
+// populateIngressHosts sets the outside hostname of the services in m which
+// are the backend of a rule of one of ings. The backend must be one of
+// services and is matched on the outside port or the name of the service.
+func populateIngressHosts(services []*corev1.Service, ings map[string]*networkingv1.Ingress, m map[uint32]*tpb.Service) {
+	names := map[string]bool{}
+	for _, s := range services {
+		names[s.Name] = true
+	}
+	var ingNames []string
+	for name := range ings {
+		ingNames = append(ingNames, name)
+	}
+	sort.Strings(ingNames)
+	for _, name := range ingNames {
+		for _, rule := range ings[name].Spec.Rules {
+			if rule.Host == "" || rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				b := p.Backend.Service
+				if b == nil || !names[b.Name] {
+					continue
+				}
+				for k, svc := range m {
+					if (b.Port.Number != 0 && k == uint32(b.Port.Number)) || (b.Port.Name != "" && svc.GetName() == b.Port.Name) {
+						svc.OutsideHostname = rule.Host
+					}
+				}
+			}
+		}
+	}
+}
+
 // stateMap keeps the POD state of all topology nodes.
 type stateMap struct {
 	m map[string]node.Status
@@ -784,6 +4065,18 @@ func (s *stateMap) setNodeState(name string, state node.Status) {
 	s.m[name] = state
 }
 
+// nodeStates returns a copy of the state of each node keyed by node name.
+func (s *stateMap) nodeStates() map[string]node.Status {
+	if s == nil {
+		return nil
+	}
+	states := make(map[string]node.Status, len(s.m))
+	for name, state := range s.m {
+		states[name] = state
+	}
+	return states
+}
+
 func (s *stateMap) topologyState() cpb.TopologyState {
 	if s == nil || len(s.m) == 0 {
 		return cpb.TopologyState_TOPOLOGY_STATE_UNSPECIFIED
@@ -804,15 +4097,166 @@ func (s *stateMap) topologyState() cpb.TopologyState {
 	}
 }
 
-// Load loads a Topology from path.
-func Load(path string) (*tpb.Topology, error) {
-	b, err := os.ReadFile(path)
+const defaultHTTPTimeout = 30 * time.Second
+
+type loadOptions struct {
+	httpTimeout time.Duration
+}
+
+// LoadOption configures how Load reads a topology.
+type LoadOption func(*loadOptions)
+
+// WithHTTPTimeout sets the timeout for fetching topologies from http and
+// https URLs. Defaults to 30 seconds.
+func WithHTTPTimeout(d time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.httpTimeout = d
+	}
+}
+
+// Load loads a Topology from path. The path may be a local file or an http
+// or https URL. The format is determined by the suffix of the file or URL
+// path. If the topology has a base, the base topology is loaded recursively
+// relative to path and the topology is merged on top of it.
+func Load(path string, opts ...LoadOption) (*tpb.Topology, error) {
+	o := &loadOptions{httpTimeout: defaultHTTPTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return loadWithBase(path, o, map[string]bool{})
+}
+
+// isURL returns true if path is an http or https URL.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// loadWithBase loads a Topology from path resolving its bases. seen holds
+// the paths already loaded to detect cycles.
+func loadWithBase(path string, o *loadOptions, seen map[string]bool) (*tpb.Topology, error) {
+	key := path
+	suffix := path
+	var b []byte
+	if isURL(path) {
+		u, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		suffix = u.Path
+		if seen[key] {
+			return nil, fmt.Errorf("cycle detected loading base topology %q", path)
+		}
+		seen[key] = true
+		if b, err = fetch(path, o.httpTimeout); err != nil {
+			return nil, err
+		}
+	} else {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		key = abs
+		if seen[key] {
+			return nil, fmt.Errorf("cycle detected loading base topology %q", path)
+		}
+		seen[key] = true
+		if b, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	format := "proto"
+	switch {
+	case strings.HasSuffix(suffix, ".yaml"), strings.HasSuffix(suffix, ".yml"):
+		format = "yaml"
+	case strings.HasSuffix(suffix, ".json"):
+		format = "json"
+	}
+	t, err := LoadFromBytes(b, format)
+	if err != nil || t.Base == "" {
+		return t, err
+	}
+	bp := t.Base
+	switch {
+	case isURL(bp), filepath.IsAbs(bp):
+	case isURL(path):
+		u, err := url.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		ref, err := url.Parse(bp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse base topology %q: %w", t.Base, err)
+		}
+		bp = u.ResolveReference(ref).String()
+	default:
+		bp = filepath.Join(filepath.Dir(path), bp)
+	}
+	base, err := loadWithBase(bp, o, seen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load base topology %q: %w", t.Base, err)
+	}
+	return mergeTopology(base, t), nil
+}
+
+// fetch returns the body of the http or https URL u.
+func fetch(u string, timeout time.Duration) ([]byte, error) {
+	c := &http.Client{Timeout: timeout}
+	resp, err := c.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch topology %q: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch topology %q: %s", u, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topology %q: %w", u, err)
+	}
+	return b, nil
+}
+
+// mergeTopology returns t merged on top of base. Nodes of t replace the base
+// nodes of the same name and the links of t are appended to the base links.
+func mergeTopology(base, t *tpb.Topology) *tpb.Topology {
+	merged := proto.Clone(base).(*tpb.Topology)
+	merged.Base = ""
+	if t.Name != "" {
+		merged.Name = t.Name
+	}
+	idx := map[string]int{}
+	for i, n := range merged.Nodes {
+		idx[n.Name] = i
+	}
+	for _, n := range t.Nodes {
+		if i, ok := idx[n.Name]; ok {
+			merged.Nodes[i] = n
+			continue
+		}
+		idx[n.Name] = len(merged.Nodes)
+		merged.Nodes = append(merged.Nodes, n)
+	}
+	merged.Links = append(merged.Links, t.Links...)
+	return merged
+}
+
+// LoadReader loads a Topology from r. The format must be one of "proto",
+// "yaml" or "json".
+func LoadReader(r io.Reader, format string) (*tpb.Topology, error) {
+	b, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
+	return LoadFromBytes(b, format)
+}
+
+// LoadFromBytes loads a Topology from b. The format must be one of "proto",
+// "yaml" or "json". The topology is migrated to CurrentSchemaVersion. Unlike
+// Load, the base of the topology is not resolved.
+func LoadFromBytes(b []byte, format string) (*tpb.Topology, error) {
 	t := &tpb.Topology{}
-	switch {
-	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+	switch format {
+	case "yaml":
 		jsonBytes, err := yaml.YAMLToJSON(b)
 		if err != nil {
 			return nil, fmt.Errorf("could not parse yaml: %v", err)
@@ -820,10 +4264,72 @@ func Load(path string) (*tpb.Topology, error) {
 		if err := protojsonUnmarshaller.Unmarshal(jsonBytes, t); err != nil {
 			return nil, fmt.Errorf("could not parse json: %v", err)
 		}
-	default:
+	case "json":
+		if err := protojsonUnmarshaller.Unmarshal(b, t); err != nil {
+			return nil, fmt.Errorf("could not parse json: %v", err)
+		}
+	case "proto":
 		if err := prototext.Unmarshal(b, t); err != nil {
 			return nil, err
 		}
+	default:
+		return nil, fmt.Errorf("unsupported topology format %q", format)
+	}
+	return MigrateTopology(t)
+}
+
+// SerializeOptions controls how Serialize encodes a topology.
+type SerializeOptions struct {
+	// Format is one of "proto", "yaml" or "json", matching the formats
+	// accepted by LoadReader. Defaults to "proto".
+	Format string
+}
+
+// Serialize encodes t in the format provided in opts. The output can be
+// read back with LoadReader using the same format.
+func Serialize(t *tpb.Topology, opts SerializeOptions) ([]byte, error) {
+	switch opts.Format {
+	case "yaml":
+		jsonBytes, err := protojson.Marshal(t)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal json: %v", err)
+		}
+		b, err := yaml.JSONToYAML(jsonBytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not convert json to yaml: %v", err)
+		}
+		return b, nil
+	case "json":
+		b, err := protojson.MarshalOptions{Multiline: true}.Marshal(t)
+		if err != nil {
+			return nil, fmt.Errorf("could not marshal json: %v", err)
+		}
+		return b, nil
+	case "proto", "":
+		return prototext.MarshalOptions{Multiline: true}.Marshal(t)
+	default:
+		return nil, fmt.Errorf("unsupported topology format %q", opts.Format)
+	}
+}
+
+// Save writes the current topology proto to path so that it can be loaded
+// again, without the resource prefix or the peers filled in by load. The
+// output format is determined by the file extension in the same manner as
+// Load.
+func (m *Manager) Save(path string) error {
+	if m.topo == nil || m.nodes == nil {
+		return fmt.Errorf("topology not loaded, cannot save to %q", path)
+	}
+	format := "proto"
+	switch {
+	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+		format = "yaml"
+	case strings.HasSuffix(path, ".json"):
+		format = "json"
+	}
+	b, err := Serialize(m.unloaded(), SerializeOptions{Format: format})
+	if err != nil {
+		return err
 	}
-	return t, nil
+	return os.WriteFile(path, b, 0644)
 }
@@ -17,31 +17,49 @@ package topo
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/h-fam/errdiff"
+	topologyclientv1 "github.com/networkop/meshnet-cni/api/clientset/v1beta1"
 	tfake "github.com/networkop/meshnet-cni/api/clientset/v1beta1/fake"
 	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
 	cpb "github.com/openconfig/kne/proto/controller"
 	epb "github.com/openconfig/kne/proto/event"
 	tpb "github.com/openconfig/kne/proto/topo"
 	"github.com/openconfig/kne/topo/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	kfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 	ktest "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
 )
 
 func TestLoad(t *testing.T) {
@@ -55,6 +73,9 @@ func TestLoad(t *testing.T) {
 	}, {
 		desc: "yaml",
 		path: "testdata/valid_topo.yaml",
+	}, {
+		desc: "json",
+		path: "testdata/valid_topo.json",
 	}, {
 		desc:    "pb invalid",
 		path:    "testdata/invalid_topo.pb.txt",
@@ -63,13 +84,432 @@ func TestLoad(t *testing.T) {
 		desc:    "yaml invalid",
 		path:    "testdata/invalid_topo.yaml",
 		wantErr: true,
+	}, {
+		desc:    "json invalid",
+		path:    "testdata/invalid_topo.json",
+		wantErr: true,
 	}}
+	want, err := Load("testdata/valid_topo.pb.txt")
+	if err != nil {
+		t.Fatalf("Load() failed to load proto topology: %v", err)
+	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			_, err := Load(tt.path)
+			got, err := Load(tt.path)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if err != nil {
+				return
+			}
+			if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+				t.Errorf("Load() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadURL(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	defer srv.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer slow.Close()
+	tests := []struct {
+		desc    string
+		url     string
+		opts    []LoadOption
+		want    string
+		wantErr string
+	}{{
+		desc: "yaml",
+		url:  srv.URL + "/valid_topo.yaml",
+		want: "testdata/valid_topo.yaml",
+	}, {
+		desc: "pb",
+		url:  srv.URL + "/valid_topo.pb.txt",
+		want: "testdata/valid_topo.pb.txt",
+	}, {
+		desc: "relative base",
+		url:  srv.URL + "/inherit/child.pb.txt",
+		want: "testdata/inherit/child.pb.txt",
+	}, {
+		desc:    "not found",
+		url:     srv.URL + "/dne.yaml",
+		wantErr: "404",
+	}, {
+		desc:    "invalid",
+		url:     srv.URL + "/invalid_topo.yaml",
+		wantErr: "could not parse",
+	}, {
+		desc:    "timeout",
+		url:     slow.URL + "/topo.yaml",
+		opts:    []LoadOption{WithHTTPTimeout(10 * time.Millisecond)},
+		wantErr: "failed to fetch topology",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Load(tt.url, tt.opts...)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Load() unexpected error: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			want, err := Load(tt.want)
+			if err != nil {
+				t.Fatalf("Load() failed to load local topology: %v", err)
+			}
+			if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+				t.Errorf("Load() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadBase(t *testing.T) {
+	tests := []struct {
+		desc    string
+		path    string
+		want    *tpb.Topology
+		wantErr string
+	}{{
+		desc: "no base",
+		path: "testdata/inherit/base.pb.txt",
+		want: &tpb.Topology{
+			Name:          "base",
+			SchemaVersion: CurrentSchemaVersion,
+			Nodes: []*tpb.Node{
+				{Name: "mgmt", Vendor: tpb.Vendor_HOST},
+				{Name: "r1", Vendor: tpb.Vendor_ARISTA, Model: "ceos"},
+			},
+			Links: []*tpb.Link{
+				{ANode: "mgmt", AInt: "eth1", ZNode: "r1", ZInt: "eth1"},
+			},
+		},
+	}, {
+		desc: "three levels",
+		path: "testdata/inherit/child.pb.txt",
+		want: &tpb.Topology{
+			Name:          "child",
+			SchemaVersion: CurrentSchemaVersion,
+			Nodes: []*tpb.Node{
+				{Name: "mgmt", Vendor: tpb.Vendor_HOST},
+				{Name: "r1", Vendor: tpb.Vendor_ARISTA, Model: "ceos", Version: "4.30"},
+				{Name: "r2", Vendor: tpb.Vendor_ARISTA},
+				{Name: "otg", Vendor: tpb.Vendor_KEYSIGHT},
+			},
+			Links: []*tpb.Link{
+				{ANode: "mgmt", AInt: "eth1", ZNode: "r1", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth2", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r2", AInt: "eth2", ZNode: "otg", ZInt: "eth1"},
+			},
+		},
+	}, {
+		desc:    "cycle",
+		path:    "testdata/inherit/cycle_a.pb.txt",
+		wantErr: "cycle detected",
+	}, {
+		desc:    "missing base",
+		path:    "testdata/inherit/missing_base.pb.txt",
+		wantErr: "failed to load base topology",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := Load(tt.path)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Load() unexpected error: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
+				t.Errorf("Load() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadReader(t *testing.T) {
+	tests := []struct {
+		desc    string
+		path    string
+		format  string
+		wantErr string
+	}{{
+		desc:   "pb",
+		path:   "testdata/valid_topo.pb.txt",
+		format: "proto",
+	}, {
+		desc:   "yaml",
+		path:   "testdata/valid_topo.yaml",
+		format: "yaml",
+	}, {
+		desc:   "json",
+		path:   "testdata/valid_topo.json",
+		format: "json",
+	}, {
+		desc:    "json invalid",
+		path:    "testdata/invalid_topo.json",
+		format:  "json",
+		wantErr: "could not parse json",
+	}, {
+		desc:    "pb invalid",
+		path:    "testdata/invalid_topo.pb.txt",
+		format:  "proto",
+		wantErr: "unknown field",
+	}, {
+		desc:    "yaml invalid",
+		path:    "testdata/invalid_topo.yaml",
+		format:  "yaml",
+		wantErr: "could not parse",
+	}, {
+		desc:    "unsupported format",
+		path:    "testdata/valid_topo.yaml",
+		format:  "xml",
+		wantErr: "unsupported topology format",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			b, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatalf("failed to read %q: %v", tt.path, err)
+			}
+			got, err := LoadReader(bytes.NewReader(b), tt.format)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("LoadReader() unexpected err: %s", s)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			want, err := Load(tt.path)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+				t.Errorf("LoadReader() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadFromBytes(t *testing.T) {
+	want := &tpb.Topology{
+		Name:          "test",
+		SchemaVersion: CurrentSchemaVersion,
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor_ARISTA,
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor_ARISTA,
+		}},
+		Links: []*tpb.Link{{
+			ANode: "r1",
+			AInt:  "eth1",
+			ZNode: "r2",
+			ZInt:  "eth1",
+		}},
+	}
+	tests := []struct {
+		desc    string
+		b       string
+		format  string
+		wantErr string
+	}{{
+		desc:   "proto",
+		format: "proto",
+		b: `name: "test"
+nodes: { name: "r1" vendor: ARISTA }
+nodes: { name: "r2" vendor: ARISTA }
+links: { a_node: "r1" a_int: "eth1" z_node: "r2" z_int: "eth1" }
+`,
+	}, {
+		desc:   "yaml",
+		format: "yaml",
+		b: `name: test
+nodes:
+- name: r1
+  vendor: ARISTA
+- name: r2
+  vendor: ARISTA
+links:
+- a_node: r1
+  a_int: eth1
+  z_node: r2
+  z_int: eth1
+`,
+	}, {
+		desc:   "json",
+		format: "json",
+		b:      `{"name": "test", "nodes": [{"name": "r1", "vendor": "ARISTA"}, {"name": "r2", "vendor": "ARISTA"}], "links": [{"a_node": "r1", "a_int": "eth1", "z_node": "r2", "z_int": "eth1"}]}`,
+	}, {
+		desc:    "proto invalid",
+		format:  "proto",
+		b:       `name: "test" bad_field: 1`,
+		wantErr: "unknown field",
+	}, {
+		desc:    "json invalid",
+		format:  "json",
+		b:       `{"name": `,
+		wantErr: "could not parse json",
+	}, {
+		desc:    "unsupported format",
+		format:  "xml",
+		b:       `<topology/>`,
+		wantErr: "unsupported topology format",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := LoadFromBytes([]byte(tt.b), tt.format)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("LoadFromBytes() unexpected err: %s", s)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+				t.Errorf("LoadFromBytes() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	topo := &tpb.Topology{
+		Name:          "test",
+		SchemaVersion: CurrentSchemaVersion,
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor_ARISTA,
+			Services: map[uint32]*tpb.Service{
+				22: {Name: "ssh", Inside: 22},
+			},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor_ARISTA,
+		}},
+		Links: []*tpb.Link{{
+			ANode: "r1",
+			AInt:  "eth1",
+			ZNode: "r2",
+			ZInt:  "eth1",
+		}},
+	}
+	tests := []struct {
+		desc    string
+		m       *Manager
+		file    string
+		wantErr string
+	}{{
+		desc: "pb",
+		m:    &Manager{topo: topo, nodes: map[string]node.Node{}},
+		file: "topo.pb.txt",
+	}, {
+		desc: "yaml",
+		m:    &Manager{topo: topo, nodes: map[string]node.Node{}},
+		file: "topo.yaml",
+	}, {
+		desc: "yml",
+		m:    &Manager{topo: topo, nodes: map[string]node.Node{}},
+		file: "topo.yml",
+	}, {
+		desc: "json",
+		m:    &Manager{topo: topo, nodes: map[string]node.Node{}},
+		file: "topo.json",
+	}, {
+		desc:    "nil topology",
+		m:       &Manager{nodes: map[string]node.Node{}},
+		file:    "topo.pb.txt",
+		wantErr: "topology not loaded",
+	}, {
+		desc:    "nodes not loaded",
+		m:       &Manager{topo: topo},
+		file:    "topo.pb.txt",
+		wantErr: "topology not loaded",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			err := tt.m.Save(path)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Save() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() failed to load saved topology: %v", err)
+			}
+			if s := cmp.Diff(topo, got, protocmp.Transform()); s != "" {
+				t.Errorf("Save() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	topo := &tpb.Topology{
+		Name:          "test",
+		SchemaVersion: CurrentSchemaVersion,
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor_ARISTA,
+			Labels: map[string]string{"foo": "bar"},
+			Services: map[uint32]*tpb.Service{
+				22: {Name: "ssh", Inside: 22},
+			},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor_CISCO,
+		}},
+		Links: []*tpb.Link{{
+			ANode: "r1",
+			AInt:  "eth1",
+			ZNode: "r2",
+			ZInt:  "eth1",
+		}},
+	}
+	tests := []struct {
+		desc       string
+		format     string
+		loadFormat string
+		wantErr    string
+	}{{
+		desc:       "proto",
+		format:     "proto",
+		loadFormat: "proto",
+	}, {
+		desc:       "default",
+		loadFormat: "proto",
+	}, {
+		desc:       "yaml",
+		format:     "yaml",
+		loadFormat: "yaml",
+	}, {
+		desc:       "json",
+		format:     "json",
+		loadFormat: "json",
+	}, {
+		desc:    "unsupported",
+		format:  "xml",
+		wantErr: "unsupported topology format",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			b, err := Serialize(topo, SerializeOptions{Format: tt.format})
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Serialize() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			got, err := LoadReader(bytes.NewReader(b), tt.loadFormat)
+			if err != nil {
+				t.Fatalf("LoadReader() failed to load serialized topology: %v", err)
+			}
+			if s := cmp.Diff(topo, got, protocmp.Transform()); s != "" {
+				t.Errorf("Serialize() unexpected diff (-want +got):\n%s", s)
+			}
 		})
 	}
 }
@@ -89,6 +529,11 @@ func (c *configurable) ConfigPush(_ context.Context, r io.Reader) error {
 	return nil
 }
 
+// WaitForInterfaces returns immediately as there is no pod to exec into.
+func (c *configurable) WaitForInterfaces(_ context.Context, _ time.Duration) error {
+	return nil
+}
+
 func NewConfigurable(impl *node.Impl) (node.Node, error) {
 	return &configurable{Impl: impl}, nil
 }
@@ -113,10 +558,26 @@ type notResettable struct {
 	*node.Impl
 }
 
+type scalable struct {
+	*node.Impl
+	replicas int32
+}
+
+func (s *scalable) Scale(_ context.Context, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("invalid replicas %d", replicas)
+	}
+	s.replicas = replicas
+	return nil
+}
+
 type certable struct {
 	*node.Impl
-	proto *tpb.Node
-	gErr  string
+	proto     *tpb.Node
+	gErr      string
+	rErr      string
+	generated bool
+	revoked   bool
 }
 
 func (c *certable) GetProto() *tpb.Node {
@@ -127,6 +588,15 @@ func (c *certable) GenerateSelfSigned(_ context.Context) error {
 	if c.gErr != "" {
 		return fmt.Errorf(c.gErr)
 	}
+	c.generated = true
+	return nil
+}
+
+func (c *certable) RevokeCert(_ context.Context) error {
+	if c.rErr != "" {
+		return fmt.Errorf(c.rErr)
+	}
+	c.revoked = true
 	return nil
 }
 
@@ -390,60 +860,203 @@ func TestNew(t *testing.T) {
 	}
 }
 
-type fakeMetricsReporter struct {
-	reportStartErr, reportEndErr error
-}
-
-func (f *fakeMetricsReporter) Close() error {
-	return nil
-}
-
-func (f *fakeMetricsReporter) ReportCreateTopologyStart(_ context.Context, _ *epb.Topology) (string, error) {
-	return "fake-uuid", f.reportStartErr
-}
-
-func (f *fakeMetricsReporter) ReportCreateTopologyEnd(_ context.Context, _ string, _ error) error {
-	return f.reportEndErr
-}
-
-func TestCreate(t *testing.T) {
-	ctx := context.Background()
-
-	origNewMetricsReporter := newMetricsReporter
-	defer func() {
-		newMetricsReporter = origNewMetricsReporter
-	}()
-	newMetricsReporter = func(_ context.Context, _, _ string) (metricsReporter, error) {
-		return &fakeMetricsReporter{reportStartErr: errors.New("start err"), reportEndErr: errors.New("end err")}, nil
+func TestKubeContext(t *testing.T) {
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	tests := []struct {
+		desc     string
+		context  string
+		wantHost string
+		wantErr  string
+	}{{
+		desc:     "named context",
+		context:  "kind-b",
+		wantHost: "https://10.0.0.1:6443",
+	}, {
+		desc:     "current context",
+		context:  "kind-a",
+		wantHost: "https://127.0.0.1:6443",
+	}, {
+		desc:    "missing context",
+		context: "dne",
+		wantErr: "dne",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m, err := New(&tpb.Topology{Name: "test"},
+				WithKubecfg("testdata/multi_context_kubeconfig.yaml"),
+				WithKubeContext(tt.context),
+				WithKubeClient(kfake.NewSimpleClientset()),
+				WithTopoClient(tf),
+			)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("New() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			if m.rCfg.Host != tt.wantHost {
+				t.Errorf("New() got host %q, want %q", m.rCfg.Host, tt.wantHost)
+			}
+		})
 	}
+}
 
-	node.Vendor(tpb.Vendor(1002), NewConfigurable)
+func TestValidate(t *testing.T) {
 	tests := []struct {
 		desc    string
 		topo    *tpb.Topology
-		timeout time.Duration
-		opts    []Option
-		wantErr string
+		wantErr []string
 	}{{
-		desc: "success",
+		desc: "valid",
 		topo: &tpb.Topology{
-			Name: "test",
-			Nodes: []*tpb.Node{
-				{
-					Name:   "r1",
-					Vendor: tpb.Vendor(1002),
-					Services: map[uint32]*tpb.Service{
-						1000: {
-							Name: "ssh",
-						},
-					},
-					Config: &tpb.Config{},
-				},
-				{
-					Name:   "r2",
-					Vendor: tpb.Vendor(1002),
-					Services: map[uint32]*tpb.Service{
-						2000: {
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}},
+			Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+		},
+	}, {
+		desc: "duplicate node",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r1"}},
+		},
+		wantErr: []string{`duplicate node "r1"`},
+	}, {
+		desc: "duplicate node port",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{
+				Name:     "r1",
+				Services: map[uint32]*tpb.Service{22: node.ServiceProto("ssh", 22, 30022)},
+			}, {
+				Name:     "r2",
+				Services: map[uint32]*tpb.Service{22: node.ServiceProto("ssh", 22, 30022)},
+			}},
+		},
+		wantErr: []string{`node port 30022 requested by both node "r1" and node "r2"`},
+	}, {
+		desc: "duplicate a endpoint",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth2"},
+			},
+		},
+		wantErr: []string{"interface r1:eth1 already connected"},
+	}, {
+		desc: "duplicate z endpoint",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth2", ZNode: "r2", ZInt: "eth1"},
+			},
+		},
+		wantErr: []string{"interface r2:eth1 already connected"},
+	}, {
+		desc: "self referential link",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}},
+			Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r1", ZInt: "eth1"}},
+		},
+		wantErr: []string{"interface r1:eth1 connected to itself"},
+	}, {
+		desc: "missing nodes",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}},
+			Links: []*tpb.Link{{ANode: "r2", AInt: "eth1", ZNode: "r3", ZInt: "eth1"}},
+		},
+		wantErr: []string{`missing node "r2"`, `missing node "r3"`},
+	}, {
+		desc: "multiple violations",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r1"}, {Name: "r2"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth1", ZNode: "r3", ZInt: "eth1"},
+				{ANode: "r2", AInt: "eth2", ZNode: "r2", ZInt: "eth2"},
+			},
+		},
+		wantErr: []string{
+			`duplicate node "r1"`,
+			"interface r1:eth1 already connected",
+			`missing node "r3"`,
+			"interface r2:eth2 connected to itself",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m := &Manager{topo: tt.topo}
+			err := m.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() unexpected err: %v", err)
+				}
+				return
+			}
+			for _, want := range tt.wantErr {
+				if s := errdiff.Check(err, want); s != "" {
+					t.Errorf("Validate() unexpected err: %s", s)
+				}
+			}
+		})
+	}
+}
+
+type fakeMetricsReporter struct {
+	reportStartErr, reportEndErr error
+}
+
+func (f *fakeMetricsReporter) Close() error {
+	return nil
+}
+
+func (f *fakeMetricsReporter) ReportCreateTopologyStart(_ context.Context, _ *epb.Topology) (string, error) {
+	return "fake-uuid", f.reportStartErr
+}
+
+func (f *fakeMetricsReporter) ReportCreateTopologyEnd(_ context.Context, _ string, _ error) error {
+	return f.reportEndErr
+}
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+
+	origNewMetricsReporter := newMetricsReporter
+	defer func() {
+		newMetricsReporter = origNewMetricsReporter
+	}()
+	newMetricsReporter = func(_ context.Context, _, _ string) (metricsReporter, error) {
+		return &fakeMetricsReporter{reportStartErr: errors.New("start err"), reportEndErr: errors.New("end err")}, nil
+	}
+
+	node.Vendor(tpb.Vendor(1002), NewConfigurable)
+	tests := []struct {
+		desc    string
+		topo    *tpb.Topology
+		timeout time.Duration
+		opts    []Option
+		wantErr string
+	}{{
+		desc: "success",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1002),
+					Services: map[uint32]*tpb.Service{
+						1000: {
+							Name: "ssh",
+						},
+					},
+					Config: &tpb.Config{},
+				},
+				{
+					Name:   "r2",
+					Vendor: tpb.Vendor(1002),
+					Services: map[uint32]*tpb.Service{
+						2000: {
 							Name: "grpc",
 						},
 						3000: {
@@ -587,6 +1200,185 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+type pendingNode struct {
+	*node.Impl
+	pending  int
+	notReady int
+}
+
+func (p *pendingNode) Status(_ context.Context) (node.Status, error) {
+	if p.pending > 0 {
+		p.pending--
+		return node.StatusPending, nil
+	}
+	return node.StatusRunning, nil
+}
+
+func (p *pendingNode) Ready(_ context.Context) (bool, error) {
+	if p.notReady > 0 {
+		p.notReady--
+		return false, nil
+	}
+	return true, nil
+}
+
+func TestCheckNodeStatusBackoff(t *testing.T) {
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	tests := []struct {
+		desc     string
+		pending  int
+		notReady int
+		opts     []Option
+		want     []time.Duration
+	}{{
+		desc: "running immediately",
+	}, {
+		desc:     "running but not ready",
+		notReady: 2,
+		want: []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+		},
+	}, {
+		desc:    "default backoff",
+		pending: 6,
+		want: []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			1600 * time.Millisecond,
+			3200 * time.Millisecond,
+		},
+	}, {
+		desc:    "default backoff capped",
+		pending: 8,
+		want: []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			800 * time.Millisecond,
+			1600 * time.Millisecond,
+			3200 * time.Millisecond,
+			5 * time.Second,
+			5 * time.Second,
+		},
+	}, {
+		desc:    "custom max interval",
+		pending: 5,
+		opts:    []Option{WithMaxPollInterval(500 * time.Millisecond)},
+		want: []time.Duration{
+			100 * time.Millisecond,
+			200 * time.Millisecond,
+			400 * time.Millisecond,
+			500 * time.Millisecond,
+			500 * time.Millisecond,
+		},
+	}, {
+		desc:    "custom poll interval",
+		pending: 4,
+		opts:    []Option{WithPollInterval(time.Second), WithMaxPollInterval(3 * time.Second)},
+		want: []time.Duration{
+			time.Second,
+			2 * time.Second,
+			3 * time.Second,
+			3 * time.Second,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []time.Duration
+			sleep = func(d time.Duration) {
+				got = append(got, d)
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			opts := []Option{
+				WithClusterConfig(&rest.Config{}),
+				WithKubeClient(kfake.NewSimpleClientset()),
+				WithTopoClient(tf),
+			}
+			m, err := New(&tpb.Topology{Name: "test"}, append(opts, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			m.nodes = map[string]node.Node{
+				"r1": &pendingNode{
+					Impl:     &node.Impl{Proto: &tpb.Node{Name: "r1"}},
+					pending:  tt.pending,
+					notReady: tt.notReady,
+				},
+			}
+			if err := m.checkNodeStatus(context.Background(), 0); err != nil {
+				t.Fatalf("checkNodeStatus() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("checkNodeStatus() unexpected sleep sequence (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestHealthScore(t *testing.T) {
+	tests := []struct {
+		desc          string
+		pending       map[string]int
+		wantScore     float64
+		wantUnhealthy []string
+	}{{
+		desc:      "no nodes",
+		wantScore: 1,
+	}, {
+		desc:          "none healthy",
+		pending:       map[string]int{"r1": 1, "r2": 1},
+		wantUnhealthy: []string{"r1", "r2"},
+	}, {
+		desc:          "half healthy",
+		pending:       map[string]int{"r1": 0, "r2": 1, "r3": 0, "r4": 1},
+		wantScore:     0.5,
+		wantUnhealthy: []string{"r2", "r4"},
+	}, {
+		desc:      "all healthy",
+		pending:   map[string]int{"r1": 0, "r2": 0},
+		wantScore: 1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			newNodes := func() map[string]node.Node {
+				nodes := map[string]node.Node{}
+				for name, pending := range tt.pending {
+					nodes[name] = &pendingNode{
+						Impl:    &node.Impl{Proto: &tpb.Node{Name: name}},
+						pending: pending,
+					}
+				}
+				return nodes
+			}
+			m := &Manager{topo: &tpb.Topology{Name: "test"}, nodes: newNodes()}
+			got, err := m.HealthScore(context.Background())
+			if err != nil {
+				t.Fatalf("HealthScore() unexpected err: %v", err)
+			}
+			if got != tt.wantScore {
+				t.Errorf("HealthScore() got %v, want %v", got, tt.wantScore)
+			}
+			m.nodes = newNodes()
+			unhealthy, err := m.UnhealthyNodes(context.Background())
+			if err != nil {
+				t.Fatalf("UnhealthyNodes() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.wantUnhealthy, unhealthy); s != "" {
+				t.Errorf("UnhealthyNodes() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
 type fakeWatch struct {
 	ch   chan watch.Event
 	done chan struct{}
@@ -600,196 +1392,857 @@ func (f *fakeWatch) ResultChan() <-chan watch.Event {
 	return f.ch
 }
 
-func TestDelete(t *testing.T) {
-	ctx := context.Background()
-	node.Vendor(tpb.Vendor(1003), NewConfigurable)
+type fakeTopologyInterface struct {
+	topologyclientv1.TopologyInterface
+	w watch.Interface
+}
 
-	failWatchEvents := []watch.Event{
-		{
-			Type: watch.Deleted,
-			Object: &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "dne",
-				},
-			},
-		},
-		{
-			Type: watch.Added,
-			Object: &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
-			},
-		},
+func (f *fakeTopologyInterface) Watch(_ context.Context, _ metav1.ListOptions) (watch.Interface, error) {
+	return f.w, nil
+}
+
+type fakeTopologyClient struct {
+	t topologyclientv1.TopologyInterface
+}
+
+func (f *fakeTopologyClient) Topology(_ string) topologyclientv1.TopologyInterface {
+	return f.t
+}
+
+func TestWatchWithHandler(t *testing.T) {
+	events := []watch.Event{{
+		Type:   watch.Added,
+		Object: &topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r1"}},
+	}, {
+		Type:   watch.Modified,
+		Object: &topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r1"}},
+	}, {
+		Type:   watch.Deleted,
+		Object: &topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r2"}},
+	}}
+	fw := &fakeWatch{
+		ch:   make(chan watch.Event, len(events)),
+		done: make(chan struct{}),
 	}
-	passWatchEvents := append(failWatchEvents,
-		watch.Event{
-			Type: watch.Deleted,
-			Object: &corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
-			},
-		},
-	)
+	for _, e := range events {
+		fw.ch <- e
+	}
+	close(fw.ch)
+	m := &Manager{
+		topo:    &tpb.Topology{Name: "test"},
+		tClient: &fakeTopologyClient{t: &fakeTopologyInterface{w: fw}},
+	}
+	var got []watch.Event
+	err := m.WatchWithHandler(context.Background(), func(eventType watch.EventType, obj runtime.Object) {
+		got = append(got, watch.Event{Type: eventType, Object: obj})
+	})
+	if err != nil {
+		t.Fatalf("WatchWithHandler() unexpected err: %v", err)
+	}
+	if s := cmp.Diff(events, got); s != "" {
+		t.Errorf("WatchWithHandler() unexpected events (-want +got):\n%s", s)
+	}
+	select {
+	case <-fw.done:
+	default:
+		t.Errorf("WatchWithHandler() did not stop the watcher")
+	}
+}
 
-	origDeleteWatchTimeout := deleteWatchTimeout
-	defer func() {
-		deleteWatchTimeout = origDeleteWatchTimeout
-	}()
-	deleteWatchTimeout = time.Millisecond
+func TestLabels(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1007), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1007),
+			Config: &tpb.Config{},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1007),
+			Config: &tpb.Config{},
+			Labels: map[string]string{"team": "r2-team"},
+		}},
+	}
+	labels := map[string]string{"team": "net", "cost-center": "123"}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithLabels(labels))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	ns, err := kf.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get namespace: %v", err)
+	}
+	if s := cmp.Diff(labels, ns.Labels); s != "" {
+		t.Errorf("push() unexpected namespace labels (-want +got):\n%s", s)
+	}
+	wantPodLabels := map[string]map[string]string{
+		"r1": {"app": "r1", "topo": "test", "team": "net", "cost-center": "123"},
+		"r2": {"app": "r2", "topo": "test", "team": "r2-team", "cost-center": "123"},
+	}
+	for name, want := range wantPodLabels {
+		p, err := kf.CoreV1().Pods("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get pod %q: %v", name, err)
+		}
+		if s := cmp.Diff(want, p.Labels); s != "" {
+			t.Errorf("push() unexpected pod %q labels (-want +got):\n%s", name, s)
+		}
+	}
+}
 
+func TestAnnotations(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1015), NewConfigurable)
 	tests := []struct {
 		desc        string
-		topo        *tpb.Topology
-		k8sObjects  []runtime.Object
-		skipWait    bool
-		watchEvents []watch.Event
-		wantErr     string
+		annotations map[string]string
 	}{{
-		desc: "delete a non-existent topo",
-		topo: &tpb.Topology{
-			Name: "test",
-			Nodes: []*tpb.Node{
-				{
+		desc:        "annotations",
+		annotations: map[string]string{"billing/team": "net", "policy.example.com/exempt": "true"},
+	}, {
+		desc: "nil annotations",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
 					Name:   "r1",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						1000: {
-							Name: "ssh",
-						},
-					},
-				},
-				{
-					Name:   "r2",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						2000: {
-							Name: "grpc",
-						},
-						3000: {
-							Name: "gnmi",
-						},
-					},
-				},
-			},
-			Links: []*tpb.Link{
-				{
-					ANode: "r1",
-					AInt:  "eth1",
-					ZNode: "r2",
-					ZInt:  "eth1",
-				},
-			},
-		},
-		skipWait: true,
-		wantErr:  "does not exist in cluster",
+					Vendor: tpb.Vendor(1015),
+					Config: &tpb.Config{},
+				}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset()
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithAnnotations(tt.annotations))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			if err := m.push(ctx); err != nil {
+				t.Fatalf("push() unexpected err: %v", err)
+			}
+			var ns *corev1.Namespace
+			for _, a := range kf.Actions() {
+				if ca, ok := a.(ktest.CreateAction); ok && ca.GetResource().Resource == "namespaces" {
+					ns = ca.GetObject().(*corev1.Namespace)
+				}
+			}
+			if ns == nil {
+				t.Fatalf("push() did not create namespace")
+			}
+			if s := cmp.Diff(tt.annotations, ns.Annotations); s != "" {
+				t.Errorf("push() unexpected namespace annotations (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestServiceAccount(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1023), NewConfigurable)
+	tests := []struct {
+		desc           string
+		serviceAccount string
+		objs           []runtime.Object
+		wantCreate     bool
+	}{{
+		desc:           "create service account",
+		serviceAccount: "kne-sa",
+		wantCreate:     true,
 	}, {
-		desc: "delete an existing topo",
-		topo: &tpb.Topology{
-			Name: "test",
-			Nodes: []*tpb.Node{
-				{
+		desc:           "existing service account",
+		serviceAccount: "kne-sa",
+		objs:           []runtime.Object{&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "kne-sa", Namespace: "test"}}},
+	}, {
+		desc: "no service account",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
 					Name:   "r1",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						1000: {
-							Name: "ssh",
-						},
-					},
-				},
-				{
+					Vendor: tpb.Vendor(1023),
+					Config: &tpb.Config{},
+				}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(tt.objs...)
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithServiceAccount(tt.serviceAccount))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			if err := m.push(ctx); err != nil {
+				t.Fatalf("push() unexpected err: %v", err)
+			}
+			var created bool
+			for _, a := range kf.Actions() {
+				if ca, ok := a.(ktest.CreateAction); ok && ca.GetResource().Resource == "serviceaccounts" {
+					created = true
+				}
+			}
+			if created != tt.wantCreate {
+				t.Errorf("push() created service account: got %v, want %v", created, tt.wantCreate)
+			}
+			if tt.serviceAccount != "" {
+				if _, err := kf.CoreV1().ServiceAccounts("test").Get(ctx, tt.serviceAccount, metav1.GetOptions{}); err != nil {
+					t.Errorf("push() service account %q not found: %v", tt.serviceAccount, err)
+				}
+			}
+			pod, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("push() did not create pod: %v", err)
+			}
+			if got := pod.Spec.ServiceAccountName; got != tt.serviceAccount {
+				t.Errorf("push() pod service account: got %q, want %q", got, tt.serviceAccount)
+			}
+		})
+	}
+}
+
+type slowNode struct {
+	*node.Impl
+}
+
+const slowNodeDelay = 100 * time.Millisecond
+
+func (s *slowNode) Create(ctx context.Context) error {
+	select {
+	case <-time.After(slowNodeDelay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if s.Name() == "fail" {
+		return fmt.Errorf("create failed")
+	}
+	return nil
+}
+
+func TestParallelism(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1018), func(impl *node.Impl) (node.Node, error) {
+		return &slowNode{Impl: impl}, nil
+	})
+	const numNodes = 8
+	tests := []struct {
+		desc        string
+		parallelism int
+		fail        bool
+		maxDuration time.Duration
+		wantErr     string
+	}{{
+		desc:        "parallel",
+		parallelism: numNodes,
+		maxDuration: numNodes / 2 * slowNodeDelay,
+	}, {
+		desc:        "parallel failure",
+		parallelism: numNodes,
+		fail:        true,
+		maxDuration: numNodes / 2 * slowNodeDelay,
+		wantErr:     "create failed",
+	}, {
+		desc:        "sequential failure",
+		parallelism: 1,
+		fail:        true,
+		wantErr:     "create failed",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{Name: "test"}
+			for i := 0; i < numNodes; i++ {
+				topo.Nodes = append(topo.Nodes, &tpb.Node{Name: fmt.Sprintf("r%d", i), Vendor: tpb.Vendor(1018)})
+			}
+			if tt.fail {
+				topo.Nodes[0].Name = "fail"
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf), WithParallelism(tt.parallelism))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			start := time.Now()
+			err = m.push(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("push() unexpected err: %s", s)
+			}
+			if d := time.Since(start); tt.maxDuration != 0 && d > tt.maxDuration {
+				t.Errorf("push() took %v, want less than %v", d, tt.maxDuration)
+			}
+		})
+	}
+}
+
+func TestLinkConfig(t *testing.T) {
+	node.Vendor(tpb.Vendor(1021), NewConfigurable)
+	tests := []struct {
+		desc    string
+		config  *tpb.LinkConfig
+		aMtu    uint32
+		want    *tpb.Interface
+		wantErr string
+	}{{
+		desc:   "mtu and vlan",
+		config: &tpb.LinkConfig{Mtu: 9000, VlanId: 100},
+		want:   &tpb.Interface{Mtu: 9000, VlanId: 100},
+	}, {
+		desc:   "matching interface mtu",
+		config: &tpb.LinkConfig{Mtu: 9000},
+		aMtu:   9000,
+		want:   &tpb.Interface{Mtu: 9000},
+	}, {
+		desc: "no config",
+		want: &tpb.Interface{},
+	}, {
+		desc:    "conflicting interface mtu",
+		config:  &tpb.LinkConfig{Mtu: 9000},
+		aMtu:    1500,
+		wantErr: "conflicts with link mtu",
+	}, {
+		desc:    "negative mtu",
+		config:  &tpb.LinkConfig{Mtu: -1},
+		wantErr: "must not be negative",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
+					Name:       "r1",
+					Vendor:     tpb.Vendor(1021),
+					Interfaces: map[string]*tpb.Interface{"eth1": {Mtu: tt.aMtu}},
+				}, {
 					Name:   "r2",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						2000: {
-							Name: "grpc",
-						},
-						3000: {
-							Name: "gnmi",
-						},
-					},
-				},
-			},
-			Links: []*tpb.Link{
-				{
-					ANode: "r1",
-					AInt:  "eth1",
-					ZNode: "r2",
-					ZInt:  "eth1",
-				},
-			},
+					Vendor: tpb.Vendor(1021),
+				}},
+				Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1", Config: tt.config}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf))
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("New() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			for _, name := range []string{"r1", "r2"} {
+				got := m.Nodes()[name].Interfaces()["eth1"]
+				opts := []cmp.Option{protocmp.Transform(), protocmp.IgnoreFields(&tpb.Interface{}, "int_name", "peer_name", "peer_int_name", "uid")}
+				if s := cmp.Diff(tt.want, got, opts...); s != "" {
+					t.Errorf("New() unexpected %s interface (-want +got):\n%s", name, s)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultResources(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1010), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1010),
+			Config: &tpb.Config{},
+		}, {
+			Name:        "r2",
+			Vendor:      tpb.Vendor(1010),
+			Config:      &tpb.Config{},
+			Constraints: map[string]string{"cpu": "2", "memory_limit": "8Gi"},
+		}},
+	}
+	defaults := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
 		},
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithDefaultResources(defaults))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	wantResources := map[string]map[string]string{
+		"r1": {"cpu": "500m", "memory": "1Gi", "memory_limit": "2Gi"},
+		"r2": {"cpu": "2", "memory": "1Gi", "memory_limit": "8Gi"},
+	}
+	for name, want := range wantResources {
+		p, err := kf.CoreV1().Pods("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get pod %q: %v", name, err)
+		}
+		if s := cmp.Diff(want, node.ToConstraints(p.Spec.Containers[0].Resources)); s != "" {
+			t.Errorf("push() unexpected pod %q resources (-want +got):\n%s", name, s)
+		}
+	}
+}
+
+func TestNodeDefaults(t *testing.T) {
+	node.Vendor(tpb.Vendor(1011), NewConfigurable)
+	node.Vendor(tpb.Vendor(1012), NewConfigurable)
+	node.Vendor(tpb.Vendor(1013), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1011),
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1011),
+			Config: &tpb.Config{
+				Image: "explicit:latest",
+				Args:  []string{"--node"},
+				Env:   map[string]string{"A": "node"},
 			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r1",
-					Namespace: "test",
-				},
+		}, {
+			Name:   "r3",
+			Vendor: tpb.Vendor(1012),
+			Config: &tpb.Config{
+				ConfigData: &tpb.Config_File{File: "r3.cfg"},
 			},
+		}, {
+			Name:   "r4",
+			Vendor: tpb.Vendor(1013),
+		}},
+	}
+	defaults := &tpb.Node{
+		Config: &tpb.Config{
+			Image: "default:latest",
+			Args:  []string{"--default"},
+			Env:   map[string]string{"A": "default", "B": "default"},
 		},
-		skipWait: true,
-	}, {
-		desc: "delete with watch",
-		topo: &tpb.Topology{
-			Name: "test",
-			Nodes: []*tpb.Node{
-				{
-					Name:   "r1",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						1000: {
-							Name: "ssh",
-						},
-					},
-				},
-				{
-					Name:   "r2",
-					Vendor: tpb.Vendor(1003),
-					Services: map[uint32]*tpb.Service{
-						2000: {
-							Name: "grpc",
-						},
-						3000: {
-							Name: "gnmi",
-						},
-					},
-				},
+	}
+	m, err := New(topo,
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kfake.NewSimpleClientset()),
+		WithNodeDefaults(tpb.Vendor(1011), defaults),
+		WithNodeDefaults(tpb.Vendor(1012), &tpb.Node{
+			Model: "other",
+			Config: &tpb.Config{
+				Image:      "other:latest",
+				ConfigData: &tpb.Config_Data{Data: []byte("hostname other")},
 			},
-			Links: []*tpb.Link{
-				{
-					ANode: "r1",
-					AInt:  "eth1",
-					ZNode: "r2",
-					ZInt:  "eth1",
-				},
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	want := map[string]*tpb.Node{
+		"r1": {
+			Name:   "r1",
+			Vendor: tpb.Vendor(1011),
+			Config: &tpb.Config{
+				Image: "default:latest",
+				Args:  []string{"--default"},
+				Env:   map[string]string{"A": "default", "B": "default"},
 			},
 		},
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
+		"r2": {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1011),
+			Config: &tpb.Config{
+				Image: "explicit:latest",
+				Args:  []string{"--node"},
+				Env:   map[string]string{"A": "node", "B": "default"},
 			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r1",
-					Namespace: "test",
-				},
+		},
+		"r3": {
+			Name:   "r3",
+			Vendor: tpb.Vendor(1012),
+			Model:  "other",
+			Config: &tpb.Config{
+				Image:      "other:latest",
+				ConfigData: &tpb.Config_File{File: "r3.cfg"},
 			},
 		},
-		watchEvents: passWatchEvents,
-	}, {
-		desc: "delete with watch - bad events",
-		topo: &tpb.Topology{
-			Name: "test",
-			Nodes: []*tpb.Node{
-				{
+		"r4": {
+			Name:   "r4",
+			Vendor: tpb.Vendor(1013),
+		},
+	}
+	for name, wantPB := range want {
+		n, ok := m.Nodes()[name]
+		if !ok {
+			t.Fatalf("node %q not found", name)
+		}
+		if s := cmp.Diff(wantPB, n.GetProto(), protocmp.Transform(), protocmp.IgnoreFields(&tpb.Node{}, "interfaces")); s != "" {
+			t.Errorf("node %q unexpected proto diff (-want +got):\n%s", name, s)
+		}
+	}
+	if s := cmp.Diff([]string{"--default"}, defaults.Config.Args); s != "" {
+		t.Errorf("WithNodeDefaults() modified defaults (-want +got):\n%s", s)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1008), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1008),
+			Services: map[uint32]*tpb.Service{
+				22: {Name: "ssh", Inside: 22},
+			},
+			Config: &tpb.Config{
+				ConfigFile: "startup.cfg",
+				ConfigData: &tpb.Config_Data{Data: []byte("hostname r1")},
+			},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1008),
+			Config: &tpb.Config{},
+		}},
+		Links: []*tpb.Link{{
+			ANode: "r1",
+			AInt:  "eth1",
+			ZNode: "r2",
+			ZInt:  "eth1",
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := m.DryRun(ctx, &buf); err != nil {
+		t.Fatalf("DryRun() unexpected err: %v", err)
+	}
+	type object struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}
+	var got []string
+	docs := strings.Split(buf.String(), "---\n")
+	if docs[0] != "" {
+		t.Fatalf("DryRun() output does not start with a document separator: %q", docs[0])
+	}
+	for _, d := range docs[1:] {
+		var o object
+		if err := yaml.Unmarshal([]byte(d), &o); err != nil {
+			t.Fatalf("DryRun() output is not valid yaml: %v", err)
+		}
+		got = append(got, o.Kind+"/"+o.Metadata.Name)
+	}
+	want := []string{
+		"Namespace/test",
+		"ConfigMap/r1-config",
+		"Service/service-r1",
+		"Pod/r1",
+		"Pod/r2",
+		"Topology/r1",
+		"Topology/r2",
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("DryRun() unexpected resources (-want +got):\n%s", s)
+	}
+	if _, err := kf.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{}); err == nil {
+		t.Errorf("DryRun() created namespace in cluster")
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{}); err == nil {
+		t.Errorf("DryRun() created pod in cluster")
+	}
+	if _, err := kf.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{}); err == nil {
+		t.Errorf("DryRun() created config map in cluster")
+	}
+}
+
+type controllerManaged struct {
+	*node.Impl
+}
+
+func (c *controllerManaged) PodSpec(context.Context) (*corev1.Pod, error) {
+	return nil, status.Errorf(codes.Unimplemented, "pod is managed by its controller")
+}
+
+func TestDryRunControllerManaged(t *testing.T) {
+	node.Vendor(tpb.Vendor(1028), NewConfigurable)
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:     "ceos",
+			Vendor:   tpb.Vendor_ARISTA,
+			Model:    "ceos",
+			Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
+			Config: &tpb.Config{
+				ConfigFile: "startup-config",
+				ConfigData: &tpb.Config_Data{Data: []byte("hostname ceos")},
+			},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1028),
+			Config: &tpb.Config{},
+		}},
+		Links: []*tpb.Link{{ANode: "ceos", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := m.DryRun(context.Background(), &buf); err != nil {
+		t.Fatalf("DryRun() unexpected err: %v", err)
+	}
+	var got []string
+	for _, d := range strings.Split(buf.String(), "---\n")[1:] {
+		var o struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(d), &o); err != nil {
+			t.Fatalf("DryRun() output is not valid yaml: %v", err)
+		}
+		got = append(got, o.Kind+"/"+o.Metadata.Name)
+	}
+	want := []string{
+		"Namespace/test",
+		"ConfigMap/ceos-config",
+		"Service/service-ceos",
+		"Pod/r2",
+		"Topology/ceos",
+		"Topology/r2",
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("DryRun() unexpected resources (-want +got):\n%s", s)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1003), NewConfigurable)
+
+	failWatchEvents := []watch.Event{
+		{
+			Type: watch.Deleted,
+			Object: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "dne",
+				},
+			},
+		},
+		{
+			Type: watch.Added,
+			Object: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+		},
+	}
+	passWatchEvents := append(failWatchEvents,
+		watch.Event{
+			Type: watch.Deleted,
+			Object: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+		},
+	)
+
+	origDeleteWatchTimeout := deleteWatchTimeout
+	defer func() {
+		deleteWatchTimeout = origDeleteWatchTimeout
+	}()
+	deleteWatchTimeout = time.Millisecond
+
+	tests := []struct {
+		desc        string
+		topo        *tpb.Topology
+		k8sObjects  []runtime.Object
+		skipWait    bool
+		watchEvents []watch.Event
+		wantErr     string
+	}{{
+		desc: "delete a non-existent topo",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						1000: {
+							Name: "ssh",
+						},
+					},
+				},
+				{
+					Name:   "r2",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						2000: {
+							Name: "grpc",
+						},
+						3000: {
+							Name: "gnmi",
+						},
+					},
+				},
+			},
+			Links: []*tpb.Link{
+				{
+					ANode: "r1",
+					AInt:  "eth1",
+					ZNode: "r2",
+					ZInt:  "eth1",
+				},
+			},
+		},
+		skipWait: true,
+		wantErr:  "does not exist in cluster",
+	}, {
+		desc: "delete an existing topo",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						1000: {
+							Name: "ssh",
+						},
+					},
+				},
+				{
+					Name:   "r2",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						2000: {
+							Name: "grpc",
+						},
+						3000: {
+							Name: "gnmi",
+						},
+					},
+				},
+			},
+			Links: []*tpb.Link{
+				{
+					ANode: "r1",
+					AInt:  "eth1",
+					ZNode: "r2",
+					ZInt:  "eth1",
+				},
+			},
+		},
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+			},
+		},
+		skipWait: true,
+	}, {
+		desc: "delete with watch",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						1000: {
+							Name: "ssh",
+						},
+					},
+				},
+				{
+					Name:   "r2",
+					Vendor: tpb.Vendor(1003),
+					Services: map[uint32]*tpb.Service{
+						2000: {
+							Name: "grpc",
+						},
+						3000: {
+							Name: "gnmi",
+						},
+					},
+				},
+			},
+			Links: []*tpb.Link{
+				{
+					ANode: "r1",
+					AInt:  "eth1",
+					ZNode: "r2",
+					ZInt:  "eth1",
+				},
+			},
+		},
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+			},
+		},
+		watchEvents: passWatchEvents,
+	}, {
+		desc: "delete with watch - bad events",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{
 					Name:   "r1",
 					Vendor: tpb.Vendor(1003),
 					Services: map[uint32]*tpb.Service{
@@ -928,23 +2381,256 @@ func TestDelete(t *testing.T) {
 	}
 }
 
-func TestShow(t *testing.T) {
+func TestDeleteNotFound(t *testing.T) {
 	ctx := context.Background()
-	node.Vendor(tpb.Vendor(1004), NewConfigurable)
-	topo := &tpb.Topology{
-		Name: "test",
-		Nodes: []*tpb.Node{
-			{
-				Name:   "r1",
-				Vendor: tpb.Vendor(1004),
-				Services: map[uint32]*tpb.Service{
-					22: {
-						Name: "ssh",
-					},
-				},
-			},
-			{
-				Name:   "r2",
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m := &Manager{topo: &tpb.Topology{Name: "test"}, kClient: kfake.NewSimpleClientset(), tClient: tf}
+	err = m.Delete(ctx)
+	var nf TopologyNotFoundError
+	if !errors.As(err, &nf) {
+		t.Fatalf("Delete() got err %v, want TopologyNotFoundError", err)
+	}
+	if nf.Name != "test" {
+		t.Errorf("Delete() got TopologyNotFoundError for %q, want %q", nf.Name, "test")
+	}
+
+	kf := kfake.NewSimpleClientset()
+	kf.PrependReactor("get", "namespaces", func(action ktest.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	m.kClient = kf
+	err = m.Delete(ctx)
+	if s := errdiff.Check(err, "connection refused"); s != "" {
+		t.Fatalf("Delete() unexpected err: %s", s)
+	}
+	if errors.As(err, &nf) {
+		t.Errorf("Delete() got TopologyNotFoundError for a failed namespace lookup")
+	}
+}
+
+func TestWaitForDelete(t *testing.T) {
+	ctx := context.Background()
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}}
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"}}
+	tests := []struct {
+		desc       string
+		k8sObjects []runtime.Object
+		deleteNS   bool
+		wantErr    string
+	}{{
+		desc: "already deleted",
+	}, {
+		desc:       "deleted while waiting",
+		k8sObjects: []runtime.Object{ns, pod},
+		deleteNS:   true,
+	}, {
+		desc:       "timeout",
+		k8sObjects: []runtime.Object{ns, pod, svc},
+		wantErr:    "remaining resources: pod/r1, service/service-r1",
+	}, {
+		desc:       "timeout without resources",
+		k8sObjects: []runtime.Object{ns},
+		wantErr:    "remaining resources: none",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset(tt.k8sObjects...)
+			sleep = func(d time.Duration) {
+				if d != defaultPollInterval {
+					t.Errorf("WaitForDelete() slept %v, want %v", d, defaultPollInterval)
+				}
+				if tt.deleteNS {
+					kf.CoreV1().Namespaces().Delete(ctx, "test", metav1.DeleteOptions{})
+				}
+				time.Sleep(time.Millisecond)
+			}
+			m := &Manager{topo: &tpb.Topology{Name: "test"}, kClient: kf}
+			err := m.WaitForDelete(ctx, 10*time.Millisecond)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("WaitForDelete() unexpected err: %s", s)
+			}
+		})
+	}
+}
+
+func TestDeleteWaitForDelete(t *testing.T) {
+	ctx := context.Background()
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	sleep = func(time.Duration) { time.Sleep(time.Millisecond) }
+	tests := []struct {
+		desc     string
+		stuck    bool
+		skipWait bool
+		wantErr  string
+	}{{
+		desc: "deleted",
+	}, {
+		desc:    "namespace not deleted",
+		stuck:   true,
+		wantErr: "remaining resources: pod/r1",
+	}, {
+		desc:     "skip wait",
+		stuck:    true,
+		skipWait: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+			)
+			if tt.stuck {
+				// Leave the namespace terminating on deletion.
+				kf.PrependReactor("delete", "namespaces", func(ktest.Action) (bool, runtime.Object, error) {
+					return true, nil, nil
+				})
+			}
+			m := &Manager{
+				topo:           &tpb.Topology{Name: "test"},
+				kClient:        kf,
+				tClient:        tf,
+				skipDeleteWait: tt.skipWait,
+				waitForDelete:  10 * time.Millisecond,
+			}
+			err = m.Delete(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("Delete() unexpected err: %s", s)
+			}
+		})
+	}
+}
+
+func TestWaitForService(t *testing.T) {
+	ctx := context.Background()
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	tests := []struct {
+		desc        string
+		node        string
+		pendingGets int
+		missing     bool
+		opts        []Option
+		wantSleeps  []time.Duration
+		wantErr     string
+	}{{
+		desc: "ready immediately",
+		node: "r1",
+	}, {
+		desc:        "ready after polls",
+		node:        "r1",
+		pendingGets: 3,
+		wantSleeps:  []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+	}, {
+		desc:        "custom backoff",
+		node:        "r1",
+		pendingGets: 3,
+		opts:        []Option{WithPollInterval(time.Second), WithMaxPollInterval(1500 * time.Millisecond)},
+		wantSleeps:  []time.Duration{time.Second, 1500 * time.Millisecond, 1500 * time.Millisecond},
+	}, {
+		desc:        "service created while waiting",
+		node:        "r1",
+		pendingGets: 2,
+		missing:     true,
+		wantSleeps:  []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+	}, {
+		desc:        "timeout",
+		node:        "r1",
+		pendingGets: -1,
+		wantErr:     "timed out",
+	}, {
+		desc:    "node not found",
+		node:    "dne",
+		wantErr: `node "dne" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"}}
+			kf := kfake.NewSimpleClientset(svc)
+			gets := 0
+			kf.PrependReactor("get", "services", func(action ktest.Action) (bool, runtime.Object, error) {
+				gets++
+				if tt.pendingGets < 0 || gets <= tt.pendingGets {
+					if tt.missing {
+						return true, nil, apierrors.NewNotFound(corev1.Resource("services"), "service-r1")
+					}
+					return true, svc, nil
+				}
+				ready := svc.DeepCopy()
+				ready.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.168.16.50"}}
+				return true, ready, nil
+			})
+			var sleeps []time.Duration
+			sleep = func(d time.Duration) {
+				sleeps = append(sleeps, d)
+				if tt.pendingGets < 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			m := &Manager{
+				topo:    &tpb.Topology{Name: "test"},
+				kClient: kf,
+				nodes: map[string]node.Node{
+					"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1"}}},
+				},
+			}
+			for _, o := range tt.opts {
+				o(m)
+			}
+			timeout := time.Minute
+			if tt.pendingGets < 0 {
+				timeout = 10 * time.Millisecond
+			}
+			got, err := m.WaitForService(ctx, tt.node, timeout)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("WaitForService() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Status.LoadBalancer.Ingress) == 0 {
+				t.Errorf("WaitForService() returned service without external address: %v", got)
+			}
+			if s := cmp.Diff(tt.wantSleeps, sleeps); s != "" {
+				t.Errorf("WaitForService() unexpected sleep sequence (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestShow(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1004), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{
+				Name:   "r1",
+				Vendor: tpb.Vendor(1004),
+				Services: map[uint32]*tpb.Service{
+					22: {
+						Name: "ssh",
+					},
+				},
+			},
+			{
+				Name:   "r2",
 				Vendor: tpb.Vendor(1004),
 				Services: map[uint32]*tpb.Service{
 					9337: {
@@ -974,6 +2660,24 @@ func TestShow(t *testing.T) {
 	wantTopo.Nodes[1].Services[9339].Outside = 9339
 	wantTopo.Nodes[1].Services[9339].OutsideIp = "192.168.16.51"
 	wantTopo.Nodes[1].Services[9339].NodePort = 20003
+	for _, n := range wantTopo.Nodes {
+		for _, svc := range n.Services {
+			svc.Status = tpb.Service_STATUS_AVAILABLE
+		}
+	}
+
+	wantTopoMissing := proto.Clone(topo).(*tpb.Topology)
+	for _, n := range wantTopoMissing.Nodes {
+		for _, svc := range n.Services {
+			svc.Status = tpb.Service_STATUS_PENDING
+		}
+	}
+
+	wantTopoPending := proto.Clone(wantTopo).(*tpb.Topology)
+	for _, svc := range wantTopoPending.Nodes[1].Services {
+		svc.OutsideIp = ""
+		svc.Status = tpb.Service_STATUS_PENDING
+	}
 
 	topoRemapPorts := proto.Clone(wantTopo).(*tpb.Topology)
 	topoRemapPorts.Nodes[1].Services[9337].Inside = 9339
@@ -1069,8 +2773,9 @@ func TestShow(t *testing.T) {
 			},
 		},
 		want: &cpb.ShowTopologyResponse{
-			State:    cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
-			Topology: wantTopo,
+			State:      cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
+			Topology:   wantTopo,
+			NodeStates: map[string]string{"r1": "RUNNING", "r2": "RUNNING"},
 		},
 	}, {
 		desc: "success with remapped ports",
@@ -1156,8 +2861,9 @@ func TestShow(t *testing.T) {
 			},
 		},
 		want: &cpb.ShowTopologyResponse{
-			State:    cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
-			Topology: wantTopoRemapPorts,
+			State:      cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
+			Topology:   wantTopoRemapPorts,
+			NodeStates: map[string]string{"r1": "RUNNING", "r2": "RUNNING"},
 		},
 	}, {
 		desc: "no pods",
@@ -1182,7 +2888,7 @@ func TestShow(t *testing.T) {
 		},
 		wantErr: "could not get pods",
 	}, {
-		desc: "no services",
+		desc: "no services - pending",
 		k8sObjects: []runtime.Object{
 			&corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1210,7 +2916,92 @@ func TestShow(t *testing.T) {
 				},
 			},
 		},
-		wantErr: "could not get services",
+		want: &cpb.ShowTopologyResponse{
+			State:      cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
+			Topology:   wantTopoMissing,
+			NodeStates: map[string]string{"r1": "RUNNING", "r2": "RUNNING"},
+		},
+	}, {
+		desc: "partial services",
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r2",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r1",
+					Namespace: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.1.1.1",
+					Type:      "LoadBalancer",
+					Ports: []corev1.ServicePort{{
+						Name:       "ssh",
+						Protocol:   "TCP",
+						Port:       22,
+						TargetPort: intstr.FromInt(22),
+						NodePort:   20001,
+					}},
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{
+							IP: "192.168.16.50",
+						}},
+					},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r2",
+					Namespace: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.1.1.2",
+					Type:      "LoadBalancer",
+					Ports: []corev1.ServicePort{{
+						Name:       "grpc",
+						Protocol:   "TCP",
+						Port:       9337,
+						TargetPort: intstr.FromInt(9337),
+						NodePort:   20002,
+					}, {
+						Name:       "gnmi",
+						Protocol:   "TCP",
+						Port:       9339,
+						TargetPort: intstr.FromInt(9339),
+						NodePort:   20003,
+					}},
+				},
+			},
+		},
+		want: &cpb.ShowTopologyResponse{
+			State:      cpb.TopologyState_TOPOLOGY_STATE_RUNNING,
+			Topology:   wantTopoPending,
+			NodeStates: map[string]string{"r1": "RUNNING", "r2": "RUNNING"},
+		},
 	}, {
 		desc: "success - loading",
 		k8sObjects: []runtime.Object{
@@ -1260,588 +3051,4332 @@ func TestShow(t *testing.T) {
 					},
 				},
 			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r2",
-					Namespace: "test",
-				},
-				Spec: corev1.ServiceSpec{
-					ClusterIP: "10.1.1.2",
-					Type:      "LoadBalancer",
-					Ports: []corev1.ServicePort{{
-						Name:       "grpc",
-						Protocol:   "TCP",
-						Port:       9337,
-						TargetPort: intstr.FromInt(9337),
-						NodePort:   20002,
-					}, {
-						Name:       "gnmi",
-						Protocol:   "TCP",
-						Port:       9339,
-						TargetPort: intstr.FromInt(9339),
-						NodePort:   20003,
-					}},
-				},
-				Status: corev1.ServiceStatus{
-					LoadBalancer: corev1.LoadBalancerStatus{
-						Ingress: []corev1.LoadBalancerIngress{{
-							IP: "192.168.16.51",
-						}},
-					},
-				},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r2",
+					Namespace: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.1.1.2",
+					Type:      "LoadBalancer",
+					Ports: []corev1.ServicePort{{
+						Name:       "grpc",
+						Protocol:   "TCP",
+						Port:       9337,
+						TargetPort: intstr.FromInt(9337),
+						NodePort:   20002,
+					}, {
+						Name:       "gnmi",
+						Protocol:   "TCP",
+						Port:       9339,
+						TargetPort: intstr.FromInt(9339),
+						NodePort:   20003,
+					}},
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{
+							IP: "192.168.16.51",
+						}},
+					},
+				},
+			},
+		},
+		want: &cpb.ShowTopologyResponse{
+			State:      cpb.TopologyState_TOPOLOGY_STATE_CREATING,
+			Topology:   wantTopo,
+			NodeStates: map[string]string{"r1": "PENDING", "r2": "RUNNING"},
+		},
+	}, {
+		desc: "success - unhealthy",
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodFailed},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r2",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{
+					Phase:      corev1.PodRunning,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r1",
+					Namespace: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.1.1.1",
+					Type:      "LoadBalancer",
+					Ports: []corev1.ServicePort{{
+						Name:       "ssh",
+						Protocol:   "TCP",
+						Port:       22,
+						TargetPort: intstr.FromInt(22),
+						NodePort:   20001,
+					}},
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{
+							IP: "192.168.16.50",
+						}},
+					},
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r2",
+					Namespace: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					ClusterIP: "10.1.1.2",
+					Type:      "LoadBalancer",
+					Ports: []corev1.ServicePort{{
+						Name:       "grpc",
+						Protocol:   "TCP",
+						Port:       9337,
+						TargetPort: intstr.FromInt(9337),
+						NodePort:   20002,
+					}, {
+						Name:       "gnmi",
+						Protocol:   "TCP",
+						Port:       9339,
+						TargetPort: intstr.FromInt(9339),
+						NodePort:   20003,
+					}},
+				},
+				Status: corev1.ServiceStatus{
+					LoadBalancer: corev1.LoadBalancerStatus{
+						Ingress: []corev1.LoadBalancerIngress{{
+							IP: "192.168.16.51",
+						}},
+					},
+				},
+			},
+		},
+		want: &cpb.ShowTopologyResponse{
+			State:      cpb.TopologyState_TOPOLOGY_STATE_ERROR,
+			Topology:   wantTopo,
+			NodeStates: map[string]string{"r1": "FAILED", "r2": "RUNNING"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			opts := []Option{
+				WithClusterConfig(&rest.Config{}),
+				WithKubeClient(kfake.NewSimpleClientset(tt.k8sObjects...)),
+				WithTopoClient(tf),
+			}
+			tTopo := proto.Clone(topo).(*tpb.Topology)
+			m, err := New(tTopo, opts...)
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			got, err := m.Show(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Show() unexpected err: %s", s)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
+				t.Fatalf("Show() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestGetNodeService(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1019), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1019),
+			Services: map[uint32]*tpb.Service{
+				22:   {Name: "ssh", Inside: 22},
+				9339: {Name: "gnmi", Inside: 9339},
+			},
+		}, {
+			Name:     "r2",
+			Vendor:   tpb.Vendor(1019),
+			Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "10.1.1.1",
+			Ports: []corev1.ServicePort{
+				{Name: "ssh", Port: 22, TargetPort: intstr.FromInt(22), NodePort: 20001},
+				{Name: "gnmi", Port: 9339, TargetPort: intstr.FromInt(9339), NodePort: 20002},
+			},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.168.16.50"}}},
+		},
+	})
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	tests := []struct {
+		desc     string
+		node     string
+		port     uint32
+		want     *tpb.Service
+		wantCode codes.Code
+	}{{
+		desc: "found",
+		node: "r1",
+		port: 9339,
+		want: &tpb.Service{
+			Name:      "gnmi",
+			Inside:    9339,
+			Outside:   9339,
+			InsideIp:  "10.1.1.1",
+			OutsideIp: "192.168.16.50",
+			NodePort:  20002,
+			Status:    tpb.Service_STATUS_AVAILABLE,
+		},
+	}, {
+		desc:     "port not found",
+		node:     "r1",
+		port:     443,
+		wantCode: codes.NotFound,
+	}, {
+		desc:     "node not found",
+		node:     "dne",
+		port:     22,
+		wantCode: codes.NotFound,
+	}, {
+		desc:     "service not found",
+		node:     "r2",
+		port:     22,
+		wantCode: codes.NotFound,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := m.GetNodeService(ctx, tt.node, tt.port)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("GetNodeService() got err %v, want code %v", err, tt.wantCode)
+			}
+			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
+				t.Errorf("GetNodeService() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestShowServiceErrors(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1017), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:     "r1",
+			Vendor:   tpb.Vendor(1017),
+			Services: map[uint32]*tpb.Service{22: {Name: "ssh"}},
+		}, {
+			Name:     "r2",
+			Vendor:   tpb.Vendor(1017),
+			Services: map[uint32]*tpb.Service{9337: {Name: "grpc"}},
+		}, {
+			Name:     "r3",
+			Vendor:   tpb.Vendor(1017),
+			Services: map[uint32]*tpb.Service{9337: {Name: "grpc"}},
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "test"}},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.1.1.1",
+				Ports:     []corev1.ServicePort{{Name: "ssh", Port: 22, TargetPort: intstr.FromInt(22)}},
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "192.168.16.50"}}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r2", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.1.1.2",
+				Ports:     []corev1.ServicePort{{Name: "grpc", Port: 9337, TargetPort: intstr.FromInt(9337)}},
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{}}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r3", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.1.1.3",
+				Ports:     []corev1.ServicePort{{Name: "grpc", Port: 9337, TargetPort: intstr.FromInt(9337)}},
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{}}},
+			},
+		},
+	)
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	got, err := m.Show(ctx)
+	for _, want := range []string{
+		`node "r2": service service-r2 has a loadbalancer ingress without an address`,
+		`node "r3": service service-r3 has a loadbalancer ingress without an address`,
+	} {
+		if s := errdiff.Substring(err, want); s != "" {
+			t.Errorf("Show() unexpected err: %s", s)
+		}
+	}
+	if got == nil {
+		t.Fatalf("Show() returned no response with error %v", err)
+	}
+	want := map[string]map[uint32]*tpb.Service{
+		"r1": {22: {Name: "ssh", Inside: 22, Outside: 22, InsideIp: "10.1.1.1", OutsideIp: "192.168.16.50", Status: tpb.Service_STATUS_AVAILABLE}},
+		"r2": {9337: {Name: "grpc"}},
+		"r3": {9337: {Name: "grpc"}},
+	}
+	for _, n := range got.GetTopology().GetNodes() {
+		if s := cmp.Diff(want[n.GetName()], n.GetServices(), protocmp.Transform()); s != "" {
+			t.Errorf("Show() unexpected services of node %q (-want +got):\n%s", n.GetName(), s)
+		}
+	}
+}
+
+func TestShowConfigMaps(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1025), NewConfigurable)
+	tests := []struct {
+		desc    string
+		include bool
+		want    map[string]string
+	}{{
+		desc:    "included",
+		include: true,
+		want:    map[string]string{"r1": "hostname r1", "r2": "hostname shared"},
+	}, {
+		desc: "not included",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1025),
+					Config: &tpb.Config{ConfigFile: "startup.cfg"},
+				}, {
+					Name:   "r2",
+					Vendor: tpb.Vendor(1025),
+					Config: &tpb.Config{ConfigFile: "startup.cfg", ConfigMapRef: "shared"},
+				}, {
+					Name:   "r3",
+					Vendor: tpb.Vendor(1025),
+					Config: &tpb.Config{ConfigFile: "startup.cfg"},
+				}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "test"}},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "r1-config", Namespace: "test"},
+					Data:       map[string]string{"startup.cfg": "hostname r1"},
+				},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "test"},
+					Data:       map[string]string{"startup.cfg": "hostname shared"},
+				},
+			)
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithIncludeConfigMaps(tt.include))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			got, err := m.Show(ctx)
+			if err != nil {
+				t.Fatalf("Show() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.want, got.GetNodeConfigs()); s != "" {
+				t.Errorf("Show() unexpected node configs (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestShowIngressHosts(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1029), NewConfigurable)
+	tests := []struct {
+		desc      string
+		listErr   error
+		wantHosts map[uint32]string
+	}{{
+		desc:      "ingress",
+		wantHosts: map[uint32]string{22: "", 443: "r1.example.com"},
+	}, {
+		desc:      "ingresses forbidden",
+		listErr:   apierrors.NewForbidden(networkingv1.Resource("ingresses"), "", fmt.Errorf("denied")),
+		wantHosts: map[uint32]string{22: "", 443: ""},
+	}, {
+		desc:      "ingresses not served",
+		listErr:   apierrors.NewNotFound(networkingv1.Resource("ingresses"), ""),
+		wantHosts: map[uint32]string{22: "", 443: ""},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1029),
+					Services: map[uint32]*tpb.Service{
+						22:  {Name: "ssh", Inside: 22},
+						443: {Name: "ui", Inside: 443},
+					},
+				}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test", Labels: map[string]string{"app": "r1"}},
+					Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+						{Name: "ssh", Port: 22, TargetPort: intstr.FromInt(22)},
+						{Name: "ui", Port: 443, TargetPort: intstr.FromInt(443)},
+					}},
+				},
+				&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: "r1-ui", Namespace: "test"},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{
+							Host: "r1.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{{
+									Path: "/",
+									Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+										Name: "service-r1",
+										Port: networkingv1.ServiceBackendPort{Number: 443},
+									}},
+								}},
+							}},
+						}},
+					},
+				},
+			)
+			if tt.listErr != nil {
+				kf.PrependReactor("list", "ingresses", func(ktest.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.listErr
+				})
+			}
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			got, err := m.Show(ctx)
+			if err != nil {
+				t.Fatalf("Show() unexpected err: %v", err)
+			}
+			gotHosts := map[uint32]string{}
+			for k, s := range got.GetTopology().GetNodes()[0].GetServices() {
+				gotHosts[k] = s.GetOutsideHostname()
+			}
+			if s := cmp.Diff(tt.wantHosts, gotHosts); s != "" {
+				t.Errorf("Show() unexpected service hostnames (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestResources(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1005), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{
+				Name:   "r1",
+				Vendor: tpb.Vendor(1005),
+				Services: map[uint32]*tpb.Service{
+					1000: {
+						Name: "ssh",
+					},
+				},
+			},
+			{
+				Name:   "r2",
+				Vendor: tpb.Vendor(1005),
+				Services: map[uint32]*tpb.Service{
+					2000: {
+						Name: "grpc",
+					},
+					3000: {
+						Name: "gnmi",
+					},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		desc        string
+		k8sObjects  []runtime.Object
+		topoObjects []runtime.Object
+		want        *Resources
+		wantErr     string
+	}{{
+		desc: "success",
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r2",
+					Namespace: "test",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r1",
+					Namespace: "test",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r2",
+					Namespace: "test",
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1-config",
+					Namespace: "test",
+				},
+				Data: map[string]string{
+					"startup-config": "hostname r1",
+				},
+			},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-config",
+					Namespace: "other",
+				},
+			},
+			&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1-ui",
+					Namespace: "test",
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "r1.example.com"}},
+				},
+			},
+			&networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other-ui",
+					Namespace: "other",
+				},
+			},
+		},
+		topoObjects: []runtime.Object{
+			&topologyv1.Topology{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "t1",
+					Namespace: "test",
+				},
+			},
+		},
+		want: &Resources{
+			Pods: map[string][]*corev1.Pod{
+				"r1": {{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "r1",
+						Namespace: "test",
+					},
+				}},
+				"r2": {{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "r2",
+						Namespace: "test",
+					},
+				}},
+			},
+			Services: map[string][]*corev1.Service{
+				"r1": {{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "service-r1",
+						Namespace: "test",
+					},
+				}},
+				"r2": {{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "service-r2",
+						Namespace: "test",
+					},
+				}},
+			},
+			ConfigMaps: map[string]*corev1.ConfigMap{
+				"r1-config": {
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "r1-config",
+						Namespace: "test",
+					},
+					Data: map[string]string{
+						"startup-config": "hostname r1",
+					},
+				},
+			},
+			Topologies: map[string]*topologyv1.Topology{
+				"t1": {
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Topology",
+						APIVersion: "networkop.co.uk/v1beta1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "t1",
+						Namespace: "test",
+					},
+				},
+			},
+			Ingresses: map[string]*networkingv1.Ingress{
+				"r1-ui": {
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "r1-ui",
+						Namespace: "test",
+					},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "r1.example.com"}},
+					},
+				},
+			},
+		},
+	}, {
+		desc: "no pods",
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r1",
+					Namespace: "test",
+				},
+			},
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "service-r2",
+					Namespace: "test",
+				},
+			},
+		},
+		wantErr: "could not get pods",
+	}, {
+		desc: "no services",
+		k8sObjects: []runtime.Object{
+			&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r1",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "r2",
+					Namespace: "test",
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			},
+		},
+		wantErr: "could not get services",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset(tt.topoObjects...)
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			opts := []Option{
+				WithClusterConfig(&rest.Config{}),
+				WithKubeClient(kfake.NewSimpleClientset(tt.k8sObjects...)),
+				WithTopoClient(tf),
+			}
+			tTopo := proto.Clone(topo).(*tpb.Topology)
+			m, err := New(tTopo, opts...)
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			got, err := m.Resources(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("Resources() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("Resources() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestNodes(t *testing.T) {
+	aNode := &configurable{}
+	bNode := &configurable{}
+	cNode := &configurable{}
+	tests := []struct {
+		desc string
+		want map[string]node.Node
+	}{{
+		desc: "non-zero nodes",
+		want: map[string]node.Node{
+			"a": aNode,
+			"b": bNode,
+			"c": cNode,
+		},
+	}, {
+		desc: "zero nodes",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m := &Manager{nodes: tt.want}
+			got := m.Nodes()
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("Nodes() unexpected diff: %s", s)
+			}
+		})
+	}
+}
+
+func TestFindNodes(t *testing.T) {
+	m := &Manager{nodes: map[string]node.Node{}}
+	for _, pb := range []*tpb.Node{
+		{Name: "r1", Vendor: tpb.Vendor_ARISTA, Type: tpb.Node_ARISTA_CEOS, Labels: map[string]string{"role": "dut"}},
+		{Name: "r2", Vendor: tpb.Vendor_ARISTA, Labels: map[string]string{"role": "ate"}},
+		{Name: "r3", Vendor: tpb.Vendor_CISCO, Type: tpb.Node_CISCO_XRD, Labels: map[string]string{"role": "dut"}},
+		{Name: "r4", Vendor: tpb.Vendor_ARISTA, Type: tpb.Node_ARISTA_CEOS, Labels: map[string]string{"role": "dut", "site": "a"}},
+	} {
+		m.nodes[pb.Name] = &configurable{Impl: &node.Impl{Proto: pb}}
+	}
+	tests := []struct {
+		desc   string
+		filter NodeFilter
+		want   []string
+	}{{
+		desc: "all",
+		want: []string{"r1", "r2", "r3", "r4"},
+	}, {
+		desc:   "vendor",
+		filter: NodeFilter{Vendor: tpb.Vendor_ARISTA},
+		want:   []string{"r1", "r2", "r4"},
+	}, {
+		desc:   "type",
+		filter: NodeFilter{Type: tpb.Node_CISCO_XRD},
+		want:   []string{"r3"},
+	}, {
+		desc:   "labels",
+		filter: NodeFilter{Labels: map[string]string{"role": "dut"}},
+		want:   []string{"r1", "r3", "r4"},
+	}, {
+		desc:   "combined",
+		filter: NodeFilter{Vendor: tpb.Vendor_ARISTA, Type: tpb.Node_ARISTA_CEOS, Labels: map[string]string{"site": "a"}},
+		want:   []string{"r4"},
+	}, {
+		desc:   "no match",
+		filter: NodeFilter{Vendor: tpb.Vendor_CISCO, Labels: map[string]string{"role": "ate"}},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got []string
+			for _, n := range m.FindNodes(tt.filter) {
+				got = append(got, n.Name())
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("FindNodes() unexpected nodes (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestTopologySize(t *testing.T) {
+	tests := []struct {
+		desc string
+		topo *tpb.Topology
+		want TopologySize
+	}{{
+		desc: "empty",
+		topo: &tpb.Topology{Name: "test"},
+	}, {
+		desc: "nodes",
+		topo: &tpb.Topology{
+			Name: "test",
+			Nodes: []*tpb.Node{
+				{Name: "r1", Vendor: tpb.Vendor_NOKIA, Services: map[uint32]*tpb.Service{22: {Name: "ssh"}, 9339: {Name: "gnmi"}}},
+				{Name: "r2", Vendor: tpb.Vendor_ARISTA, Services: map[uint32]*tpb.Service{22: {Name: "ssh"}}},
+				{Name: "r3", Vendor: tpb.Vendor_NOKIA},
+			},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			},
+		},
+		want: TopologySize{
+			Nodes:    3,
+			Links:    2,
+			Services: 3,
+			Vendors:  []tpb.Vendor{tpb.Vendor_ARISTA, tpb.Vendor_NOKIA},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m := &Manager{topo: tt.topo}
+			if s := cmp.Diff(tt.want, m.TopologySize()); s != "" {
+				t.Errorf("TopologySize() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestConfigPush(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"configurable":     &configurable{},
+			"not_configurable": &notConfigurable{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		name    string
+		cfg     io.Reader
+		wantErr string
+	}{{
+		desc: "configurable good config",
+		name: "configurable",
+		cfg:  bytes.NewReader([]byte("good config")),
+	}, {
+		desc:    "configurable bad config",
+		name:    "configurable",
+		cfg:     bytes.NewReader([]byte("error")),
+		wantErr: "error",
+	}, {
+		desc:    "not configurable",
+		name:    "not_configurable",
+		wantErr: "does not implement ConfigPusher interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := m.ConfigPush(context.Background(), tt.name, tt.cfg)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("ConfigPush() unexpected error: %s", s)
+			}
+		})
+	}
+}
+
+type configStore struct {
+	*node.Impl
+	config []byte
+}
+
+func (c *configStore) ConfigPush(_ context.Context, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.config = b
+	return nil
+}
+
+func (c *configStore) PullConfig(_ context.Context, w io.Writer) error {
+	_, err := w.Write(c.config)
+	return err
+}
+
+func TestConfigPull(t *testing.T) {
+	ctx := context.Background()
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"store":    &configStore{},
+			"no_store": &notExecer{},
+		},
+	}
+	const config = "hostname r1\n"
+	if err := m.ConfigPush(ctx, "store", strings.NewReader(config)); err != nil {
+		t.Fatalf("ConfigPush() unexpected err: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := m.ConfigPull(ctx, "store", &buf); err != nil {
+		t.Fatalf("ConfigPull() unexpected err: %v", err)
+	}
+	if got := buf.String(); got != config {
+		t.Errorf("ConfigPull() got %q, want %q", got, config)
+	}
+	buf.Reset()
+	if err := m.ConfigPull(ctx, "no_store", &buf); err != nil {
+		t.Errorf("ConfigPull() of node without ConfigPuller unexpected err: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("ConfigPull() of node without ConfigPuller wrote %q, want nothing", buf.String())
+	}
+	if err := m.ConfigPull(ctx, "dne", &buf); err == nil {
+		t.Errorf("ConfigPull() of missing node succeeded, want error")
+	}
+}
+
+type backuper struct {
+	*node.Impl
+	startup string
+	err     error
+}
+
+func (b *backuper) BackupConfig(_ context.Context, w io.Writer) error {
+	if b.err != nil {
+		return b.err
+	}
+	_, err := io.WriteString(w, b.startup)
+	return err
+}
+
+func TestBackupAllConfigs(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"r1": &backuper{startup: "startup r1\n"},
+			"r2": &configStore{config: []byte("running r2\n")},
+			"r3": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r3"}}},
+			"r4": &backuper{err: errors.New("no startup config")},
+		},
+	}
+	dir := filepath.Join(t.TempDir(), "backup")
+	err := m.BackupAllConfigs(context.Background(), dir)
+	if s := errdiff.Check(err, `failed to back up config of node "r4": no startup config`); s != "" {
+		t.Fatalf("BackupAllConfigs() unexpected error: %s", s)
+	}
+	got := map[string]string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read backup directory: %v", err)
+	}
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatalf("failed to read backup %q: %v", e.Name(), err)
+		}
+		got[e.Name()] = string(b)
+	}
+	want := map[string]string{
+		"r1.conf": "startup r1\n",
+		"r2.conf": "running r2\n",
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("BackupAllConfigs() unexpected backups (-want +got):\n%s", s)
+	}
+}
+
+type versioner struct {
+	*node.Impl
+	version string
+	err     error
+}
+
+func (v *versioner) Version(_ context.Context) (string, error) {
+	return v.version, v.err
+}
+
+func TestNodeVersion(t *testing.T) {
+	kf := kfake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "r1", Image: "registry.example.com:5000/ceos:4.30.1F"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "r2", Image: "registry.example.com:5000/ceos"}}},
+		},
+	)
+	impl := func(name string) *node.Impl {
+		return &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: name}}
+	}
+	tests := []struct {
+		desc    string
+		node    node.Node
+		want    string
+		wantErr string
+	}{{
+		desc: "image tag",
+		node: &configurable{Impl: impl("r1")},
+		want: "4.30.1F",
+	}, {
+		desc: "image without tag",
+		node: &configurable{Impl: impl("r2")},
+		want: "latest",
+	}, {
+		desc: "versioner",
+		node: &versioner{Impl: impl("r1"), version: "4.30.1F-build7"},
+		want: "4.30.1F-build7",
+	}, {
+		desc: "versioner without version",
+		node: &versioner{Impl: impl("r1")},
+		want: "4.30.1F",
+	}, {
+		desc:    "versioner failure",
+		node:    &versioner{Impl: impl("r1"), err: fmt.Errorf("gnmi unavailable")},
+		wantErr: "gnmi unavailable",
+	}, {
+		desc:    "pod not found",
+		node:    &configurable{Impl: impl("r3")},
+		wantErr: "failed to get pods",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m := &Manager{nodes: map[string]node.Node{"dut": tt.node}}
+			got, err := m.NodeVersion(context.Background(), "dut")
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("NodeVersion() unexpected err: %s", s)
+			}
+			if got != tt.want {
+				t.Errorf("NodeVersion() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetCfg(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"resettable":     &resettable{},
+			"resettable_err": &resettable{rErr: "failed to reset"},
+			"not_resettable": &notResettable{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		name    string
+		wantErr string
+	}{{
+		desc: "resettable",
+		name: "resettable",
+	}, {
+		desc:    "resettable failure",
+		name:    "resettable_err",
+		wantErr: "failed to reset",
+	}, {
+		desc:    "not resettable",
+		name:    "not_resettable",
+		wantErr: "does not implement Resetter interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := m.ResetCfg(context.Background(), tt.name)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("ResetCfg() unexpected error: %s", s)
+			}
+		})
+	}
+}
+
+type execer struct {
+	*node.Impl
+	stdout, stderr string
+	err            error
+}
+
+func (e *execer) Exec(_ context.Context, cmd []string, _ io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fmt.Fprintf(stdout, "%s: %s", strings.Join(cmd, " "), e.stdout)
+	fmt.Fprint(stderr, e.stderr)
+	return e.err
+}
+
+type notExecer struct {
+	node.Node
+}
+
+func TestExecCommand(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"execer":     &execer{stdout: "up", stderr: "warning"},
+			"execer_err": &execer{stderr: "not found", err: fmt.Errorf("command terminated with exit code 1")},
+			"not_execer": &notExecer{},
+		},
+	}
+	tests := []struct {
+		desc       string
+		name       string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{{
+		desc:       "execer",
+		name:       "execer",
+		wantStdout: "show version: up",
+		wantStderr: "warning",
+	}, {
+		desc:       "execer failure",
+		name:       "execer_err",
+		wantStdout: "show version: ",
+		wantStderr: "not found",
+		wantErr:    "exit code 1",
+	}, {
+		desc:    "not execer",
+		name:    "not_execer",
+		wantErr: "does not implement Execer interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			stdout, stderr, err := m.ExecCommand(context.Background(), tt.name, []string{"show", "version"})
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("ExecCommand() unexpected error: %s", s)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("ExecCommand() got stdout %q, want %q", stdout, tt.wantStdout)
+			}
+			if stderr != tt.wantStderr {
+				t.Errorf("ExecCommand() got stderr %q, want %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+type fileCopier struct {
+	*node.Impl
+	copied map[string]string
+}
+
+func (f *fileCopier) CopyFile(_ context.Context, srcPath, destPath string) error {
+	if _, err := os.Stat(srcPath); err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+	f.copied[destPath] = srcPath
+	return nil
+}
+
+func TestCopyFile(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "license.key")
+	if err := os.WriteFile(src, []byte("secret"), 0600); err != nil {
+		t.Fatalf("failed to write source file: %v", err)
+	}
+	fc := &fileCopier{copied: map[string]string{}}
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"copier":     fc,
+			"not_copier": &notExecer{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		name    string
+		src     string
+		wantErr string
+	}{{
+		desc: "copier",
+		name: "copier",
+		src:  src,
+	}, {
+		desc:    "source not readable",
+		name:    "copier",
+		src:     src + ".dne",
+		wantErr: "failed to read source file",
+	}, {
+		desc:    "not copier",
+		name:    "not_copier",
+		src:     src,
+		wantErr: "does not implement FileCopier interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		src:     src,
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := m.CopyFile(context.Background(), tt.name, tt.src, "/etc/license.key")
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("CopyFile() unexpected error: %s", s)
+			}
+		})
+	}
+	if s := cmp.Diff(map[string]string{"/etc/license.key": src}, fc.copied); s != "" {
+		t.Errorf("CopyFile() unexpected copies (-want +got):\n%s", s)
+	}
+}
+
+func TestNodeLogs(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1016), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1016),
+			Config: &tpb.Config{},
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if _, err := m.NodeLogs(ctx, "r1", corev1.PodLogOptions{}); err == nil {
+		t.Errorf("NodeLogs() of node without pods succeeded, want error")
+	}
+	if _, err := m.NodeLogs(ctx, "dne", corev1.PodLogOptions{}); err == nil {
+		t.Errorf("NodeLogs() of missing node succeeded, want error")
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	r, err := m.NodeLogs(ctx, "r1", corev1.PodLogOptions{})
+	if err != nil {
+		t.Fatalf("NodeLogs() unexpected err: %v", err)
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read logs: %v", err)
+	}
+	// The fake clientset always streams "fake logs".
+	if got, want := string(b), "fake logs"; got != want {
+		t.Errorf("NodeLogs() got %q, want %q", got, want)
+	}
+	got, err := m.TailNodeLogs(ctx, "r1", 10)
+	if err != nil {
+		t.Fatalf("TailNodeLogs() unexpected err: %v", err)
+	}
+	if want := "fake logs"; got != want {
+		t.Errorf("TailNodeLogs() got %q, want %q", got, want)
+	}
+}
+
+func TestNodeEvents(t *testing.T) {
+	event := func(name, kind, obj, reason string) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "test"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: obj, Namespace: "test"},
+			Reason:         reason,
+		}
+	}
+	kf := kfake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}},
+		event("r1.1", "Pod", "r1", "ImagePullBackOff"),
+		event("r2.1", "Pod", "r2", "OOMKilled"),
+		event("svc.1", "Service", "r1", "EnsuringLoadBalancer"),
+	)
+	m := &Manager{
+		topo:    &tpb.Topology{Name: "test"},
+		kClient: kf,
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1"}}},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got, err := m.NodeEvents(ctx, "r1")
+	if err != nil {
+		t.Fatalf("NodeEvents() unexpected err: %v", err)
+	}
+	var reasons []string
+	for _, e := range got {
+		reasons = append(reasons, e.Reason)
+	}
+	if s := cmp.Diff([]string{"ImagePullBackOff"}, reasons); s != "" {
+		t.Errorf("NodeEvents() unexpected reasons (-want +got):\n%s", s)
+	}
+	if _, err := m.NodeEvents(ctx, "dne"); err == nil {
+		t.Errorf("NodeEvents() of missing node succeeded, want error")
+	}
+
+	watched := make(chan corev1.Event)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- m.WatchNodeEvents(ctx, "r1", func(e corev1.Event) {
+			watched <- e
+		})
+	}()
+	// Retry creating events until the watch is established.
+	for i := 0; ; i++ {
+		if _, err := kf.CoreV1().Events("test").Create(ctx, event(fmt.Sprintf("r2.w%d", i), "Pod", "r2", "Started"), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		if _, err := kf.CoreV1().Events("test").Create(ctx, event(fmt.Sprintf("r1.w%d", i), "Pod", "r1", "Started"), metav1.CreateOptions{}); err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		select {
+		case e := <-watched:
+			if e.InvolvedObject.Name != "r1" {
+				t.Errorf("WatchNodeEvents() got event for %q, want %q", e.InvolvedObject.Name, "r1")
+			}
+			cancel()
+			if err := <-errCh; err != nil {
+				t.Errorf("WatchNodeEvents() unexpected err: %v", err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if i > 500 {
+			t.Fatalf("WatchNodeEvents() did not report an event")
+		}
+	}
+}
+
+type updater struct {
+	*node.Impl
+	got *tpb.Node
+	err error
+}
+
+func (u *updater) Update(_ context.Context) error {
+	u.got = proto.Clone(u.Proto).(*tpb.Node)
+	return u.err
+}
+
+func TestUpdateNode(t *testing.T) {
+	orig := &tpb.Node{
+		Name:   "r1",
+		Config: &tpb.Config{Image: "foo:1", Env: map[string]string{"A": "1", "B": "2"}, Command: []string{"/bin/foo"}, Args: []string{"-a"}},
+		Services: map[uint32]*tpb.Service{
+			22: {Name: "ssh", Inside: 22},
+		},
+	}
+	patch := &tpb.Node{
+		Config: &tpb.Config{Image: "foo:2", Env: map[string]string{"B": "3"}},
+		Services: map[uint32]*tpb.Service{
+			443: {Name: "ssl", Inside: 443},
+		},
+	}
+	merged := &tpb.Node{
+		Name:   "r1",
+		Config: &tpb.Config{Image: "foo:2", Env: map[string]string{"A": "1", "B": "3"}, Command: []string{"/bin/foo"}, Args: []string{"-a"}},
+		Services: map[uint32]*tpb.Service{
+			22:  {Name: "ssh", Inside: 22},
+			443: {Name: "ssl", Inside: 443},
+		},
+	}
+	tests := []struct {
+		desc      string
+		name      string
+		patch     *tpb.Node
+		updateErr error
+		want      *tpb.Node
+		wantProto *tpb.Node
+		wantErr   string
+	}{{
+		desc:      "updater",
+		name:      "r1",
+		patch:     patch,
+		want:      merged,
+		wantProto: merged,
+	}, {
+		desc:      "update failure",
+		name:      "r1",
+		patch:     patch,
+		updateErr: fmt.Errorf("update failed"),
+		want:      merged,
+		wantProto: orig,
+		wantErr:   "update failed",
+	}, {
+		desc:  "replaced args",
+		name:  "r1",
+		patch: &tpb.Node{Config: &tpb.Config{Command: []string{"/bin/bar"}, Args: []string{"-b"}}},
+		want: &tpb.Node{
+			Name:     "r1",
+			Config:   &tpb.Config{Image: "foo:1", Env: map[string]string{"A": "1", "B": "2"}, Command: []string{"/bin/bar"}, Args: []string{"-b"}},
+			Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
+		},
+	}, {
+		desc:      "rename",
+		name:      "r1",
+		patch:     &tpb.Node{Name: "r2"},
+		wantProto: orig,
+		wantErr:   "cannot rename",
+	}, {
+		desc:    "not updater",
+		name:    "not_updater",
+		patch:   patch,
+		wantErr: "does not implement Updater interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		patch:   patch,
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			u := &updater{Impl: &node.Impl{Proto: proto.Clone(orig).(*tpb.Node)}, err: tt.updateErr}
+			m := &Manager{
+				nodes: map[string]node.Node{
+					"r1":          u,
+					"not_updater": &notExecer{},
+				},
+			}
+			err := m.UpdateNode(context.Background(), tt.name, tt.patch)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("UpdateNode() unexpected error: %s", s)
+			}
+			if tt.want != nil {
+				if s := cmp.Diff(tt.want, u.got, protocmp.Transform()); s != "" {
+					t.Errorf("UpdateNode() unexpected proto passed to Update (-want +got):\n%s", s)
+				}
+			}
+			if tt.wantProto != nil {
+				if s := cmp.Diff(tt.wantProto, u.Proto, protocmp.Transform()); s != "" {
+					t.Errorf("UpdateNode() unexpected node proto (-want +got):\n%s", s)
+				}
+			}
+		})
+	}
+}
+
+func TestScale(t *testing.T) {
+	tests := []struct {
+		desc         string
+		name         string
+		replicas     int32
+		wantReplicas map[string]int32
+		wantErr      string
+	}{{
+		desc:         "scalable",
+		name:         "scalable1",
+		replicas:     3,
+		wantReplicas: map[string]int32{"scalable1": 3, "scalable2": 1},
+	}, {
+		desc:     "scalable failure",
+		name:     "scalable1",
+		replicas: -1,
+		wantErr:  "invalid replicas",
+	}, {
+		desc:    "not scalable",
+		name:    "not_scalable",
+		wantErr: "does not implement Scaler interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s1 := &scalable{replicas: 1}
+			s2 := &scalable{replicas: 1}
+			m := &Manager{
+				nodes: map[string]node.Node{
+					"scalable1":    s1,
+					"scalable2":    s2,
+					"not_scalable": &notConfigurable{},
+				},
+			}
+			err := m.Scale(context.Background(), tt.name, tt.replicas)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("Scale() unexpected error: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			got := map[string]int32{"scalable1": s1.replicas, "scalable2": s2.replicas}
+			if s := cmp.Diff(tt.wantReplicas, got); s != "" {
+				t.Errorf("Scale() unexpected replicas (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1014), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1014),
+			Config: &tpb.Config{},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1014),
+			Config: &tpb.Config{},
+		}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	if err := m.Pause(ctx, "r1"); err != nil {
+		t.Fatalf("Pause() unexpected err: %v", err)
+	}
+	var annotated bool
+	for _, a := range kf.Actions() {
+		if pa, ok := a.(ktest.PatchAction); ok && pa.GetName() == "r1" && strings.Contains(string(pa.GetPatch()), PausedAnnotation) {
+			annotated = true
+		}
+	}
+	if !annotated {
+		t.Errorf("Pause() did not annotate pod r1")
+	}
+	mt, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get meshnet topology r1: %v", err)
+	}
+	if _, ok := mt.Annotations[PausedAnnotation]; !ok {
+		t.Errorf("Pause() did not annotate meshnet topology r1")
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Pause() did not delete pod r1, got err: %v", err)
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r2", metav1.GetOptions{}); err != nil {
+		t.Errorf("Pause() unexpectedly deleted pod r2: %v", err)
+	}
+	// Pausing an already paused node is a noop.
+	if err := m.Pause(ctx, "r1"); err != nil {
+		t.Fatalf("Pause() of paused node unexpected err: %v", err)
+	}
+	// The node is resumed by a new manager for the same topology.
+	m2, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := m2.Resume(ctx, "r1"); err != nil {
+			t.Fatalf("Resume() unexpected err on attempt %d: %v", i, err)
+		}
+	}
+	p, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Resume() did not re-create pod r1: %v", err)
+	}
+	if _, ok := p.Annotations[PausedAnnotation]; ok {
+		t.Errorf("Resume() pod r1 still has annotation %q", PausedAnnotation)
+	}
+	mt, err = tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get meshnet topology r1: %v", err)
+	}
+	if _, ok := mt.Annotations[PausedAnnotation]; ok {
+		t.Errorf("Resume() meshnet topology r1 still has annotation %q", PausedAnnotation)
+	}
+
+	if err := m.Pause(ctx, "dne"); status.Code(err) != codes.NotFound {
+		t.Errorf("Pause() of missing node got err %v, want code %v", err, codes.NotFound)
+	}
+	if err := m.Resume(ctx, "dne"); status.Code(err) != codes.NotFound {
+		t.Errorf("Resume() of missing node got err %v, want code %v", err, codes.NotFound)
+	}
+	kf.PrependReactor("delete", "pods", func(ktest.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("pods"), "r2", fmt.Errorf("denied"))
+	})
+	err = m.Pause(ctx, "r2")
+	if !apierrors.IsForbidden(err) || status.Code(err) == codes.NotFound {
+		t.Errorf("Pause() with API failure got err %v, want forbidden error", err)
+	}
+	// The node must not be reported as resumed when re-creating its pod
+	// fails as already existing but it still has no pods.
+	kf.ReactionChain = kf.ReactionChain[1:]
+	if err := kf.CoreV1().Pods("test").Delete(ctx, "r2", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete pod r2: %v", err)
+	}
+	kf.PrependReactor("create", "pods", func(ktest.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewAlreadyExists(corev1.Resource("pods"), "r2")
+	})
+	if err := m.Resume(ctx, "r2"); err == nil {
+		t.Errorf("Resume() without a re-created pod succeeded, want error")
+	}
+}
+
+func TestPauseControllerManaged(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset()
+	m := &Manager{topo: &tpb.Topology{Name: "test"}, kClient: kf, nodes: map[string]node.Node{
+		"r1": &controllerManaged{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1"}}},
+	}}
+	if err := m.Pause(ctx, "r1"); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Pause() got err %v, want code %v", err, codes.Unimplemented)
+	}
+	if err := m.Resume(ctx, "r1"); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Resume() got err %v, want code %v", err, codes.Unimplemented)
+	}
+}
+
+func TestTagRelease(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test", Annotations: map[string]string{VersionAnnotation: "v1.0.0"}}},
+	)
+	m := &Manager{topo: &tpb.Topology{Name: "test"}, kClient: kf, nodes: map[string]node.Node{}}
+	for _, name := range []string{"r1", "r2"} {
+		m.nodes[name] = &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: name}}}
+	}
+
+	got, err := m.ReleaseInfo(ctx)
+	if err != nil {
+		t.Fatalf("ReleaseInfo() unexpected err: %v", err)
+	}
+	if s := cmp.Diff(&ReleaseInfo{}, got); s != "" {
+		t.Errorf("ReleaseInfo() of untagged topology unexpected diff (-want +got):\n%s", s)
+	}
+
+	if err := m.TagRelease(ctx, "v1.2.3", "abc123"); err != nil {
+		t.Fatalf("TagRelease() unexpected err: %v", err)
+	}
+	want := &ReleaseInfo{Version: "v1.2.3", Commit: "abc123"}
+	got, err = m.ReleaseInfo(ctx)
+	if err != nil {
+		t.Fatalf("ReleaseInfo() unexpected err: %v", err)
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("ReleaseInfo() unexpected diff (-want +got):\n%s", s)
+	}
+	for _, name := range []string{"r1", "r2"} {
+		p, err := kf.CoreV1().Pods("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get pod %q: %v", name, err)
+		}
+		wantAnnotations := map[string]string{VersionAnnotation: "v1.2.3", CommitAnnotation: "abc123"}
+		if s := cmp.Diff(wantAnnotations, p.Annotations); s != "" {
+			t.Errorf("TagRelease() unexpected annotations on pod %q (-want +got):\n%s", name, s)
+		}
+	}
+
+	m.topo.Name = "dne"
+	var tErr TopologyNotFoundError
+	if err := m.TagRelease(ctx, "v1.2.3", "abc123"); !errors.As(err, &tErr) {
+		t.Errorf("TagRelease() of missing topology got err %v, want TopologyNotFoundError", err)
+	}
+	if _, err := m.ReleaseInfo(ctx); !errors.As(err, &tErr) {
+		t.Errorf("ReleaseInfo() of missing topology got err %v, want TopologyNotFoundError", err)
+	}
+}
+
+func TestLinkStatus(t *testing.T) {
+	topo := &tpb.Topology{
+		Name: "test",
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth2"},
+		},
+	}
+	// The link UIDs are not in link order.
+	nodes := map[string]node.Node{}
+	for name, intfs := range map[string]map[string]*tpb.Interface{
+		"r1": {"eth1": {Uid: 2}, "eth2": {Uid: 0}},
+		"r2": {"eth1": {Uid: 2}, "eth2": {Uid: 1}},
+		"r3": {"eth1": {Uid: 0}, "eth2": {Uid: 1}},
+	} {
+		nodes[name] = &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: name, Interfaces: intfs}}}
+	}
+	plumbed := func(name string, skipped ...int64) *topologyv1.Topology {
+		t := &topologyv1.Topology{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Status:     topologyv1.TopologyStatus{NetNS: "/proc/1/ns/net"},
+		}
+		for _, id := range skipped {
+			t.Status.Skipped = append(t.Status.Skipped, topologyv1.Skipped{LinkId: id})
+		}
+		return t
+	}
+	tests := []struct {
+		desc       string
+		topologies []runtime.Object
+		want       map[string]LinkState
+	}{{
+		desc:       "all up",
+		topologies: []runtime.Object{plumbed("r1"), plumbed("r2"), plumbed("r3")},
+		want: map[string]LinkState{
+			"r1:eth1-r2:eth1": {UID: 2, ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1", Up: true},
+			"r1:eth2-r3:eth1": {UID: 0, ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth1", Up: true},
+			"r2:eth2-r3:eth2": {UID: 1, ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth2", Up: true},
+		},
+	}, {
+		desc: "skipped and unplumbed",
+		topologies: []runtime.Object{
+			plumbed("r1", 0),
+			plumbed("r2"),
+			&topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "test"}},
+		},
+		want: map[string]LinkState{
+			"r1:eth1-r2:eth1": {UID: 2, ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1", Up: true},
+			"r1:eth2-r3:eth1": {UID: 0, ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			"r2:eth2-r3:eth2": {UID: 1, ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth2"},
+		},
+	}, {
+		desc: "missing topologies",
+		want: map[string]LinkState{
+			"r1:eth1-r2:eth1": {UID: 2, ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			"r1:eth2-r3:eth1": {UID: 0, ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+			"r2:eth2-r3:eth2": {UID: 1, ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth2"},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset(tt.topologies...)
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			m := &Manager{topo: topo, tClient: tf, nodes: nodes}
+			got, err := m.LinkStatus(context.Background())
+			if err != nil {
+				t.Fatalf("LinkStatus() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("LinkStatus() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestCheckLinkStatus(t *testing.T) {
+	spec := func(name string, links ...topologyv1.Link) *topologyv1.Topology {
+		return &topologyv1.Topology{
+			TypeMeta:   metav1.TypeMeta{Kind: "Topology", APIVersion: "networkop.co.uk/v1beta1"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec:       topologyv1.TopologySpec{Links: links},
+		}
+	}
+	tests := []struct {
+		desc       string
+		topologies []runtime.Object
+		want       []LinkConflict
+	}{{
+		desc: "unique",
+		topologies: []runtime.Object{
+			spec("r1", topologyv1.Link{UID: 0, LocalIntf: "eth1", PeerPod: "r2", PeerIntf: "eth1"}, topologyv1.Link{UID: 1, LocalIntf: "eth2", PeerPod: "r3", PeerIntf: "eth1"}),
+			spec("r2", topologyv1.Link{UID: 0, LocalIntf: "eth1", PeerPod: "r1", PeerIntf: "eth1"}),
+			spec("r3", topologyv1.Link{UID: 1, LocalIntf: "eth1", PeerPod: "r1", PeerIntf: "eth2"}),
+		},
+	}, {
+		desc: "duplicate uids",
+		topologies: []runtime.Object{
+			spec("r1", topologyv1.Link{UID: 0, LocalIntf: "eth1", PeerPod: "r2", PeerIntf: "eth1"}, topologyv1.Link{UID: 1, LocalIntf: "eth2", PeerPod: "r3", PeerIntf: "eth1"}),
+			spec("r2", topologyv1.Link{UID: 0, LocalIntf: "eth1", PeerPod: "r1", PeerIntf: "eth1"}, topologyv1.Link{UID: 1, LocalIntf: "eth2", PeerPod: "r3", PeerIntf: "eth2"}),
+			spec("r3", topologyv1.Link{UID: 1, LocalIntf: "eth1", PeerPod: "r1", PeerIntf: "eth2"}, topologyv1.Link{UID: 1, LocalIntf: "eth2", PeerPod: "r2", PeerIntf: "eth2"}),
+		},
+		want: []LinkConflict{{
+			UID:   1,
+			Links: []string{"r1:eth2-r3:eth1", "r2:eth2-r3:eth2"},
+		}},
+	}, {
+		desc: "no topologies",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset(tt.topologies...)
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			m := &Manager{topo: &tpb.Topology{Name: "test"}, tClient: tf}
+			got, err := m.CheckLinkStatus(context.Background())
+			if err != nil {
+				t.Fatalf("CheckLinkStatus() unexpected err: %v", err)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("CheckLinkStatus() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1022), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Vendor: tpb.Vendor(1022), Config: &tpb.Config{}},
+			{Name: "r2", Vendor: tpb.Vendor(1022), Config: &tpb.Config{}},
+			{Name: "r3", Vendor: tpb.Vendor(1022), Config: &tpb.Config{}},
+		},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+		},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	// Simulate meshnet plumbing the links of pods when they are created.
+	plumb := func(a ktest.Action) (bool, runtime.Object, error) {
+		name := a.(ktest.CreateAction).GetObject().(*corev1.Pod).Name
+		tp, err := tf.Topology("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil, nil
+		}
+		tp.Status.NetNS = "/proc/1/ns/net"
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tp)
+		if err != nil {
+			return true, nil, err
+		}
+		_, err = tf.Topology("test").Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{})
+		return false, nil, err
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	kf.PrependReactor("create", "pods", plumb)
+	// Drop the meshnet topology of r1 and mark the others to detect which
+	// are recreated.
+	if err := tf.Topology("test").Delete(ctx, "r1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete topology: %v", err)
+	}
+	for _, name := range []string{"r2", "r3"} {
+		tp, err := tf.Topology("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get topology: %v", err)
+		}
+		tp.Labels = map[string]string{"marker": "true"}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tp)
+		if err != nil {
+			t.Fatalf("failed to convert topology: %v", err)
+		}
+		if _, err := tf.Topology("test").Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("failed to update topology: %v", err)
+		}
+	}
+
+	tests := []struct {
+		desc    string
+		link    *tpb.Link
+		wantErr string
+	}{{
+		desc:    "missing node",
+		link:    &tpb.Link{ANode: "r1", AInt: "eth1", ZNode: "dne", ZInt: "eth1"},
+		wantErr: `node "dne" not found`,
+	}, {
+		desc:    "missing link",
+		link:    &tpb.Link{ANode: "r1", AInt: "eth1", ZNode: "r3", ZInt: "eth1"},
+		wantErr: "link r1:eth1 r3:eth1 not found",
+	}, {
+		desc: "reversed link",
+		link: &tpb.Link{ANode: "r2", AInt: "eth1", ZNode: "r1", ZInt: "eth1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf.ClearActions()
+			err := m.Reconnect(ctx, tt.link.ANode, tt.link.AInt, tt.link.ZNode, tt.link.ZInt)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("Reconnect() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			restarted := map[string]bool{}
+			for _, a := range kf.Actions() {
+				if da, ok := a.(ktest.DeleteAction); ok && a.GetResource().Resource == "pods" {
+					restarted[da.GetName()] = true
+				}
+			}
+			if s := cmp.Diff(map[string]bool{"r1": true, "r2": true}, restarted); s != "" {
+				t.Errorf("Reconnect() unexpected restarted pods (-want +got):\n%s", s)
+			}
+		})
+	}
+	r1, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Reconnect() did not recreate topology r1: %v", err)
+	}
+	if s := cmp.Diff([]topologyv1.Link{{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}, r1.Spec.Links, cmpopts.IgnoreFields(topologyv1.Link{}, "LocalIP", "PeerIP")); s != "" {
+		t.Errorf("Reconnect() unexpected r1 links (-want +got):\n%s", s)
+	}
+	for name, wantMarker := range map[string]bool{"r2": false, "r3": true} {
+		tp, err := tf.Topology("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get topology %q: %v", name, err)
+		}
+		if got := tp.Labels["marker"] == "true"; got != wantMarker {
+			t.Errorf("Reconnect() topology %q kept marker %v, want %v", name, got, wantMarker)
+		}
+	}
+
+	// The link is not reported reconnected if meshnet does not plumb it.
+	kf.ReactionChain = kf.ReactionChain[1:]
+	tctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	if s := errdiff.Check(m.Reconnect(tctx, "r1", "eth1", "r2", "eth1"), "was not plumbed"); s != "" {
+		t.Errorf("Reconnect() of unplumbed link unexpected err: %s", s)
+	}
+}
+
+func TestGenerateSelfSigned(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"certable": &certable{
+				proto: &tpb.Node{
+					Config: &tpb.Config{
+						Cert: &tpb.CertificateCfg{
+							Config: &tpb.CertificateCfg_SelfSigned{},
+						},
+					},
+				},
+			},
+			"certable_err": &certable{
+				gErr: "failed to generate certs",
+				proto: &tpb.Node{
+					Config: &tpb.Config{
+						Cert: &tpb.CertificateCfg{
+							Config: &tpb.CertificateCfg_SelfSigned{},
+						},
+					},
+				},
+			},
+			"not_certable": &notCertable{
+				proto: &tpb.Node{
+					Config: &tpb.Config{
+						Cert: &tpb.CertificateCfg{
+							Config: &tpb.CertificateCfg_SelfSigned{},
+						},
+					},
+				},
+			},
+			"no_info": &certable{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		name    string
+		wantErr string
+	}{{
+		desc: "certable",
+		name: "certable",
+	}, {
+		desc:    "certable failure",
+		name:    "certable_err",
+		wantErr: "failed to generate certs",
+	}, {
+		desc:    "not certable",
+		name:    "not_certable",
+		wantErr: "does not implement Certer interface",
+	}, {
+		desc: "no cert info",
+		name: "no_info",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := m.GenerateSelfSigned(context.Background(), tt.name)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("GenerateSelfSigned() unexpected error: %s", s)
+			}
+		})
+	}
+}
+
+func TestRevokeCert(t *testing.T) {
+	tests := []struct {
+		desc    string
+		name    string
+		wantErr string
+	}{{
+		desc: "revoker",
+		name: "certable",
+	}, {
+		desc:    "revoker failure",
+		name:    "certable_err",
+		wantErr: "failed to revoke certs",
+	}, {
+		desc:    "not revoker",
+		name:    "not_certable",
+		wantErr: "does not implement CertRevoker interface",
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: "not found",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := &certable{}
+			m := &Manager{
+				nodes: map[string]node.Node{
+					"certable":     c,
+					"certable_err": &certable{rErr: "failed to revoke certs"},
+					"not_certable": &notCertable{},
+				},
+			}
+			err := m.RevokeCert(context.Background(), tt.name)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Errorf("RevokeCert() unexpected error: %s", s)
+			}
+			if got, want := c.revoked, tt.name == "certable"; got != want {
+				t.Errorf("RevokeCert() revoked %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestResetNode(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1006), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(1006),
+			Config: &tpb.Config{},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor(1006),
+			Config: &tpb.Config{},
+		}},
+		Links: []*tpb.Link{{
+			ANode: "r1",
+			AInt:  "eth1",
+			ZNode: "r2",
+			ZInt:  "eth1",
+		}},
+	}
+	tests := []struct {
+		desc    string
+		node    string
+		wantErr string
+	}{{
+		desc: "success",
+		node: "r1",
+	}, {
+		desc:    "node not found",
+		node:    "dne",
+		wantErr: `node "dne" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tf, err := tfake.NewSimpleClientset(
+				&topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}},
+				&topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}},
+			)
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", Labels: map[string]string{"orig": "true"}}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test", Labels: map[string]string{"orig": "true"}}},
+			)
+			m, err := New(proto.Clone(topo).(*tpb.Topology), WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			err = m.ResetNode(ctx, tt.node)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ResetNode() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			p1, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get reset pod: %v", err)
+			}
+			if _, ok := p1.Labels["orig"]; ok {
+				t.Errorf("ResetNode() did not recreate pod r1")
+			}
+			p2, err := kf.CoreV1().Pods("test").Get(ctx, "r2", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get untouched pod: %v", err)
+			}
+			if _, ok := p2.Labels["orig"]; !ok {
+				t.Errorf("ResetNode() modified pod r2")
+			}
+			mt, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get meshnet topology: %v", err)
+			}
+			want := []topologyv1.Link{{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}
+			if s := cmp.Diff(want, mt.Spec.Links); s != "" {
+				t.Errorf("ResetNode() unexpected meshnet links (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestStateMap(t *testing.T) {
+	type nodeInfo struct {
+		name  string
+		phase node.Status
+	}
+
+	tests := []struct {
+		desc  string
+		nodes []*nodeInfo
+		want  cpb.TopologyState
+	}{{
+		desc: "no nodes",
+		want: cpb.TopologyState_TOPOLOGY_STATE_UNSPECIFIED,
+	}, {
+		desc: "one node failed",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusFailed},
+			{"n2", node.StatusRunning},
+			{"n3", node.StatusRunning},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
+	}, {
+		desc: "one node failed with one node pending",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusFailed},
+			{"n2", node.StatusRunning},
+			{"n3", node.StatusRunning},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
+	}, {
+		desc: "one node failed, one node pending, one node unknown",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusFailed},
+			{"n2", node.StatusPending},
+			{"n3", node.StatusUnknown},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
+	}, {
+		desc: "all nodes failed",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusFailed},
+			{"n2", node.StatusFailed},
+			{"n3", node.StatusFailed},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
+	}, {
+		desc: "one node pending",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusPending},
+			{"n2", node.StatusRunning},
+			{"n3", node.StatusRunning},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_CREATING,
+	}, {
+		desc: "one node running, one node pending",
+		nodes: []*nodeInfo{
+			{"n1", node.StatusRunning},
+			{"n2", node.StatusPending},
+		},
+		want: cpb.TopologyState_TOPOLOGY_STATE_CREATING,
+	},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sm := &stateMap{}
+			for _, n := range tt.nodes {
+				sm.setNodeState(n.name, n.phase)
+			}
+			got := sm.topologyState()
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			wantStates := map[string]node.Status{}
+			for _, n := range tt.nodes {
+				wantStates[n.name] = n.phase
+			}
+			if s := cmp.Diff(wantStates, sm.nodeStates(), cmpopts.EquateEmpty()); s != "" {
+				t.Errorf("nodeStates() unexpected diff (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestTopologies(t *testing.T) {
+	tf, err := tfake.NewSimpleClientset(&topologyv1.Topology{
+		TypeMeta:   metav1.TypeMeta{Kind: "Topology", APIVersion: "networkop.co.uk/v1beta1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+		Spec:       topologyv1.TopologySpec{Links: []topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}},
+	})
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m := &Manager{topo: &tpb.Topology{Name: "test"}, tClient: tf}
+	got, err := m.Topologies(context.Background())
+	if err != nil {
+		t.Fatalf("Topologies() unexpected err: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "r1" {
+		t.Fatalf("Topologies() got %v, want topology r1", got)
+	}
+	if s := cmp.Diff([]topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}, got[0].Spec.Links); s != "" {
+		t.Errorf("Topologies() unexpected links diff (-want +got):\n%s", s)
+	}
+}
+
+func TestReconcileServices(t *testing.T) {
+	ctx := context.Background()
+	services := map[uint32]*tpb.Service{
+		22:    {Name: "ssh", Inside: 22},
+		57400: {Name: "gnmi", Inside: 57400},
+	}
+	kf := kfake.NewSimpleClientset(
+		// r1 lost its load balancer and has a stale target port.
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				Type: corev1.ServiceTypeClusterIP,
+				Ports: []corev1.ServicePort{
+					{Name: "ssh", Protocol: "TCP", Port: 22, TargetPort: intstr.FromInt(2222)},
+					{Name: "gnmi", Protocol: "TCP", Port: 57400, TargetPort: intstr.FromInt(57400)},
+				},
+			},
+		},
+		// r3 is correct, including a node port assigned by the cluster.
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-r3", Namespace: "test"},
+			Spec: corev1.ServiceSpec{
+				Type: corev1.ServiceTypeLoadBalancer,
+				Ports: []corev1.ServicePort{
+					{Name: "gnmi", Protocol: "TCP", Port: 57400, TargetPort: intstr.FromInt(57400), NodePort: 30001},
+					{Name: "ssh", Protocol: "TCP", Port: 22, TargetPort: intstr.FromInt(22), NodePort: 30000},
+				},
+			},
+		},
+	)
+	m := &Manager{
+		topo:    &tpb.Topology{Name: "test"},
+		kClient: kf,
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1", Services: services}}},
+			"r2": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r2", Services: services}}},
+			"r3": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r3", Services: services}}},
+			"r4": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r4"}}},
+		},
+	}
+	got, err := m.ReconcileServices(ctx)
+	if err != nil {
+		t.Fatalf("ReconcileServices() unexpected err: %v", err)
+	}
+	want := []ReconcileAction{
+		{Node: "r1", Service: "service-r1", Action: "update"},
+		{Node: "r2", Service: "service-r2", Action: "create"},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("ReconcileServices() unexpected actions (-want +got):\n%s", s)
+	}
+	wantPorts := []corev1.ServicePort{
+		{Name: "ssh", Protocol: "TCP", Port: 22, TargetPort: intstr.FromInt(22)},
+		{Name: "gnmi", Protocol: "TCP", Port: 57400, TargetPort: intstr.FromInt(57400)},
+	}
+	for _, name := range []string{"service-r1", "service-r2"} {
+		svc, err := kf.CoreV1().Services("test").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("ReconcileServices() service %q not found: %v", name, err)
+		}
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			t.Errorf("ReconcileServices() service %q type: got %q, want %q", name, svc.Spec.Type, corev1.ServiceTypeLoadBalancer)
+		}
+		if s := cmp.Diff(wantPorts, svc.Spec.Ports); s != "" {
+			t.Errorf("ReconcileServices() service %q unexpected ports (-want +got):\n%s", name, s)
+		}
+	}
+	// A second pass has nothing left to fix.
+	got, err = m.ReconcileServices(ctx)
+	if err != nil {
+		t.Fatalf("ReconcileServices() unexpected err: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("ReconcileServices() second pass got actions %v, want none", got)
+	}
+}
+
+func TestConfigMapRef(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1024), NewConfigurable)
+	basePath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(basePath, "r1.cfg"), []byte("hostname r1\n"), 0644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	tests := []struct {
+		desc    string
+		config  *tpb.Config
+		objs    []runtime.Object
+		wantCM  map[string]string
+		wantErr string
+	}{{
+		desc: "from file",
+		config: &tpb.Config{
+			ConfigFile:   "startup.cfg",
+			ConfigPath:   "/etc",
+			ConfigMapRef: "shared-config",
+			ConfigData:   &tpb.Config_File{File: "r1.cfg"},
+		},
+		wantCM: map[string]string{"startup.cfg": "hostname r1\n"},
+	}, {
+		desc: "existing config map",
+		config: &tpb.Config{
+			ConfigFile:   "startup.cfg",
+			ConfigPath:   "/etc",
+			ConfigMapRef: "shared-config",
+		},
+		objs: []runtime.Object{&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared-config", Namespace: "test"},
+			Data:       map[string]string{"startup.cfg": "hostname existing\n"},
+		}},
+		wantCM: map[string]string{"startup.cfg": "hostname existing\n"},
+	}, {
+		desc: "missing config map",
+		config: &tpb.Config{
+			ConfigFile:   "startup.cfg",
+			ConfigPath:   "/etc",
+			ConfigMapRef: "shared-config",
+		},
+		wantErr: `failed to get config map "shared-config"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
+					Name:   "r1",
+					Vendor: tpb.Vendor(1024),
+					Config: tt.config,
+				}},
+			}
+			tf, err := tfake.NewSimpleClientset()
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
+			}
+			kf := kfake.NewSimpleClientset(tt.objs...)
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithBasePath(basePath))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			err = m.push(ctx)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("push() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			cm, err := kf.CoreV1().ConfigMaps("test").Get(ctx, "shared-config", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("push() did not create config map: %v", err)
+			}
+			if s := cmp.Diff(tt.wantCM, cm.Data); s != "" {
+				t.Errorf("push() unexpected config map data (-want +got):\n%s", s)
+			}
+			if _, err := kf.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{}); err == nil {
+				t.Errorf("push() created node config map r1-config, want only shared-config")
+			}
+			pod, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("push() did not create pod: %v", err)
+			}
+			var ref string
+			for _, v := range pod.Spec.Volumes {
+				if v.Name == node.ConfigVolumeName && v.ConfigMap != nil {
+					ref = v.ConfigMap.Name
+				}
+			}
+			if ref != "shared-config" {
+				t.Errorf("push() pod config volume references config map %q, want %q", ref, "shared-config")
+			}
+			if err := m.nodes["r1"].(*configurable).DeleteConfig(ctx); err != nil {
+				t.Fatalf("DeleteConfig() unexpected err: %v", err)
+			}
+			if _, err := kf.CoreV1().ConfigMaps("test").Get(ctx, "shared-config", metav1.GetOptions{}); err != nil {
+				t.Errorf("DeleteConfig() deleted referenced config map shared-config: %v", err)
+			}
+		})
+	}
+}
+
+type configRecorder struct {
+	*node.Impl
+	mu  *sync.Mutex
+	got map[string]string
+}
+
+func (c *configRecorder) ConfigPush(ctx context.Context, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if string(b) == "error" {
+		return fmt.Errorf("push failed")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.got[c.Name()] = string(b)
+	return nil
+}
+
+func TestApplyConfig(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		desc        string
+		ctx         context.Context
+		config      string
+		want        map[string]string
+		wantNodeErr map[string]string
+		wantErr     string
+	}{{
+		desc:   "success",
+		ctx:    context.Background(),
+		config: "hostname dut",
+		want:   map[string]string{"r1": "hostname dut", "r2": "hostname dut"},
+		wantNodeErr: map[string]string{
+			"not_pusher": "does not implement ConfigPusher interface",
+		},
+	}, {
+		desc:   "push failures",
+		ctx:    context.Background(),
+		config: "error",
+		want:   map[string]string{},
+		wantNodeErr: map[string]string{
+			"r1":         "push failed",
+			"r2":         "push failed",
+			"not_pusher": "does not implement ConfigPusher interface",
+		},
+	}, {
+		desc:    "context canceled",
+		ctx:     canceled,
+		config:  "hostname dut",
+		want:    map[string]string{},
+		wantErr: "context canceled",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var mu sync.Mutex
+			got := map[string]string{}
+			newNode := func(name string, v tpb.Vendor) node.Node {
+				return &configRecorder{Impl: &node.Impl{Proto: &tpb.Node{Name: name, Vendor: v}}, mu: &mu, got: got}
+			}
+			m := &Manager{
+				nodes: map[string]node.Node{
+					"r1":         newNode("r1", tpb.Vendor_ARISTA),
+					"r2":         newNode("r2", tpb.Vendor_ARISTA),
+					"other":      newNode("other", tpb.Vendor_JUNIPER),
+					"not_pusher": &notConfigurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "not_pusher", Vendor: tpb.Vendor_ARISTA}}},
+				},
+			}
+			nodeErrs, err := m.ApplyConfig(tt.ctx, tpb.Vendor_ARISTA, strings.NewReader(tt.config))
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("ApplyConfig() unexpected err: %s", s)
+			}
+			if s := cmp.Diff(tt.want, got); s != "" {
+				t.Errorf("ApplyConfig() unexpected pushed configs (-want +got):\n%s", s)
+			}
+			if err != nil {
+				return
+			}
+			if len(nodeErrs) != len(tt.wantNodeErr) {
+				t.Errorf("ApplyConfig() got node errors %v, want %v", nodeErrs, tt.wantNodeErr)
+			}
+			for name, want := range tt.wantNodeErr {
+				if s := errdiff.Check(nodeErrs[name], want); s != "" {
+					t.Errorf("ApplyConfig() unexpected err for node %q: %s", name, s)
+				}
+			}
+		})
+	}
+}
+
+type blockingPusher struct {
+	*node.Impl
+	// err is returned by ConfigPush if set, otherwise ConfigPush blocks
+	// until its context is done.
+	err error
+}
+
+func (b *blockingPusher) ConfigPush(ctx context.Context, _ io.Reader) error {
+	if b.err != nil {
+		return b.err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(5 * time.Second):
+		return fmt.Errorf("push not canceled")
+	}
+}
+
+func TestApplyConfigFatal(t *testing.T) {
+	m := &Manager{
+		nodes: map[string]node.Node{
+			"r1": &blockingPusher{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1", Vendor: tpb.Vendor_ARISTA}}, err: context.DeadlineExceeded},
+			"r2": &blockingPusher{Impl: &node.Impl{Proto: &tpb.Node{Name: "r2", Vendor: tpb.Vendor_ARISTA}}},
+			"r3": &blockingPusher{Impl: &node.Impl{Proto: &tpb.Node{Name: "r3", Vendor: tpb.Vendor_ARISTA}}},
+		},
+	}
+	start := time.Now()
+	nodeErrs, err := m.ApplyConfig(context.Background(), tpb.Vendor_ARISTA, strings.NewReader("hostname dut"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ApplyConfig() got err %v, want %v", err, context.DeadlineExceeded)
+	}
+	if s := errdiff.Substring(err, `failed to push config to node "r1"`); s != "" {
+		t.Errorf("ApplyConfig() unexpected err: %s", s)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("ApplyConfig() took %v, want the other pushes to be canceled", d)
+	}
+	if len(nodeErrs) != 0 {
+		t.Errorf("ApplyConfig() got node errors %v, want none", nodeErrs)
+	}
+}
+
+func TestNodeFactory(t *testing.T) {
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{{
+			Name:   "r1",
+			Vendor: tpb.Vendor(2000),
+			Config: &tpb.Config{},
+		}, {
+			Name:   "r2",
+			Vendor: tpb.Vendor_ARISTA,
+			Config: &tpb.Config{},
+		}},
+	}
+	newManager := func(opts ...Option) (*Manager, *kfake.Clientset, error) {
+		tf, err := tfake.NewSimpleClientset()
+		if err != nil {
+			t.Fatalf("cannot create fake topology clientset: %v", err)
+		}
+		kf := kfake.NewSimpleClientset()
+		m, err := New(proto.Clone(topo).(*tpb.Topology), append([]Option{WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf)}, opts...)...)
+		return m, kf, err
+	}
+	if _, _, err := newManager(); err == nil {
+		t.Fatalf("New() without node factory succeeded for unregistered vendor, want error")
+	}
+	var impls []*node.Impl
+	m, kf, err := newManager(WithBasePath("/base"), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		impls = append(impls, impl)
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if len(impls) != 2 {
+		t.Fatalf("New() called node factory %d times, want 2", len(impls))
+	}
+	for _, impl := range impls {
+		if impl.Namespace != "test" || impl.BasePath != "/base" || impl.KubeClient != kf {
+			t.Errorf("New() node factory got impl %+v, want namespace, base path and client of the manager", impl)
+		}
+	}
+	for name, n := range m.Nodes() {
+		if _, ok := n.(*configurable); !ok {
+			t.Errorf("New() node %q is %T, want *configurable", name, n)
+		}
+	}
+	if err := m.push(context.Background()); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r1", Config: &tpb.Config{}}, {Name: "r2", Config: &tpb.Config{}}},
+		Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if _, err := m.Clone(ctx, "test"); err == nil {
+		t.Fatalf("Clone() with the same name succeeded, want error")
+	}
+	c, err := m.Clone(ctx, "clone", WithLabels(map[string]string{"copy": "true"}))
+	if err != nil {
+		t.Fatalf("Clone() unexpected err: %v", err)
+	}
+	if got := m.topo.GetName(); got != "test" {
+		t.Errorf("Clone() changed original topology name to %q", got)
+	}
+	if got := c.topo.GetName(); got != "clone" {
+		t.Errorf("Clone() got topology name %q, want %q", got, "clone")
+	}
+	ns, err := kf.CoreV1().Namespaces().Get(ctx, "clone", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Clone() did not create namespace: %v", err)
+	}
+	if ns.Labels["copy"] != "true" {
+		t.Errorf("Clone() got namespace labels %v, want option applied", ns.Labels)
+	}
+	if _, err := kf.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Clone() created original namespace, got err %v", err)
+	}
+	for _, name := range []string{"r1", "r2"} {
+		if _, err := kf.CoreV1().Pods("clone").Get(ctx, name, metav1.GetOptions{}); err != nil {
+			t.Errorf("Clone() did not create pod %q: %v", name, err)
+		}
+		if got := c.nodes[name].GetNamespace(); got != "clone" {
+			t.Errorf("Clone() node %q got namespace %q, want %q", name, got, "clone")
+		}
+		if got := m.nodes[name].GetNamespace(); got != "test" {
+			t.Errorf("Clone() changed namespace of original node %q to %q", name, got)
+		}
+	}
+}
+
+func TestRestartNode(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"}})
+	m := &Manager{
+		topo: &tpb.Topology{Name: "test"},
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1"}}},
+		},
+		kClient: kf,
+	}
+	if err := m.RestartNode(ctx, "dne"); err == nil {
+		t.Fatalf("RestartNode() for missing node succeeded, want error")
+	}
+	if err := m.RestartNode(ctx, "r1"); err != nil {
+		t.Fatalf("RestartNode() unexpected err: %v", err)
+	}
+	var deleted bool
+	for _, a := range kf.Actions() {
+		if a.GetVerb() == "delete" && a.GetResource().Resource == "pods" {
+			deleted = true
+		}
+	}
+	if !deleted {
+		t.Errorf("RestartNode() did not delete pod of node")
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{}); err != nil {
+		t.Errorf("RestartNode() did not recreate pod: %v", err)
+	}
+}
+
+type rollbackStore struct {
+	configStore
+	pushes []string
+}
+
+func (c *rollbackStore) ConfigPush(ctx context.Context, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.pushes = append(c.pushes, string(b))
+	if string(b) == "error" {
+		return fmt.Errorf("push failed")
+	}
+	return c.configStore.ConfigPush(ctx, bytes.NewReader(b))
+}
+
+func TestPushConfigs(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc       string
+		configs    map[string]string
+		wantErr    string
+		wantConfig map[string]string
+		wantPushes map[string][]string
+	}{{
+		desc:       "success",
+		configs:    map[string]string{"r1": "new1", "r2": "new2", "r3": "new3"},
+		wantConfig: map[string]string{"r1": "new1", "r2": "new2", "r3": "new3"},
+		wantPushes: map[string][]string{"r1": {"new1"}, "r2": {"new2"}, "r3": {"new3"}},
+	}, {
+		desc:       "rollback",
+		configs:    map[string]string{"r1": "new1", "r2": "error", "r3": "new3"},
+		wantErr:    `failed to push config to node "r2"`,
+		wantConfig: map[string]string{"r1": "old1", "r2": "old2", "r3": "old3"},
+		wantPushes: map[string][]string{"r1": {"new1", "old1"}, "r2": {"error"}, "r3": {"new3", "old3"}},
+	}, {
+		desc:       "missing node",
+		configs:    map[string]string{"r1": "new1", "dne": "new"},
+		wantErr:    `node "dne" not found`,
+		wantConfig: map[string]string{"r1": "old1", "r2": "old2", "r3": "old3"},
+		wantPushes: map[string][]string{},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			stores := map[string]*rollbackStore{}
+			m := &Manager{nodes: map[string]node.Node{}}
+			for _, name := range []string{"r1", "r2", "r3"} {
+				s := &rollbackStore{configStore: configStore{config: []byte("old" + name[1:])}}
+				stores[name] = s
+				m.nodes[name] = s
+			}
+			configs := map[string]io.Reader{}
+			for name, c := range tt.configs {
+				configs[name] = strings.NewReader(c)
+			}
+			err := m.PushConfigs(ctx, configs)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("PushConfigs() unexpected err: %s", s)
+			}
+			gotConfig := map[string]string{}
+			gotPushes := map[string][]string{}
+			for name, s := range stores {
+				gotConfig[name] = string(s.config)
+				if len(s.pushes) > 0 {
+					gotPushes[name] = s.pushes
+				}
+			}
+			if s := cmp.Diff(tt.wantConfig, gotConfig); s != "" {
+				t.Errorf("PushConfigs() unexpected configs (-want +got):\n%s", s)
+			}
+			if s := cmp.Diff(tt.wantPushes, gotPushes); s != "" {
+				t.Errorf("PushConfigs() unexpected pushes (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestAddNodeAndLink(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r1", Config: &tpb.Config{}}, {Name: "r2", Config: &tpb.Config{}}},
+		Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithLabels(map[string]string{"lab": "a"}), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+
+	if err := m.AddNode(ctx, &tpb.Node{Name: "r1", Config: &tpb.Config{}}); err == nil {
+		t.Fatalf("AddNode() with duplicate name succeeded, want error")
+	}
+	if err := m.AddNode(ctx, &tpb.Node{Name: "r3", Config: &tpb.Config{}}); err != nil {
+		t.Fatalf("AddNode() unexpected err: %v", err)
+	}
+	n, ok := m.Nodes()["r3"]
+	if !ok {
+		t.Fatalf("AddNode() did not add node to Nodes()")
+	}
+	if got := n.GetProto().GetLabels()["lab"]; got != "a" {
+		t.Errorf("AddNode() got label %q, want manager label applied", got)
+	}
+	if got := len(m.topo.Nodes); got != 3 {
+		t.Errorf("AddNode() got %d nodes in topology, want 3", got)
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r3", metav1.GetOptions{}); err != nil {
+		t.Errorf("AddNode() did not create pod: %v", err)
+	}
+	if _, err := tf.Topology("test").Get(ctx, "r3", metav1.GetOptions{}); err != nil {
+		t.Errorf("AddNode() did not create meshnet topology: %v", err)
+	}
+
+	for _, l := range []*tpb.Link{
+		{ANode: "r1", AInt: "eth1", ZNode: "r3", ZInt: "eth1"},
+		{ANode: "r3", AInt: "eth1", ZNode: "r3", ZInt: "eth2"},
+		{ANode: "r3", AInt: "eth1", ZNode: "dne", ZInt: "eth1"},
+	} {
+		if err := m.AddLink(ctx, l); err == nil {
+			t.Errorf("AddLink(%v) succeeded, want error", l)
+		}
+	}
+	if err := m.AddLink(ctx, &tpb.Link{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"}); err != nil {
+		t.Fatalf("AddLink() unexpected err: %v", err)
+	}
+	if got := len(m.topo.Links); got != 2 {
+		t.Errorf("AddLink() got %d links in topology, want 2", got)
+	}
+	mt, err := tf.Topology("test").Get(ctx, "r3", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("AddLink() meshnet topology of r3 not found: %v", err)
+	}
+	want := []topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth2", PeerPod: "r2"}}
+	if s := cmp.Diff(want, mt.Spec.Links); s != "" {
+		t.Errorf("AddLink() unexpected meshnet links (-want +got):\n%s", s)
+	}
+	if got := m.nodes["r2"].GetProto().GetInterfaces()["eth2"].GetPeerName(); got != "r3" {
+		t.Errorf("AddLink() got peer %q of r2:eth2, want r3", got)
+	}
+	// Removing r1 drops the link with uid 0 so the number of links no longer
+	// gives an unused uid.
+	if err := m.RemoveNode(ctx, "r1", true); err != nil {
+		t.Fatalf("RemoveNode() unexpected err: %v", err)
+	}
+	if err := m.AddLink(ctx, &tpb.Link{ANode: "r2", AInt: "eth3", ZNode: "r3", ZInt: "eth2"}); err != nil {
+		t.Fatalf("AddLink() unexpected err: %v", err)
+	}
+	if got, want := m.nodes["r3"].GetProto().GetInterfaces()["eth2"].GetUid(), int64(2); got != want {
+		t.Errorf("AddLink() got uid %d of r3:eth2, want %d", got, want)
+	}
+}
+
+func TestAddNodeWithLinks(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r1", Config: &tpb.Config{}}, {Name: "r2", Config: &tpb.Config{}}},
+		Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	var restarts []string
+	kf.PrependReactor("delete", "pods", func(action ktest.Action) (bool, runtime.Object, error) {
+		restarts = append(restarts, action.(ktest.DeleteAction).GetName())
+		return false, nil, nil
+	})
+
+	for _, pb := range []*tpb.Node{
+		{Name: "r3", Config: &tpb.Config{}, Interfaces: map[string]*tpb.Interface{"eth1": {PeerName: "r1", PeerIntName: "eth1"}}},
+		{Name: "r3", Config: &tpb.Config{}, Interfaces: map[string]*tpb.Interface{"eth1": {PeerName: "dne", PeerIntName: "eth1"}}},
+		{Name: "r3", Config: &tpb.Config{}, Interfaces: map[string]*tpb.Interface{
+			"eth1": {PeerName: "r1", PeerIntName: "eth2"},
+			"eth2": {PeerName: "r1", PeerIntName: "eth2"},
+		}},
+	} {
+		if err := m.AddNode(ctx, pb); err == nil {
+			t.Errorf("AddNode(%v) succeeded, want error", pb)
+		}
+	}
+	pb := &tpb.Node{
+		Name:   "r3",
+		Config: &tpb.Config{},
+		Interfaces: map[string]*tpb.Interface{
+			"eth1": {PeerName: "r1", PeerIntName: "eth2"},
+			"eth2": {PeerName: "r2", PeerIntName: "eth2"},
+		},
+	}
+	if err := m.AddNode(ctx, pb); err != nil {
+		t.Fatalf("AddNode() unexpected err: %v", err)
+	}
+	if got, want := m.nodes["r3"].GetProto().GetInterfaces()["eth2"].GetPeerName(), "r2"; got != want {
+		t.Errorf("AddNode() got peer %q of r3:eth2, want %q", got, want)
+	}
+	if got, want := m.nodes["r1"].GetProto().GetInterfaces()["eth2"].GetPeerName(), "r3"; got != want {
+		t.Errorf("AddNode() got peer %q of r1:eth2, want %q", got, want)
+	}
+	if got := len(m.topo.Links); got != 3 {
+		t.Errorf("AddNode() got %d links in topology, want 3", got)
+	}
+	mt, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("AddNode() meshnet topology of r1 not found: %v", err)
+	}
+	want := []topologyv1.Link{
+		{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"},
+		{UID: 1, LocalIntf: "eth2", PeerIntf: "eth1", PeerPod: "r3"},
+	}
+	if s := cmp.Diff(want, mt.Spec.Links, cmpopts.SortSlices(func(a, b topologyv1.Link) bool { return a.UID < b.UID })); s != "" {
+		t.Errorf("AddNode() unexpected meshnet links of r1 (-want +got):\n%s", s)
+	}
+	if s := cmp.Diff([]string{"r1", "r2"}, restarts); s != "" {
+		t.Errorf("AddNode() unexpected restarted pods (-want +got):\n%s", s)
+	}
+}
+
+func TestAddNodeRollback(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r1", Config: &tpb.Config{}}, {Name: "r2", Config: &tpb.Config{}}},
+		Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	kf.PrependReactor("create", "services", func(action ktest.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("service create failed")
+	})
+	pb := &tpb.Node{
+		Name:     "r3",
+		Config:   &tpb.Config{},
+		Services: map[uint32]*tpb.Service{22: {Name: "ssh"}},
+		Interfaces: map[string]*tpb.Interface{
+			"eth1": {PeerName: "r1", PeerIntName: "eth2"},
+		},
+	}
+	if s := errdiff.Check(m.AddNode(ctx, pb), "service create failed"); s != "" {
+		t.Fatalf("AddNode() unexpected err: %s", s)
+	}
+	if _, ok := m.nodes["r3"]; ok {
+		t.Errorf("AddNode() failed but added node r3")
+	}
+	if _, ok := m.nodes["r1"].GetProto().GetInterfaces()["eth2"]; ok {
+		t.Errorf("AddNode() failed but added interface r1:eth2")
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r3", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("AddNode() did not delete pod r3, got err %v", err)
+	}
+	if _, err := tf.Topology("test").Get(ctx, "r3", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("AddNode() did not delete meshnet topology r3, got err %v", err)
+	}
+	mt, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("AddNode() meshnet topology of r1 not found: %v", err)
+	}
+	want := []topologyv1.Link{{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}
+	if s := cmp.Diff(want, mt.Spec.Links); s != "" {
+		t.Errorf("AddNode() unexpected meshnet links of r1 (-want +got):\n%s", s)
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Config: &tpb.Config{}},
+			{Name: "r2", Config: &tpb.Config{}},
+			{Name: "r3", Config: &tpb.Config{}},
+			{Name: "r4", Config: &tpb.Config{}},
+		},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r2", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+		},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	if err := m.RemoveNode(ctx, "dne", false); err == nil {
+		t.Fatalf("RemoveNode() for missing node succeeded, want error")
+	}
+	if err := m.RemoveNode(ctx, "r4", false); err != nil {
+		t.Fatalf("RemoveNode() for node without links unexpected err: %v", err)
+	}
+	if s := errdiff.Check(m.RemoveNode(ctx, "r3", false), "set force"); s != "" {
+		t.Fatalf("RemoveNode() for node with links unexpected err: %s", s)
+	}
+	if _, ok := m.Nodes()["r3"]; !ok {
+		t.Fatalf("RemoveNode() without force removed node with links")
+	}
+	if err := m.RemoveNode(ctx, "r3", true); err != nil {
+		t.Fatalf("RemoveNode() with force unexpected err: %v", err)
+	}
+	for _, name := range []string{"r3", "r4"} {
+		if _, ok := m.Nodes()[name]; ok {
+			t.Errorf("RemoveNode() did not remove node %q from Nodes()", name)
+		}
+		if _, err := kf.CoreV1().Pods("test").Get(ctx, name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("RemoveNode() did not delete pod %q, got err %v", name, err)
+		}
+		if _, err := tf.Topology("test").Get(ctx, name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			t.Errorf("RemoveNode() did not delete meshnet topology %q, got err %v", name, err)
+		}
+	}
+	var names []string
+	for _, n := range m.topo.Nodes {
+		names = append(names, n.Name)
+	}
+	if s := cmp.Diff([]string{"r1", "r2"}, names); s != "" {
+		t.Errorf("RemoveNode() unexpected topology nodes (-want +got):\n%s", s)
+	}
+	if s := cmp.Diff([]*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}}, m.topo.Links, protocmp.Transform()); s != "" {
+		t.Errorf("RemoveNode() unexpected topology links (-want +got):\n%s", s)
+	}
+	if _, ok := m.nodes["r2"].GetProto().GetInterfaces()["eth2"]; ok {
+		t.Errorf("RemoveNode() did not remove interface eth2 of peer r2")
+	}
+	mt, err := tf.Topology("test").Get(ctx, "r2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("RemoveNode() meshnet topology of r2 not found: %v", err)
+	}
+	want := []topologyv1.Link{{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r1"}}
+	if s := cmp.Diff(want, mt.Spec.Links); s != "" {
+		t.Errorf("RemoveNode() unexpected meshnet links of r2 (-want +got):\n%s", s)
+	}
+}
+
+func TestExpandWildcards(t *testing.T) {
+	tests := []struct {
+		desc      string
+		topo      *tpb.Topology
+		wantLinks []*tpb.Link
+		wantErr   string
+	}{{
+		desc: "distinct interfaces",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}, {Name: "r3"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth*", ZNode: "r3", ZInt: "eth*"},
+			},
+		},
+		wantLinks: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth2", ZNode: "r3", ZInt: "eth1"},
+		},
+	}, {
+		desc: "explicit interfaces reserved",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}, {Name: "r3"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth1", ZNode: "r3", ZInt: "eth1"},
+			},
+		},
+		wantLinks: []*tpb.Link{
+			{ANode: "r1", AInt: "eth2", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth1", ZNode: "r3", ZInt: "eth1"},
+		},
+	}, {
+		desc: "declared interfaces",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1", Interfaces: map[string]*tpb.Interface{
+				"eth10": {}, "eth9": {}, "mgmt0": {},
+			}}, {Name: "r2"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth2"},
+			},
+		},
+		wantLinks: []*tpb.Link{
+			{ANode: "r1", AInt: "eth9", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth10", ZNode: "r2", ZInt: "eth2"},
+		},
+	}, {
+		desc: "no interface available",
+		topo: &tpb.Topology{
+			Nodes: []*tpb.Node{{Name: "r1", Interfaces: map[string]*tpb.Interface{"eth1": {}}}, {Name: "r2"}},
+			Links: []*tpb.Link{
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth1"},
+				{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth2"},
+			},
+		},
+		wantErr: "no interface available for r1:eth*",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := expandWildcards(tt.topo)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("expandWildcards() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			if s := cmp.Diff(tt.wantLinks, tt.topo.Links, protocmp.Transform()); s != "" {
+				t.Errorf("expandWildcards() unexpected links (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestLoadWildcards(t *testing.T) {
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	topo := &tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r1"}, {Name: "r2"}, {Name: "r3"}},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth*", ZNode: "r2", ZInt: "eth1"},
+			{ANode: "r1", AInt: "eth*", ZNode: "r3", ZInt: "eth1"},
+		},
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf), WithNodeFactory(func(impl *node.Impl) (node.Node, error) {
+		return &configurable{Impl: impl}, nil
+	}))
+	if err != nil {
+		t.Fatalf("New() unexpected err: %v", err)
+	}
+	ints := m.Nodes()["r1"].GetProto().GetInterfaces()
+	for name, peer := range map[string]string{"eth1": "r2", "eth2": "r3"} {
+		if got := ints[name].GetPeerName(); got != peer {
+			t.Errorf("New() got peer %q of r1:%s, want %q", got, name, peer)
+		}
+	}
+}
+
+func TestAnnotateNode(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "r1",
+		Namespace:   "test",
+		Annotations: map[string]string{"old": "value", "keep": "value"},
+	}})
+	m := &Manager{
+		topo: &tpb.Topology{Name: "test"},
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1"}}},
+		},
+		kClient: kf,
+	}
+	err := m.AnnotateNode(ctx, "dne", map[string]string{"a": "b"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("AnnotateNode() for missing node got err %v, want NotFound", err)
+	}
+	if err := m.AnnotateNode(ctx, "r1", map[string]string{"kne.google.com/under-test": "true", "old": ""}); err != nil {
+		t.Fatalf("AnnotateNode() unexpected err: %v", err)
+	}
+	var patches []string
+	for _, a := range kf.Actions() {
+		if pa, ok := a.(ktest.PatchAction); ok {
+			if pa.GetPatchType() != types.StrategicMergePatchType {
+				t.Errorf("AnnotateNode() got patch type %q, want %q", pa.GetPatchType(), types.StrategicMergePatchType)
+			}
+			patches = append(patches, string(pa.GetPatch()))
+		}
+	}
+	want := []string{`{"metadata":{"annotations":{"kne.google.com/under-test":"true","old":null}}}`}
+	if s := cmp.Diff(want, patches); s != "" {
+		t.Errorf("AnnotateNode() unexpected patches (-want +got):\n%s", s)
+	}
+	p, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	wantAnnotations := map[string]string{"kne.google.com/under-test": "true", "keep": "value"}
+	if s := cmp.Diff(wantAnnotations, p.Annotations); s != "" {
+		t.Errorf("AnnotateNode() unexpected annotations (-want +got):\n%s", s)
+	}
+}
+
+func TestNodePodSpec(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset()
+	m := &Manager{
+		topo: &tpb.Topology{Name: "test"},
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: "r1", Config: &tpb.Config{Image: "image"}}}},
+		},
+		kClient: kf,
+	}
+	if _, err := m.NodePodSpec(ctx, "dne"); err == nil {
+		t.Fatalf("NodePodSpec() for missing node succeeded, want error")
+	}
+	p, err := m.NodePodSpec(ctx, "r1")
+	if err != nil {
+		t.Fatalf("NodePodSpec() unexpected err: %v", err)
+	}
+	if p.Name != "r1" || len(p.Spec.Containers) != 1 || p.Spec.Containers[0].Image != "image" {
+		t.Errorf("NodePodSpec() got pod %+v, want pod of r1 with image", p)
+	}
+	if _, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("NodePodSpec() created pod, got err %v", err)
+	}
+}
+
+func TestStatusCallback(t *testing.T) {
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	sleep = func(time.Duration) {}
+	type call struct {
+		Node, Phase string
+	}
+	var got []call
+	var last time.Duration
+	cb := func(name, phase string, elapsed time.Duration) {
+		if elapsed < last {
+			t.Errorf("StatusCallback() elapsed went backwards: %v < %v", elapsed, last)
+		}
+		last = elapsed
+		got = append(got, call{Node: name, Phase: phase})
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m, err := New(&tpb.Topology{Name: "test"},
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kfake.NewSimpleClientset()),
+		WithTopoClient(tf),
+		WithStatusCallback(cb),
+	)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	m.nodes = map[string]node.Node{
+		"r1": &pendingNode{
+			Impl:    &node.Impl{Proto: &tpb.Node{Name: "r1"}},
+			pending: 2,
+		},
+	}
+	if err := m.checkNodeStatus(context.Background(), 0); err != nil {
+		t.Fatalf("checkNodeStatus() unexpected err: %v", err)
+	}
+	want := []call{
+		{Node: "r1", Phase: string(node.StatusPending)},
+		{Node: "r1", Phase: string(node.StatusPending)},
+		{Node: "r1", Phase: string(node.StatusRunning)},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("StatusCallback() unexpected calls (-want +got):\n%s", s)
+	}
+}
+
+type deletingNode struct {
+	*node.Impl
+	pods int
+}
+
+func (d *deletingNode) Pods(context.Context) ([]*corev1.Pod, error) {
+	if d.pods == 0 {
+		return nil, nil
+	}
+	d.pods--
+	return []*corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: d.Name()}}}, nil
+}
+
+func TestWaitNodeDeleted(t *testing.T) {
+	origSleep := sleep
+	defer func() {
+		sleep = origSleep
+	}()
+	var got []time.Duration
+	sleep = func(d time.Duration) {
+		got = append(got, d)
+	}
+	// A manager without a poll interval polls at the default interval.
+	m := &Manager{}
+	n := &deletingNode{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1"}}, pods: 2}
+	if err := m.waitNodeDeleted(context.Background(), n); err != nil {
+		t.Fatalf("waitNodeDeleted() unexpected err: %v", err)
+	}
+	if s := cmp.Diff([]time.Duration{defaultPollInterval, defaultPollInterval}, got); s != "" {
+		t.Errorf("waitNodeDeleted() unexpected sleeps (-want +got):\n%s", s)
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	node.Vendor(tpb.Vendor(1026), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Vendor: tpb.Vendor(1026)},
+			{Name: "r2", Vendor: tpb.Vendor(1026)},
+			{Name: "r3", Vendor: tpb.Vendor(1026)},
+		},
+	}
+	k8sObjects := []runtime.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "r1",
+				Namespace: "test",
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "r2",
+				Namespace: "test",
 			},
 		},
-		want: &cpb.ShowTopologyResponse{
-			State:    cpb.TopologyState_TOPOLOGY_STATE_CREATING,
-			Topology: wantTopo,
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "r3",
+				Namespace: "test",
+			},
 		},
-	}, {
-		desc: "success - unhealthy",
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service-r1",
+				Namespace: "test",
 			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r1",
-					Namespace: "test",
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.96.0.1",
+				Ports: []corev1.ServicePort{
+					{Name: "ssh", Port: 22},
+					{Name: "gnmi", Port: 9339},
 				},
-				Status: corev1.PodStatus{Phase: corev1.PodFailed},
 			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r2",
-					Namespace: "test",
-				},
-				Status: corev1.PodStatus{
-					Phase:      corev1.PodRunning,
-					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{
+					Ingress: []corev1.LoadBalancerIngress{{IP: "192.168.18.100"}},
 				},
 			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r1",
-					Namespace: "test",
-				},
-				Spec: corev1.ServiceSpec{
-					ClusterIP: "10.1.1.1",
-					Type:      "LoadBalancer",
-					Ports: []corev1.ServicePort{{
-						Name:       "ssh",
-						Protocol:   "TCP",
-						Port:       22,
-						TargetPort: intstr.FromInt(22),
-						NodePort:   20001,
-					}},
-				},
-				Status: corev1.ServiceStatus{
-					LoadBalancer: corev1.LoadBalancerStatus{
-						Ingress: []corev1.LoadBalancerIngress{{
-							IP: "192.168.16.50",
-						}},
-					},
-				},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "service-r2",
+				Namespace: "test",
 			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r2",
-					Namespace: "test",
-				},
-				Spec: corev1.ServiceSpec{
-					ClusterIP: "10.1.1.2",
-					Type:      "LoadBalancer",
-					Ports: []corev1.ServicePort{{
-						Name:       "grpc",
-						Protocol:   "TCP",
-						Port:       9337,
-						TargetPort: intstr.FromInt(9337),
-						NodePort:   20002,
-					}, {
-						Name:       "gnmi",
-						Protocol:   "TCP",
-						Port:       9339,
-						TargetPort: intstr.FromInt(9339),
-						NodePort:   20003,
-					}},
-				},
-				Status: corev1.ServiceStatus{
-					LoadBalancer: corev1.LoadBalancerStatus{
-						Ingress: []corev1.LoadBalancerIngress{{
-							IP: "192.168.16.51",
-						}},
-					},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.96.0.2",
+				Ports: []corev1.ServicePort{
+					{Name: "ssh", Port: 22},
 				},
 			},
 		},
-		want: &cpb.ShowTopologyResponse{
-			State:    cpb.TopologyState_TOPOLOGY_STATE_ERROR,
-			Topology: wantTopo,
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m, err := New(topo,
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kfake.NewSimpleClientset(k8sObjects...)),
+		WithTopoClient(tf),
+	)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	got, err := m.ServiceEndpoints(context.Background())
+	if err != nil {
+		t.Fatalf("ServiceEndpoints() unexpected err: %v", err)
+	}
+	want := map[string]map[uint32]Endpoint{
+		"r1": {
+			22:   {Host: "192.168.18.100", Port: 22},
+			9339: {Host: "192.168.18.100", Port: 9339},
+		},
+		"r2": {
+			22: {Host: "10.96.0.2", Port: 22},
+		},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("ServiceEndpoints() unexpected diff (-want +got):\n%s", s)
+	}
+}
+
+func TestNodeTerminalURL(t *testing.T) {
+	service := func(name, clusterIP, lbIP string, ports ...corev1.ServicePort) *corev1.Service {
+		s := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-" + name, Namespace: "test"},
+			Spec:       corev1.ServiceSpec{ClusterIP: clusterIP, Ports: ports},
+		}
+		if lbIP != "" {
+			s.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: lbIP}}
+		}
+		return s
+	}
+	kf := kfake.NewSimpleClientset(
+		service("r1", "10.96.0.1", "192.168.18.100", corev1.ServicePort{Name: "ssh", Port: 22}, corev1.ServicePort{Name: TerminalPortName, Port: 8888}),
+		service("r2", "fd00::1", "", corev1.ServicePort{Name: TerminalPortName, Port: 7681}),
+		service("r3", "10.96.0.3", "192.168.18.102", corev1.ServicePort{Name: "ssh", Port: 22}),
+	)
+	m := &Manager{nodes: map[string]node.Node{}}
+	for _, name := range []string{"r1", "r2", "r3", "r4"} {
+		m.nodes[name] = &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: name}}}
+	}
+	tests := []struct {
+		desc           string
+		name           string
+		want           string
+		wantErr        string
+		wantNotExposed bool
+	}{{
+		desc: "load balancer",
+		name: "r1",
+		want: "http://192.168.18.100:8888",
+	}, {
+		desc: "cluster ip",
+		name: "r2",
+		want: "http://[fd00::1]:7681",
+	}, {
+		desc:           "no terminal port",
+		name:           "r3",
+		wantErr:        `node "r3" does not expose a web terminal`,
+		wantNotExposed: true,
+	}, {
+		desc:           "no service",
+		name:           "r4",
+		wantErr:        `node "r4" does not expose a web terminal`,
+		wantNotExposed: true,
+	}, {
+		desc:    "node not found",
+		name:    "dne",
+		wantErr: `node "dne" not found`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := m.NodeTerminalURL(context.Background(), tt.name)
+			if s := errdiff.Check(err, tt.wantErr); s != "" {
+				t.Fatalf("NodeTerminalURL() unexpected err: %s", s)
+			}
+			var tErr TerminalNotExposedError
+			if gotNotExposed := errors.As(err, &tErr); gotNotExposed != tt.wantNotExposed {
+				t.Errorf("NodeTerminalURL() got TerminalNotExposedError %v, want %v", gotNotExposed, tt.wantNotExposed)
+			}
+			if got != tt.want {
+				t.Errorf("NodeTerminalURL() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchHandler(t *testing.T) {
+	events := []watch.Event{{
+		Type:   watch.Added,
+		Object: &topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r1"}},
+	}, {
+		Type: watch.Modified,
+		Object: &topologyv1.Topology{
+			ObjectMeta: metav1.ObjectMeta{Name: "r1"},
+			Status:     topologyv1.TopologyStatus{ContainerID: "c1"},
+		},
+	}}
+	tests := []struct {
+		desc    string
+		format  string
+		want    []watchEvent
+		wantErr string
+	}{{
+		desc:   "json",
+		format: WatchFormatJSON,
+		want: []watchEvent{
+			{Type: watch.Added, Name: "r1", Phase: "Pending"},
+			{Type: watch.Modified, Name: "r1", Phase: "Running"},
 		},
+	}, {
+		desc:    "unknown format",
+		format:  "yaml",
+		wantErr: "unknown watch format",
 	}}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			tf, err := tfake.NewSimpleClientset()
+			var buf bytes.Buffer
+			handler, err := watchHandler(&buf, tt.format)
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("watchHandler() unexpected err: %s", s)
+			}
 			if err != nil {
-				t.Fatalf("cannot create fake topology clientset: %v", err)
+				return
 			}
-			opts := []Option{
-				WithClusterConfig(&rest.Config{}),
-				WithKubeClient(kfake.NewSimpleClientset(tt.k8sObjects...)),
-				WithTopoClient(tf),
+			for _, e := range events {
+				handler(e.Type, e.Object)
+			}
+			var got []watchEvent
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var e watchEvent
+				if err := dec.Decode(&e); err != nil {
+					t.Fatalf("watchHandler() wrote invalid JSON: %v", err)
+				}
+				if e.Timestamp.IsZero() {
+					t.Errorf("watchHandler() wrote event without timestamp: %+v", e)
+				}
+				got = append(got, e)
+			}
+			if s := cmp.Diff(tt.want, got, cmpopts.IgnoreFields(watchEvent{}, "Timestamp")); s != "" {
+				t.Errorf("watchHandler() unexpected events (-want +got):\n%s", s)
+			}
+		})
+	}
+}
+
+func TestExposeNode(t *testing.T) {
+	ctx := context.Background()
+	kf := kfake.NewSimpleClientset()
+	m := &Manager{
+		topo: &tpb.Topology{Name: "test"},
+		nodes: map[string]node.Node{
+			"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{
+				Name:   "r1",
+				Labels: map[string]string{"vendor": "cisco"},
+			}}},
+		},
+		kClient: kf,
+	}
+	_, err := m.ExposeNode(ctx, "dne", 8080, corev1.ProtocolTCP)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("ExposeNode() for missing node got err %v, want NotFound", err)
+	}
+	got, err := m.ExposeNode(ctx, "r1", 8080, "")
+	if err != nil {
+		t.Fatalf("ExposeNode() unexpected err: %v", err)
+	}
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "service-r1-tcp-8080",
+			Namespace: "test",
+			Labels: map[string]string{
+				"pod":    "r1",
+				"vendor": "cisco",
+			},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{
+				Name:       "port-8080",
+				Protocol:   corev1.ProtocolTCP,
+				Port:       8080,
+				TargetPort: intstr.FromInt(8080),
+			}},
+			Selector: map[string]string{
+				"app": "r1",
+			},
+			Type: corev1.ServiceTypeLoadBalancer,
+		},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("ExposeNode() unexpected service (-want +got):\n%s", s)
+	}
+	if _, err := kf.CoreV1().Services("test").Get(ctx, "service-r1-tcp-8080", metav1.GetOptions{}); err != nil {
+		t.Errorf("ExposeNode() did not create service: %v", err)
+	}
+	if _, err := m.ExposeNode(ctx, "r1", 8080, corev1.ProtocolTCP); err == nil {
+		t.Errorf("ExposeNode() for already exposed port got nil err, want err")
+	}
+}
+
+func TestCreateStrictLinks(t *testing.T) {
+	node.Vendor(tpb.Vendor(1030), NewConfigurable)
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Vendor: tpb.Vendor(1030), Interfaces: map[string]*tpb.Interface{"eth1": {}}},
+			{Name: "r2", Vendor: tpb.Vendor(1030)},
+			{Name: "r3", Vendor: tpb.Vendor(1030)},
+		},
+		Links: []*tpb.Link{{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"}},
+	}
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kfake.NewSimpleClientset()), WithTopoClient(tf), WithStrictLinks(true))
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	// The links are validated as given rather than after the interfaces of
+	// the links have been filled in.
+	err = m.Create(context.Background(), 0)
+	for _, want := range []string{`interface r2:eth1: used by a link but not declared by the node`, `node "r3": has no links`} {
+		if s := errdiff.Substring(err, want); s != "" {
+			t.Errorf("Create() unexpected err: %s", s)
+		}
+	}
+}
+
+func TestGenerateCerts(t *testing.T) {
+	certCfg := &tpb.Config{
+		Cert: &tpb.CertificateCfg{
+			Config: &tpb.CertificateCfg_SelfSigned{},
+		},
+	}
+	tests := []struct {
+		desc    string
+		gErr    map[string]string
+		wantErr string
+	}{{
+		desc: "success",
+	}, {
+		desc:    "one failure",
+		gErr:    map[string]string{"r2": "failed to generate certs"},
+		wantErr: "failed to generate cert for node r2: failed to generate certs",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			certables := map[string]*certable{}
+			m := &Manager{nodes: map[string]node.Node{
+				"not_certable": &notCertable{proto: &tpb.Node{Config: certCfg}},
+			}}
+			for _, name := range []string{"r1", "r2", "r3"} {
+				c := &certable{proto: &tpb.Node{Name: name, Config: certCfg}, gErr: tt.gErr[name]}
+				certables[name] = c
+				m.nodes[name] = c
+			}
+			err := m.GenerateCerts(context.Background())
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("GenerateCerts() unexpected err: %s", s)
+			}
+			for name, c := range certables {
+				if want := tt.gErr[name] == ""; c.generated != want {
+					t.Errorf("GenerateCerts() node %q generated certs %v, want %v", name, c.generated, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeNode(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc      string
+		phase     corev1.PodPhase
+		stuck     bool
+		wantErr   string
+		wantImage string
+		wantPatch []string
+	}{{
+		desc:      "ready",
+		phase:     corev1.PodRunning,
+		wantImage: "new:1",
+		wantPatch: []string{
+			`{"spec":{"containers":[{"image":"new:1","name":"r1"}]}}`,
+		},
+	}, {
+		desc:      "rollback on timeout",
+		phase:     corev1.PodPending,
+		wantErr:   `rolled back to image "old:1"`,
+		wantImage: "old:1",
+		wantPatch: []string{
+			`{"spec":{"containers":[{"image":"new:1","name":"r1"}]}}`,
+			`{"spec":{"containers":[{"image":"old:1","name":"r1"}]}}`,
+		},
+	}, {
+		desc:      "rollback when new image never runs",
+		phase:     corev1.PodRunning,
+		stuck:     true,
+		wantErr:   `not running image "new:1"`,
+		wantImage: "old:1",
+		wantPatch: []string{
+			`{"spec":{"containers":[{"image":"new:1","name":"r1"}]}}`,
+			`{"spec":{"containers":[{"image":"old:1","name":"r1"}]}}`,
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			kf := kfake.NewSimpleClientset(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{Name: "sidecar", Image: "sidecar:1"},
+						{Name: "r1", Image: "old:1"},
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:      tt.phase,
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					ContainerStatuses: []corev1.ContainerStatus{
+						{Name: "sidecar", Image: "sidecar:1"},
+						{Name: "r1", Image: "old:1"},
+					},
+				},
+			})
+			// Report the images of the pod spec as running, as the kubelet
+			// does once it replaces the containers, unless stuck.
+			kf.PrependReactor("get", "pods", func(action ktest.Action) (bool, runtime.Object, error) {
+				ga := action.(ktest.GetAction)
+				obj, err := kf.Tracker().Get(ga.GetResource(), ga.GetNamespace(), ga.GetName())
+				if err != nil || tt.stuck {
+					return true, obj, err
+				}
+				pod := obj.(*corev1.Pod).DeepCopy()
+				for i, c := range pod.Spec.Containers {
+					pod.Status.ContainerStatuses[i].Image = c.Image
+				}
+				return true, pod, nil
+			})
+			pb := &tpb.Node{Name: "r1", Config: &tpb.Config{Image: "old:1"}}
+			m := &Manager{
+				topo: &tpb.Topology{Name: "test"},
+				nodes: map[string]node.Node{
+					"r1": &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: pb}},
+				},
+				kClient:        kf,
+				upgradeTimeout: 10 * time.Millisecond,
+			}
+			err := m.UpgradeNode(ctx, "r1", "new:1")
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("UpgradeNode() unexpected err: %s", s)
+			}
+			var patches []string
+			for _, a := range kf.Actions() {
+				if pa, ok := a.(ktest.PatchAction); ok {
+					patches = append(patches, string(pa.GetPatch()))
+				}
 			}
-			tTopo := proto.Clone(topo).(*tpb.Topology)
-			m, err := New(tTopo, opts...)
+			if s := cmp.Diff(tt.wantPatch, patches); s != "" {
+				t.Errorf("UpgradeNode() unexpected patches (-want +got):\n%s", s)
+			}
+			p, err := kf.CoreV1().Pods("test").Get(ctx, "r1", metav1.GetOptions{})
 			if err != nil {
-				t.Fatalf("New() failed to create new topology manager: %v", err)
+				t.Fatalf("failed to get pod: %v", err)
 			}
-			got, err := m.Show(ctx)
-			if s := errdiff.Check(err, tt.wantErr); s != "" {
-				t.Fatalf("Show() unexpected err: %s", s)
+			if got := p.Spec.Containers[1].Image; got != tt.wantImage {
+				t.Errorf("UpgradeNode() got pod image %q, want %q", got, tt.wantImage)
 			}
-			if tt.wantErr != "" {
-				return
+			if got := p.Spec.Containers[0].Image; got != "sidecar:1" {
+				t.Errorf("UpgradeNode() changed sidecar image to %q", got)
 			}
-			if s := cmp.Diff(tt.want, got, protocmp.Transform()); s != "" {
-				t.Fatalf("Show() unexpected diff (-want +got):\n%s", s)
+			if got := pb.GetConfig().GetImage(); got != tt.wantImage {
+				t.Errorf("UpgradeNode() got node image %q, want %q", got, tt.wantImage)
 			}
 		})
 	}
+	if err := (&Manager{}).UpgradeNode(ctx, "dne", "new:1"); status.Code(err) != codes.NotFound {
+		t.Errorf("UpgradeNode() for missing node got err %v, want NotFound", err)
+	}
 }
 
-func TestResources(t *testing.T) {
-	ctx := context.Background()
-	node.Vendor(tpb.Vendor(1005), NewConfigurable)
-	topo := &tpb.Topology{
-		Name: "test",
-		Nodes: []*tpb.Node{
-			{
-				Name:   "r1",
-				Vendor: tpb.Vendor(1005),
-				Services: map[uint32]*tpb.Service{
-					1000: {
-						Name: "ssh",
-					},
-				},
-			},
-			{
-				Name:   "r2",
-				Vendor: tpb.Vendor(1005),
-				Services: map[uint32]*tpb.Service{
-					2000: {
-						Name: "grpc",
-					},
-					3000: {
-						Name: "gnmi",
-					},
-				},
-			},
-		},
-	}
-	tests := []struct {
-		desc        string
-		k8sObjects  []runtime.Object
-		topoObjects []runtime.Object
-		want        *Resources
-		wantErr     string
-	}{{
-		desc: "success",
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
-			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r1",
-					Namespace: "test",
-				},
-			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r2",
-					Namespace: "test",
-				},
-			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r1",
-					Namespace: "test",
+func TestHealthCheck(t *testing.T) {
+	kf := kfake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "r1",
+				RestartCount: 3,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
 				},
-			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r2",
-					Namespace: "test",
-				},
-			},
+			}, {
+				Name:         "sidecar",
+				RestartCount: 1,
+			}},
 		},
-		topoObjects: []runtime.Object{
-			&topologyv1.Topology{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "t1",
-					Namespace: "test",
+	}, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "r2",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "image not found"},
 				},
-			},
+			}},
 		},
-		want: &Resources{
-			Pods: map[string][]*corev1.Pod{
-				"r1": {{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "r1",
-						Namespace: "test",
-					},
-				}},
-				"r2": {{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "r2",
-						Namespace: "test",
-					},
-				}},
-			},
-			Services: map[string][]*corev1.Service{
-				"r1": {{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "service-r1",
-						Namespace: "test",
-					},
-				}},
-				"r2": {{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "service-r2",
-						Namespace: "test",
-					},
-				}},
-			},
-			ConfigMaps: map[string]*corev1.ConfigMap{},
-			Topologies: map[string]*topologyv1.Topology{
-				"t1": {
-					TypeMeta: metav1.TypeMeta{
-						Kind:       "Topology",
-						APIVersion: "networkop.co.uk/v1beta1",
-					},
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "t1",
-						Namespace: "test",
-					},
-				},
-			},
+	}, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "r3", Namespace: "test"},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "r3"}},
 		},
+	})
+	m := &Manager{nodes: map[string]node.Node{}}
+	for _, name := range []string{"r1", "r2", "r3", "r4"} {
+		m.nodes[name] = &configurable{Impl: &node.Impl{Namespace: "test", KubeClient: kf, Proto: &tpb.Node{Name: name}}}
+	}
+	got, err := m.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("HealthCheck() unexpected err: %v", err)
+	}
+	want := []NodeHealth{{
+		Name:         "r1",
+		Phase:        corev1.PodRunning,
+		RestartCount: 4,
+		OOMKilled:    true,
+		LastError:    "container r1: OOMKilled (exit code 137)",
 	}, {
-		desc: "no pods",
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
-			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r1",
-					Namespace: "test",
+		Name:      "r2",
+		Phase:     corev1.PodPending,
+		LastError: "container r2: ImagePullBackOff: image not found",
+	}, {
+		Name:  "r3",
+		Phase: corev1.PodRunning,
+	}, {
+		Name:  "r4",
+		Phase: corev1.PodUnknown,
+	}}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("HealthCheck() unexpected health (-want +got):\n%s", s)
+	}
+}
+
+func TestWithPodSecurityContext(t *testing.T) {
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	sc := &corev1.PodSecurityContext{RunAsUser: pointer.Int64(1000), RunAsNonRoot: pointer.Bool(true)}
+	m, err := New(&tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Config: &tpb.Config{Image: "image"}},
+			{Name: "r2", Config: &tpb.Config{Image: "image", SecurityContext: &tpb.PodSecurityContext{RunAsUser: 2000}}},
+		},
+	},
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kfake.NewSimpleClientset()),
+		WithTopoClient(tf),
+		WithNodeFactory(NewConfigurable),
+		WithPodSecurityContext(sc),
+	)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	want := map[string]*corev1.PodSecurityContext{
+		"r1": {RunAsUser: pointer.Int64(1000), RunAsNonRoot: pointer.Bool(true)},
+		"r2": {RunAsUser: pointer.Int64(2000), RunAsNonRoot: pointer.Bool(true)},
+	}
+	for name, wantSC := range want {
+		p, err := m.NodePodSpec(context.Background(), name)
+		if err != nil {
+			t.Fatalf("NodePodSpec(%q) unexpected err: %v", name, err)
+		}
+		if s := cmp.Diff(wantSC, p.Spec.SecurityContext); s != "" {
+			t.Errorf("NodePodSpec(%q) unexpected security context (-want +got):\n%s", name, s)
+		}
+	}
+}
+
+func TestImagePullSecrets(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	kf := kfake.NewSimpleClientset()
+	secrets := []corev1.LocalObjectReference{{Name: "registry"}, {Name: "other"}}
+	newManager := func(c *RegistryCredential, secrets ...corev1.LocalObjectReference) *Manager {
+		t.Helper()
+		m, err := New(&tpb.Topology{
+			Name:  "test",
+			Nodes: []*tpb.Node{{Name: "r1", Config: &tpb.Config{Image: "registry.example.com/image"}}},
+		},
+			WithClusterConfig(&rest.Config{}),
+			WithKubeClient(kf),
+			WithTopoClient(tf),
+			WithNodeFactory(NewConfigurable),
+			WithImagePullSecrets(secrets...),
+			WithRegistryCredential(c),
+		)
+		if err != nil {
+			t.Fatalf("New() failed to create new topology manager: %v", err)
+		}
+		return m
+	}
+
+	m := newManager(nil, secrets...)
+	p, err := m.NodePodSpec(ctx, "r1")
+	if err != nil {
+		t.Fatalf("NodePodSpec() unexpected err: %v", err)
+	}
+	if s := cmp.Diff(secrets, p.Spec.ImagePullSecrets); s != "" {
+		t.Errorf("NodePodSpec() unexpected image pull secrets (-want +got):\n%s", s)
+	}
+	if err := m.createImagePullSecret(ctx); err != nil {
+		t.Fatalf("createImagePullSecret() without credential unexpected err: %v", err)
+	}
+	if _, err := kf.CoreV1().Secrets("test").Get(ctx, "registry", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("createImagePullSecret() without credential created secret, got err %v", err)
+	}
+
+	// The secret is created and then updated once the password changes.
+	for _, password := range []string{"pass1", "pass2"} {
+		m := newManager(&RegistryCredential{Server: "registry.example.com", Username: "user", Password: password}, secrets...)
+		if err := m.createImagePullSecret(ctx); err != nil {
+			t.Fatalf("createImagePullSecret() unexpected err: %v", err)
+		}
+		s, err := kf.CoreV1().Secrets("test").Get(ctx, "registry", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get image pull secret: %v", err)
+		}
+		if s.Type != corev1.SecretTypeDockerConfigJson {
+			t.Errorf("createImagePullSecret() got secret type %q, want %q", s.Type, corev1.SecretTypeDockerConfigJson)
+		}
+		var got map[string]map[string]map[string]string
+		if err := json.Unmarshal(s.Data[corev1.DockerConfigJsonKey], &got); err != nil {
+			t.Fatalf("failed to decode image pull secret: %v", err)
+		}
+		want := map[string]map[string]map[string]string{
+			"auths": {
+				"registry.example.com": {
+					"username": "user",
+					"password": password,
+					"auth":     base64.StdEncoding.EncodeToString([]byte("user:" + password)),
 				},
 			},
-			&corev1.Service{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "service-r2",
-					Namespace: "test",
+		}
+		if s := cmp.Diff(want, got); s != "" {
+			t.Errorf("createImagePullSecret() unexpected docker config (-want +got):\n%s", s)
+		}
+	}
+
+	m = newManager(&RegistryCredential{Server: "registry.example.com"})
+	err = m.createImagePullSecret(ctx)
+	if s := errdiff.Check(err, "requires an image pull secret"); s != "" {
+		t.Errorf("createImagePullSecret() without image pull secrets unexpected err: %s", s)
+	}
+}
+
+func TestCreateNetworkPolicy(t *testing.T) {
+	port := func(p int) *intstr.IntOrString {
+		v := intstr.FromInt(p)
+		return &v
+	}
+	tcp, udp := corev1.ProtocolTCP, corev1.ProtocolUDP
+	allTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	tests := []struct {
+		desc          string
+		allowExternal bool
+		want          networkingv1.NetworkPolicySpec
+	}{{
+		desc: "isolated",
+		want: networkingv1.NetworkPolicySpec{
+			PolicyTypes: allTypes,
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+			}, {
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: &tcp, Port: port(22)},
+					{Protocol: &tcp, Port: port(6030)},
+					{Protocol: &udp, Port: port(6343)},
+					{Protocol: &tcp, Port: port(9339)},
 				},
-			},
+			}},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				To: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+			}, {
+				To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "kube-system"}},
+					PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{Protocol: &udp, Port: port(53)}, {Protocol: &tcp, Port: port(53)}},
+			}},
 		},
-		wantErr: "could not get pods",
 	}, {
-		desc: "no services",
-		k8sObjects: []runtime.Object{
-			&corev1.Namespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: "test",
-				},
-			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r1",
-					Namespace: "test",
-				},
-				Status: corev1.PodStatus{Phase: corev1.PodRunning},
-			},
-			&corev1.Pod{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "r2",
-					Namespace: "test",
-				},
-				Status: corev1.PodStatus{Phase: corev1.PodRunning},
-			},
+		desc:          "allow external",
+		allowExternal: true,
+		want: networkingv1.NetworkPolicySpec{
+			PolicyTypes: allTypes,
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
 		},
-		wantErr: "could not get services",
 	}}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			tf, err := tfake.NewSimpleClientset(tt.topoObjects...)
-			if err != nil {
-				t.Fatalf("cannot create fake topology clientset: %v", err)
+			ctx := context.Background()
+			kf := kfake.NewSimpleClientset(&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "service-r1-udp-6343", Namespace: "test"},
+				Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+					{Protocol: corev1.ProtocolUDP, Port: 6343, TargetPort: intstr.FromInt(6343)},
+					{Protocol: corev1.ProtocolTCP, Port: 22, TargetPort: intstr.FromInt(22)},
+				}},
+			})
+			m := &Manager{
+				topo: &tpb.Topology{Name: "test"},
+				nodes: map[string]node.Node{
+					"r1": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1", Services: map[uint32]*tpb.Service{
+						22:   {Name: "ssh", Inside: 22},
+						9339: {Name: "gnmi", Inside: 9339},
+					}}}},
+					"r2": &configurable{Impl: &node.Impl{Proto: &tpb.Node{Name: "r2", Services: map[uint32]*tpb.Service{
+						22:    {Name: "ssh", Inside: 22},
+						50051: {Name: "gribi", Inside: 6030},
+					}}}},
+				},
+				kClient: kf,
 			}
-			opts := []Option{
-				WithClusterConfig(&rest.Config{}),
-				WithKubeClient(kfake.NewSimpleClientset(tt.k8sObjects...)),
-				WithTopoClient(tf),
+			if err := m.CreateNetworkPolicy(ctx, tt.allowExternal); err != nil {
+				t.Fatalf("CreateNetworkPolicy() unexpected err: %v", err)
 			}
-			tTopo := proto.Clone(topo).(*tpb.Topology)
-			m, err := New(tTopo, opts...)
+			got, err := kf.NetworkingV1().NetworkPolicies("test").Get(ctx, NetworkPolicyName, metav1.GetOptions{})
 			if err != nil {
-				t.Fatalf("New() failed to create new topology manager: %v", err)
-			}
-			got, err := m.Resources(ctx)
-			if s := errdiff.Check(err, tt.wantErr); s != "" {
-				t.Errorf("Resources() unexpected err: %s", s)
+				t.Fatalf("CreateNetworkPolicy() did not create network policy: %v", err)
 			}
-			if s := cmp.Diff(tt.want, got); s != "" {
-				t.Errorf("Resources() unexpected diff (-want +got):\n%s", s)
+			if s := cmp.Diff(tt.want, got.Spec); s != "" {
+				t.Errorf("CreateNetworkPolicy() unexpected spec (-want +got):\n%s", s)
 			}
 		})
 	}
 }
 
-func TestNodes(t *testing.T) {
-	aNode := &configurable{}
-	bNode := &configurable{}
-	cNode := &configurable{}
-	tests := []struct {
-		desc string
-		want map[string]node.Node
-	}{{
-		desc: "non-zero nodes",
-		want: map[string]node.Node{
-			"a": aNode,
-			"b": bNode,
-			"c": cNode,
-		},
+func TestSyncFromCluster(t *testing.T) {
+	pod := func(name string, labels map[string]string, image string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: labels},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+		}
+	}
+	kf := kfake.NewSimpleClientset(
+		pod("r0", map[string]string{"app": "r0", "topo": "test", "vendor": "ARISTA"}, "ceos:latest"),
+		pod("r1", map[string]string{"app": "r1", "topo": "test", "vendor": "ARISTA", "model": "ceos", "role": "dut"}, "ceos:4.30"),
+		pod("r2", map[string]string{"app": "r2", "topo": "test", "vendor": "CISCO"}, "xrd:latest"),
+		pod("r3", map[string]string{"app": "r3", "topo": "test", "vendor": "BOGUS"}, "bogus:latest"),
+		pod("helper", map[string]string{"run": "helper"}, "busybox"),
+	)
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
+	}
+	m, err := New(&tpb.Topology{
+		Name:  "test",
+		Nodes: []*tpb.Node{{Name: "r0", Vendor: tpb.Vendor_ARISTA}},
+	},
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kf),
+		WithTopoClient(tf),
+		WithNodeFactory(NewConfigurable),
+	)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	r0 := m.Nodes()["r0"]
+	if err := m.SyncFromCluster(context.Background()); err != nil {
+		t.Fatalf("SyncFromCluster() unexpected err: %v", err)
+	}
+	var got []*tpb.Node
+	for _, name := range []string{"r0", "r1", "r2", "r3", "helper"} {
+		if n, ok := m.Nodes()[name]; ok {
+			got = append(got, n.GetProto())
+		}
+	}
+	want := []*tpb.Node{{
+		Name:       "r0",
+		Vendor:     tpb.Vendor_ARISTA,
+		Interfaces: map[string]*tpb.Interface{},
 	}, {
-		desc: "zero nodes",
+		Name:   "r1",
+		Vendor: tpb.Vendor_ARISTA,
+		Model:  "ceos",
+		Labels: map[string]string{"vendor": "ARISTA", "model": "ceos", "role": "dut"},
+		Config: &tpb.Config{Image: "ceos:4.30"},
+	}, {
+		Name:   "r2",
+		Vendor: tpb.Vendor_CISCO,
+		Labels: map[string]string{"vendor": "CISCO"},
+		Config: &tpb.Config{Image: "xrd:latest"},
 	}}
-	for _, tt := range tests {
-		t.Run(tt.desc, func(t *testing.T) {
-			m := &Manager{nodes: tt.want}
-			got := m.Nodes()
-			if s := cmp.Diff(tt.want, got); s != "" {
-				t.Errorf("Nodes() unexpected diff: %s", s)
-			}
-		})
+	if s := cmp.Diff(want, got, protocmp.Transform()); s != "" {
+		t.Errorf("SyncFromCluster() unexpected nodes (-want +got):\n%s", s)
+	}
+	if m.Nodes()["r0"] != r0 {
+		t.Errorf("SyncFromCluster() replaced existing node r0")
+	}
+	if got := len(m.topo.Nodes); got != 3 {
+		t.Errorf("SyncFromCluster() got %d topology nodes, want 3", got)
 	}
 }
 
-func TestConfigPush(t *testing.T) {
-	m := &Manager{
-		nodes: map[string]node.Node{
-			"configurable":     &configurable{},
-			"not_configurable": &notConfigurable{},
-		},
-	}
+type interfaceWaiter struct {
+	*node.Impl
+	err     error
+	timeout time.Duration
+	waited  bool
+}
+
+func (w *interfaceWaiter) Status(_ context.Context) (node.Status, error) {
+	return node.StatusRunning, nil
+}
+
+func (w *interfaceWaiter) Ready(_ context.Context) (bool, error) {
+	return true, nil
+}
+
+func (w *interfaceWaiter) WaitForInterfaces(_ context.Context, timeout time.Duration) error {
+	w.waited = true
+	w.timeout = timeout
+	return w.err
+}
+
+func TestCheckNodeStatusInterfaces(t *testing.T) {
 	tests := []struct {
 		desc    string
-		name    string
-		cfg     io.Reader
+		timeout time.Duration
+		err     error
 		wantErr string
 	}{{
-		desc: "configurable good config",
-		name: "configurable",
-		cfg:  bytes.NewReader([]byte("good config")),
+		desc: "interfaces present",
 	}, {
-		desc:    "configurable bad config",
-		name:    "configurable",
-		cfg:     bytes.NewReader([]byte("error")),
-		wantErr: "error",
+		desc:    "interfaces present with timeout",
+		timeout: time.Minute,
 	}, {
-		desc:    "not configurable",
-		name:    "not_configurable",
-		wantErr: "does not implement ConfigPusher interface",
+		desc:    "interfaces missing",
+		err:     fmt.Errorf("interfaces [eth1] of node r1 not found"),
+		wantErr: "interfaces [eth1] of node r1 not found",
 	}, {
-		desc:    "node not found",
-		name:    "dne",
-		wantErr: "not found",
+		desc: "interfaces not listable",
+		err:  status.Errorf(codes.Unimplemented, "cannot list interfaces"),
 	}}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			err := m.ConfigPush(context.Background(), tt.name, tt.cfg)
-			if s := errdiff.Check(err, tt.wantErr); s != "" {
-				t.Errorf("ConfigPush() unexpected error: %s", s)
+			w := &interfaceWaiter{Impl: &node.Impl{Proto: &tpb.Node{Name: "r1"}}, err: tt.err}
+			m := &Manager{nodes: map[string]node.Node{"r1": w}}
+			err := m.checkNodeStatus(context.Background(), tt.timeout)
+			if s := errdiff.Substring(err, tt.wantErr); s != "" {
+				t.Fatalf("checkNodeStatus() unexpected err: %s", s)
+			}
+			if !w.waited {
+				t.Fatalf("checkNodeStatus() did not wait for interfaces")
+			}
+			if tt.timeout == 0 && w.timeout != 0 {
+				t.Errorf("checkNodeStatus() waited for interfaces with timeout %v, want none", w.timeout)
+			}
+			if tt.timeout != 0 && (w.timeout <= 0 || w.timeout > tt.timeout) {
+				t.Errorf("checkNodeStatus() waited for interfaces with timeout %v, want at most %v", w.timeout, tt.timeout)
 			}
 		})
 	}
 }
 
-func TestResetCfg(t *testing.T) {
-	m := &Manager{
-		nodes: map[string]node.Node{
-			"resettable":     &resettable{},
-			"resettable_err": &resettable{rErr: "failed to reset"},
-			"not_resettable": &notResettable{},
-		},
-	}
+func TestPushUpsert(t *testing.T) {
+	ctx := context.Background()
+	node.Vendor(tpb.Vendor(1027), NewConfigurable)
 	tests := []struct {
 		desc    string
-		name    string
+		upsert  bool
 		wantErr string
 	}{{
-		desc: "resettable",
-		name: "resettable",
-	}, {
-		desc:    "resettable failure",
-		name:    "resettable_err",
-		wantErr: "failed to reset",
+		desc:   "upsert",
+		upsert: true,
 	}, {
-		desc:    "not resettable",
-		name:    "not_resettable",
-		wantErr: "does not implement Resetter interface",
-	}, {
-		desc:    "node not found",
-		name:    "dne",
-		wantErr: "not found",
+		desc:    "no upsert",
+		wantErr: "already exists",
 	}}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			err := m.ResetCfg(context.Background(), tt.name)
-			if s := errdiff.Check(err, tt.wantErr); s != "" {
-				t.Errorf("ResetCfg() unexpected error: %s", s)
+			tf, err := tfake.NewSimpleClientset(&topologyv1.Topology{
+				TypeMeta:   metav1.TypeMeta{Kind: "Topology", APIVersion: "networkop.co.uk/v1beta1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+			})
+			if err != nil {
+				t.Fatalf("cannot create fake topology clientset: %v", err)
 			}
-		})
-	}
-}
-
-func TestGenerateSelfSigned(t *testing.T) {
-	m := &Manager{
-		nodes: map[string]node.Node{
-			"certable": &certable{
-				proto: &tpb.Node{
-					Config: &tpb.Config{
-						Cert: &tpb.CertificateCfg{
-							Config: &tpb.CertificateCfg_SelfSigned{},
-						},
-					},
+			kf := kfake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test", Labels: map[string]string{"app": "r1"}}},
+				&corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "service-r1", Namespace: "test"},
+					Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "telnet", Port: 23}}},
 				},
-			},
-			"certable_err": &certable{
-				gErr: "failed to generate certs",
-				proto: &tpb.Node{
-					Config: &tpb.Config{
-						Cert: &tpb.CertificateCfg{
-							Config: &tpb.CertificateCfg_SelfSigned{},
-						},
-					},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "r1-config", Namespace: "test"},
+					Data:       map[string]string{"startup.cfg": "hostname old"},
 				},
-			},
-			"not_certable": &notCertable{
-				proto: &tpb.Node{
+			)
+			topo := &tpb.Topology{
+				Name: "test",
+				Nodes: []*tpb.Node{{
+					Name:     "r1",
+					Vendor:   tpb.Vendor(1027),
+					Services: map[uint32]*tpb.Service{22: {Name: "ssh", Inside: 22}},
 					Config: &tpb.Config{
-						Cert: &tpb.CertificateCfg{
-							Config: &tpb.CertificateCfg_SelfSigned{},
-						},
+						ConfigFile: "startup.cfg",
+						ConfigData: &tpb.Config_Data{Data: []byte("hostname new")},
 					},
+				}, {
+					Name:   "r2",
+					Vendor: tpb.Vendor(1027),
+					Config: &tpb.Config{},
+				}},
+				Links: []*tpb.Link{
+					{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
 				},
-			},
-			"no_info": &certable{},
-		},
-	}
-	tests := []struct {
-		desc    string
-		name    string
-		wantErr string
-	}{{
-		desc: "certable",
-		name: "certable",
-	}, {
-		desc:    "certable failure",
-		name:    "certable_err",
-		wantErr: "failed to generate certs",
-	}, {
-		desc:    "not certable",
-		name:    "not_certable",
-		wantErr: "does not implement Certer interface",
-	}, {
-		desc: "no cert info",
-		name: "no_info",
-	}, {
-		desc:    "node not found",
-		name:    "dne",
-		wantErr: "not found",
-	}}
-	for _, tt := range tests {
-		t.Run(tt.desc, func(t *testing.T) {
-			err := m.GenerateSelfSigned(context.Background(), tt.name)
+			}
+			m, err := New(topo, WithClusterConfig(&rest.Config{}), WithKubeClient(kf), WithTopoClient(tf), WithUpsert(tt.upsert))
+			if err != nil {
+				t.Fatalf("New() failed to create new topology manager: %v", err)
+			}
+			err = m.push(ctx)
 			if s := errdiff.Check(err, tt.wantErr); s != "" {
-				t.Errorf("GenerateSelfSigned() unexpected error: %s", s)
+				t.Fatalf("push() unexpected err: %s", s)
+			}
+			if err != nil {
+				return
+			}
+			r1, err := tf.Topology("test").Get(ctx, "r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get meshnet topology r1: %v", err)
+			}
+			wantLinks := []topologyv1.Link{{UID: 0, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}}
+			if s := cmp.Diff(wantLinks, r1.Spec.Links); s != "" {
+				t.Errorf("push() did not update meshnet topology r1: diff (-want +got):\n%s", s)
+			}
+			if _, err := tf.Topology("test").Get(ctx, "r2", metav1.GetOptions{}); err != nil {
+				t.Errorf("push() did not create meshnet topology r2: %v", err)
+			}
+			if _, err := kf.CoreV1().Pods("test").Get(ctx, "r2", metav1.GetOptions{}); err != nil {
+				t.Errorf("push() did not create pod r2: %v", err)
+			}
+			svc, err := kf.CoreV1().Services("test").Get(ctx, "service-r1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get service service-r1: %v", err)
+			}
+			var ports []int32
+			for _, p := range svc.Spec.Ports {
+				ports = append(ports, p.Port)
+			}
+			if s := cmp.Diff([]int32{22}, ports); s != "" {
+				t.Errorf("push() did not update service of r1: diff (-want +got):\n%s", s)
+			}
+			cm, err := kf.CoreV1().ConfigMaps("test").Get(ctx, "r1-config", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get config map r1-config: %v", err)
+			}
+			if s := cmp.Diff(map[string]string{"startup.cfg": "hostname new"}, cm.Data); s != "" {
+				t.Errorf("push() did not update config of r1: diff (-want +got):\n%s", s)
 			}
 		})
 	}
 }
 
-func TestStateMap(t *testing.T) {
-	type nodeInfo struct {
-		name  string
-		phase node.Status
+func TestWithResourcePrefix(t *testing.T) {
+	ctx := context.Background()
+	tf, err := tfake.NewSimpleClientset()
+	if err != nil {
+		t.Fatalf("cannot create fake topology clientset: %v", err)
 	}
-
-	tests := []struct {
-		desc  string
-		nodes []*nodeInfo
-		want  cpb.TopologyState
-	}{{
-		desc: "no nodes",
-		want: cpb.TopologyState_TOPOLOGY_STATE_UNSPECIFIED,
-	}, {
-		desc: "one node failed",
-		nodes: []*nodeInfo{
-			{"n1", node.StatusFailed},
-			{"n2", node.StatusRunning},
-			{"n3", node.StatusRunning},
-		},
-		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
-	}, {
-		desc: "one node failed with one node pending",
-		nodes: []*nodeInfo{
-			{"n1", node.StatusFailed},
-			{"n2", node.StatusRunning},
-			{"n3", node.StatusRunning},
-		},
-		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
-	}, {
-		desc: "one node failed, one node pending, one node unknown",
-		nodes: []*nodeInfo{
-			{"n1", node.StatusFailed},
-			{"n2", node.StatusPending},
-			{"n3", node.StatusUnknown},
-		},
-		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
-	}, {
-		desc: "all nodes failed",
-		nodes: []*nodeInfo{
-			{"n1", node.StatusFailed},
-			{"n2", node.StatusFailed},
-			{"n3", node.StatusFailed},
+	kf := kfake.NewSimpleClientset()
+	topo := &tpb.Topology{
+		Name: "test",
+		Nodes: []*tpb.Node{
+			{Name: "r1", Config: &tpb.Config{}, Services: map[uint32]*tpb.Service{22: {Name: "ssh"}}},
+			{Name: "r2", Config: &tpb.Config{}},
 		},
-		want: cpb.TopologyState_TOPOLOGY_STATE_ERROR,
-	}, {
-		desc: "one node pending",
-		nodes: []*nodeInfo{
-			{"n1", node.StatusPending},
-			{"n2", node.StatusRunning},
-			{"n3", node.StatusRunning},
+		Links: []*tpb.Link{
+			{ANode: "r1", AInt: "eth1", ZNode: "r2", ZInt: "eth1"},
 		},
-		want: cpb.TopologyState_TOPOLOGY_STATE_CREATING,
-	},
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.desc, func(t *testing.T) {
-			sm := &stateMap{}
-			for _, n := range tt.nodes {
-				sm.setNodeState(n.name, n.phase)
+	opts := []Option{
+		WithClusterConfig(&rest.Config{}),
+		WithKubeClient(kf),
+		WithTopoClient(tf),
+		WithNodeFactory(NewConfigurable),
+		WithResourcePrefix("alice-"),
+	}
+	m, err := New(topo, opts...)
+	if err != nil {
+		t.Fatalf("New() failed to create new topology manager: %v", err)
+	}
+	if err := m.push(ctx); err != nil {
+		t.Fatalf("push() unexpected err: %v", err)
+	}
+	check := func(ns string) {
+		t.Helper()
+		if _, err := kf.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{}); err != nil {
+			t.Errorf("failed to get namespace %q: %v", ns, err)
+		}
+		for _, name := range []string{"r1", "r2"} {
+			if _, err := kf.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+				t.Errorf("failed to get pod %q in namespace %q: %v", name, ns, err)
 			}
-			got := sm.topologyState()
-			if got != tt.want {
-				t.Fatalf("got %+v, want %+v", got, tt.want)
+			if _, err := tf.Topology(ns).Get(ctx, name, metav1.GetOptions{}); err != nil {
+				t.Errorf("failed to get meshnet topology %q in namespace %q: %v", name, ns, err)
 			}
-		})
+		}
+		if _, err := kf.CoreV1().Services(ns).Get(ctx, "service-r1", metav1.GetOptions{}); err != nil {
+			t.Errorf("failed to get service %q in namespace %q: %v", "service-r1", ns, err)
+		}
+	}
+	check("alice-test")
+	for name, n := range m.Nodes() {
+		if got := n.GetNamespace(); got != "alice-test" {
+			t.Errorf("node %q got namespace %q, want %q", name, got, "alice-test")
+		}
+	}
+	if _, err := kf.CoreV1().Namespaces().Get(ctx, "test", metav1.GetOptions{}); err == nil {
+		t.Errorf("push() created unprefixed namespace %q", "test")
+	}
+
+	if _, err := m.Clone(ctx, "copy"); err != nil {
+		t.Fatalf("Clone() unexpected err: %v", err)
+	}
+	check("alice-copy")
+	if _, err := m.Clone(ctx, "test"); err == nil {
+		t.Errorf("Clone() to the prefixed name of the topology succeeded, want error")
+	}
+
+	if got := m.topo.GetName(); got != "test" {
+		t.Errorf("New() got topology name %q, want %q", got, "test")
+	}
+	path := filepath.Join(t.TempDir(), "topo.pb.txt")
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save() unexpected err: %v", err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() unexpected err: %v", err)
+	}
+	if got := saved.GetName(); got != "test" {
+		t.Errorf("Save() wrote topology name %q, want %q", got, "test")
+	}
+	m2, err := New(saved, opts...)
+	if err != nil {
+		t.Fatalf("New() failed to create topology manager from saved topology: %v", err)
+	}
+	if got, want := m2.namespace(), "alice-test"; got != want {
+		t.Errorf("New() of saved topology got namespace %q, want %q", got, want)
 	}
 }
@@ -0,0 +1,114 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"fmt"
+
+	topologyclientv1 "github.com/networkop/meshnet-cni/api/clientset/v1beta1"
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	log "k8s.io/klog/v2"
+)
+
+// TopologyEvent is a change to a meshnet topology resource.
+type TopologyEvent struct {
+	Type     watch.EventType
+	Topology *topologyv1.Topology
+	// NodeName is the name of the node the meshnet topology is for.
+	NodeName string
+}
+
+// TopologyWatcher delivers the changes to the meshnet topology resources of
+// a namespace as TopologyEvents.
+type TopologyWatcher struct {
+	w  watch.Interface
+	ch chan TopologyEvent
+}
+
+// NewTopologyWatcher starts watching the meshnet topology resources in
+// namespace. The watch ends when ctx is done or Stop is called.
+func NewTopologyWatcher(ctx context.Context, tClient topologyclientv1.Interface, namespace string) (*TopologyWatcher, error) {
+	w, err := tClient.Topology(namespace).Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to watch topologies in namespace %q: %w", namespace, err)
+	}
+	return newTopologyWatcher(ctx, w), nil
+}
+
+func newTopologyWatcher(ctx context.Context, w watch.Interface) *TopologyWatcher {
+	tw := &TopologyWatcher{
+		w:  w,
+		ch: make(chan TopologyEvent),
+	}
+	go tw.watch(ctx)
+	return tw
+}
+
+// Events returns the channel the events are delivered on. The channel is
+// closed when the watch ends.
+func (tw *TopologyWatcher) Events() <-chan TopologyEvent {
+	return tw.ch
+}
+
+// Stop ends the watch.
+func (tw *TopologyWatcher) Stop() {
+	tw.w.Stop()
+}
+
+func (tw *TopologyWatcher) watch(ctx context.Context) {
+	defer close(tw.ch)
+	defer tw.w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-tw.w.ResultChan():
+			if !ok {
+				return
+			}
+			t, err := decodeTopology(e.Object)
+			if err != nil {
+				log.Warningf("Skipping %s event: %v", e.Type, err)
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case tw.ch <- TopologyEvent{Type: e.Type, Topology: t, NodeName: t.Name}:
+			}
+		}
+	}
+}
+
+// decodeTopology returns obj as a meshnet topology.
+func decodeTopology(obj runtime.Object) (*topologyv1.Topology, error) {
+	switch o := obj.(type) {
+	case *topologyv1.Topology:
+		return o, nil
+	case *unstructured.Unstructured:
+		t := &topologyv1.Topology{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, t); err != nil {
+			return nil, fmt.Errorf("failed to decode topology: %w", err)
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unexpected object %T", obj)
+	}
+}
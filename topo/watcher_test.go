@@ -0,0 +1,78 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	topologyv1 "github.com/networkop/meshnet-cni/api/types/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func TestTopologyWatcher(t *testing.T) {
+	r1 := &topologyv1.Topology{
+		ObjectMeta: metav1.ObjectMeta{Name: "r1", Namespace: "test"},
+		Spec: topologyv1.TopologySpec{
+			Links: []topologyv1.Link{{UID: 1, LocalIntf: "eth1", PeerIntf: "eth1", PeerPod: "r2"}},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r1)
+	if err != nil {
+		t.Fatalf("failed to convert topology: %v", err)
+	}
+	r2 := &topologyv1.Topology{ObjectMeta: metav1.ObjectMeta{Name: "r2", Namespace: "test"}}
+
+	fw := watch.NewFake()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tw := newTopologyWatcher(ctx, fw)
+	go func() {
+		fw.Add(&unstructured.Unstructured{Object: obj})
+		fw.Error(&metav1.Status{Message: "skipped"})
+		fw.Modify(r2)
+		fw.Delete(r2)
+		fw.Stop()
+	}()
+	var got []TopologyEvent
+	for e := range tw.Events() {
+		got = append(got, e)
+	}
+	want := []TopologyEvent{
+		{Type: watch.Added, Topology: r1, NodeName: "r1"},
+		{Type: watch.Modified, Topology: r2, NodeName: "r2"},
+		{Type: watch.Deleted, Topology: r2, NodeName: "r2"},
+	}
+	if s := cmp.Diff(want, got); s != "" {
+		t.Errorf("Events() unexpected diff (-want +got):\n%s", s)
+	}
+}
+
+func TestTopologyWatcherCancel(t *testing.T) {
+	fw := watch.NewFake()
+	ctx, cancel := context.WithCancel(context.Background())
+	tw := newTopologyWatcher(ctx, fw)
+	cancel()
+	for e := range tw.Events() {
+		t.Errorf("Events() got unexpected event %+v after cancel", e)
+	}
+	if !fw.IsStopped() {
+		t.Errorf("TopologyWatcher did not stop the watch after cancel")
+	}
+}